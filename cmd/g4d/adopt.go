@@ -53,7 +53,7 @@ func runAdopt(cmd *cobra.Command, args []string) {
 		cfg, err = config.LoadFromPath(args[0])
 		configPath = args[0]
 	} else {
-		cfg, configPath, err = config.LoadFromDiscovery()
+		cfg, configPath, err = run.DiscoverConfig()
 	}
 
 	if err != nil {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nvandessel/go4dot/internal/config"
+)
+
+// resolveIDArg interprets the legacy "[id] [config-path]" positional form
+// used by external and machine subcommands. With the global --config flag
+// set there is no guessing: positionals are always IDs. Without it, a
+// positional that exists on disk or has a YAML extension is still treated
+// as a config path for backward compatibility, with a deprecation note.
+func resolveIDArg(args []string) (id, configPathArg string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+
+	if configFlag != "" {
+		return args[0], ""
+	}
+
+	if len(args) >= 2 {
+		deprecatedConfigArg(args[1])
+		return args[0], args[1]
+	}
+
+	if _, err := os.Stat(args[0]); err == nil || config.IsConfigPath(args[0]) {
+		deprecatedConfigArg(args[0])
+		return "", args[0]
+	}
+
+	return args[0], ""
+}
+
+// deprecatedConfigArg nudges users toward the unambiguous --config flag
+func deprecatedConfigArg(path string) {
+	fmt.Fprintf(os.Stderr, "Note: passing a config path as a positional argument is deprecated, use --config %s\n", path)
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/stow"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -31,7 +36,7 @@ var configValidateCmd = &cobra.Command{
 			cfg, err = config.LoadFromPath(configPath)
 		} else {
 			// Discover config
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -47,6 +52,18 @@ var configValidateCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// Same-target collisions aren't schema errors but almost
+		// always a mistake - surface them as warnings
+		if root, rootErr := config.ResolveRepoRoot(configPath); rootErr == nil {
+			if collisions, colErr := config.DetectTargetCollisions(cfg, root); colErr == nil && len(collisions) > 0 {
+				fmt.Println("Warning: multiple configs manage the same target path:")
+				for _, col := range collisions {
+					fmt.Printf("  %s: %s\n", col.Target, strings.Join(col.Configs, ", "))
+				}
+				fmt.Println()
+			}
+		}
+
 		fmt.Println("Configuration is valid")
 		fmt.Printf("  Schema version: %s\n", cfg.SchemaVersion)
 		fmt.Printf("  Name: %s\n", cfg.Metadata.Name)
@@ -69,7 +86,7 @@ var configShowCmd = &cobra.Command{
 			configPath = args[0]
 			cfg, err = config.LoadFromPath(configPath)
 		} else {
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -91,8 +108,229 @@ var configShowCmd = &cobra.Command{
 	},
 }
 
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the resolved config file location",
+	Long: `Print the absolute path of the config file that discovery would use,
+and the dotfiles directory derived from it.
+
+Useful for scripting and for debugging which file g4d picks up when
+several candidates exist.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, configPath, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "No config found. Searched:")
+			for _, dir := range config.ConfigSearchPaths() {
+				for _, name := range config.ConfigFileNames {
+					fmt.Fprintf(os.Stderr, "  %s\n", filepath.Join(dir, name))
+				}
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Config:   %s\n", configPath)
+		fmt.Printf("Dotfiles: %s\n", filepath.Dir(configPath))
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config in your editor and validate on save",
+	Long: `Open the resolved config file in $VISUAL or $EDITOR (falling back to
+vi or nano), then reload and validate it once the editor exits.
+
+On validation failure the errors are shown and you're offered the
+editor again, collapsing the edit-validate-fix loop into one command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, configPath, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		editor, err := resolveEditor()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for {
+			if err := openEditor(editor, configPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
+				os.Exit(1)
+			}
+
+			cfg, err := config.Load(configPath)
+			if err == nil {
+				err = cfg.Validate()
+			}
+			if err == nil {
+				fmt.Println("Configuration is valid")
+				return
+			}
+
+			fmt.Fprintf(os.Stderr, "Validation failed:\n%v\n", err)
+			if nonInteractive {
+				os.Exit(1)
+			}
+
+			fmt.Print("\nRe-open the editor to fix it? [Y/n] ")
+			reader := bufio.NewReader(os.Stdin)
+			response, _ := reader.ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response == "n" || response == "no" {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// resolveEditor picks the editor to use: $VISUAL, then $EDITOR, then
+// whichever of vi or nano is installed
+func resolveEditor() (string, error) {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if v := os.Getenv(env); v != "" {
+			return v, nil
+		}
+	}
+	for _, name := range []string{"vi", "nano"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no editor found: set $EDITOR or $VISUAL")
+}
+
+// openEditor runs the editor attached to the terminal. The editor value
+// may include arguments (e.g. "code --wait").
+func openEditor(editor, path string) error {
+	parts := strings.Fields(editor)
+	parts = append(parts, path)
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+var configArchiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Archive a config (soft-delete)",
+	Long: `Move a config from core/optional into the archived list, rewriting
+the config file.
+
+An archived config keeps its files in the repo but is skipped by
+install and refresh until it is unarchived (or those commands are given
+--include-archived). Pass --unstow to also remove its symlinks now.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, configPath, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		name := args[0]
+		item, err := cfg.Archive(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cfg.Save(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Archived '%s' (files stay in the repo)\n", name)
+
+		if unstow, _ := cmd.Flags().GetBool("unstow"); unstow {
+			result := stow.UnstowConfigs(cmd.Context(), filepath.Dir(configPath), []config.ConfigItem{item}, stow.StowOptions{
+				DotfilesMode: cfg.Stow.Dotfiles,
+				ProgressFunc: func(current, total int, msg string) {
+					fmt.Println(msg)
+				},
+			})
+			if len(result.Failed) > 0 {
+				for _, fail := range result.Failed {
+					fmt.Fprintf(os.Stderr, "Error unstowing %s: %v\n", fail.ConfigName, fail.Error)
+				}
+				os.Exit(1)
+			}
+			fmt.Printf("Unstowed '%s'\n", name)
+		} else {
+			fmt.Printf("Symlinks left in place; run 'g4d stow remove %s' to unstow\n", name)
+		}
+	},
+}
+
+var configUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <name>",
+	Short: "Move an archived config back to core or optional",
+	Long: `Move a config out of the archived list back into the active groups,
+rewriting the config file.
+
+Archiving is a soft-delete: an archived config keeps its files in the
+repo but is never stowed by install or refresh unless those commands
+are given --include-archived. Unarchiving makes it part of normal
+installs again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, configPath, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		group := "optional"
+		if core, _ := cmd.Flags().GetBool("core"); core {
+			group = "core"
+		}
+
+		name := args[0]
+		if err := cfg.Unarchive(name, group); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cfg.Save(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Unarchived '%s' into %s configs\n", name, group)
+		fmt.Printf("Run 'g4d stow add %s' to stow it\n", name)
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Emit a JSON Schema for .go4dot.yaml",
+	Long: `Emit a JSON Schema describing the .go4dot.yaml format.
+
+Write it somewhere your editor can reach and reference it from a config:
+
+  g4d config schema > go4dot.schema.json
+  # yaml-language-server: $schema=./go4dot.schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := config.JSONSchema()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configArchiveCmd)
+	configCmd.AddCommand(configUnarchiveCmd)
+
+	configArchiveCmd.Flags().Bool("unstow", false, "Also remove the config's symlinks")
+	configUnarchiveCmd.Flags().Bool("core", false, "Restore into the core group instead of optional")
 }
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
-	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/state"
+	"github.com/nvandessel/go4dot/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +32,7 @@ var depsCheckCmd = &cobra.Command{
 		if len(args) > 0 {
 			cfg, err = config.LoadFromPath(args[0])
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -38,19 +41,33 @@ var depsCheckCmd = &cobra.Command{
 		}
 
 		// Detect platform
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
 		}
 
+		group, _ := cmd.Flags().GetString("group")
+		applyAssumeInstalled(cmd, cfg)
+
 		// Check dependencies
-		result, err := deps.Check(cfg, p)
+		result, err := deps.CheckGroup(cfg, p, group)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error checking dependencies: %v\n", err)
 			os.Exit(1)
 		}
 
+		if format, _ := cmd.Flags().GetString("format"); format != "" && format != "table" {
+			if err := renderFormatTemplate(format, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if len(result.GetMissingCritical()) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Display results
 		fmt.Println("Dependency Status")
 		fmt.Println("-----------------")
@@ -60,27 +77,21 @@ var depsCheckCmd = &cobra.Command{
 		// Show critical deps
 		if len(result.Critical) > 0 {
 			fmt.Println("Critical Dependencies:")
-			for _, dep := range result.Critical {
-				printDepStatus(dep)
-			}
+			printDepTier(result.Critical)
 			fmt.Println()
 		}
 
 		// Show core deps
 		if len(result.Core) > 0 {
 			fmt.Println("Core Dependencies:")
-			for _, dep := range result.Core {
-				printDepStatus(dep)
-			}
+			printDepTier(result.Core)
 			fmt.Println()
 		}
 
 		// Show optional deps
 		if len(result.Optional) > 0 {
 			fmt.Println("Optional Dependencies:")
-			for _, dep := range result.Optional {
-				printDepStatus(dep)
-			}
+			printDepTier(result.Optional)
 		}
 
 		// Exit with error if critical deps are missing
@@ -104,7 +115,7 @@ var depsInstallCmd = &cobra.Command{
 		if len(args) > 0 {
 			cfg, err = config.LoadFromPath(args[0])
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -113,14 +124,17 @@ var depsInstallCmd = &cobra.Command{
 		}
 
 		// Detect platform
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
 		}
 
+		group, _ := cmd.Flags().GetString("group")
+		applyAssumeInstalled(cmd, cfg)
+
 		// Check current status
-		checkResult, err := deps.Check(cfg, p)
+		checkResult, err := deps.CheckGroup(cfg, p, group)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error checking dependencies: %v\n", err)
 			os.Exit(1)
@@ -137,6 +151,7 @@ var depsInstallCmd = &cobra.Command{
 		// Install with progress
 		opts := deps.InstallOptions{
 			OnlyMissing: true,
+			Group:       group,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -146,12 +161,28 @@ var depsInstallCmd = &cobra.Command{
 			},
 		}
 
-		result, err := deps.Install(cfg, p, opts)
+		result, err := deps.Install(cmd.Context(), cfg, p, opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error during installation: %v\n", err)
 			os.Exit(1)
 		}
 
+		// Record what go4dot installed so it can be removed later
+		if len(result.Installed) > 0 {
+			st, stErr := state.Load()
+			if stErr == nil {
+				if st == nil {
+					st = state.New()
+				}
+				for _, dep := range result.Installed {
+					st.AddInstalledPackage(dep.Name)
+				}
+				if err := st.Save(); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
+				}
+			}
+		}
+
 		// Show results
 		fmt.Println()
 		fmt.Printf("Installed: %d packages\n", len(result.Installed))
@@ -165,29 +196,225 @@ var depsInstallCmd = &cobra.Command{
 	},
 }
 
-func printDepStatus(dep deps.DependencyCheck) {
-	status := "x"
-	info := "missing"
+var depsUninstallCmd = &cobra.Command{
+	Use:   "uninstall [name]",
+	Short: "Remove packages installed by go4dot",
+	Long: `Remove system packages for dependencies.
+
+With a name argument, removes that single dependency.
+Without arguments, removes all packages that go4dot itself installed
+(tracked in the state file); packages that were already present on the
+system are left alone.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load config
+		cfg, _, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Detect platform
+		p, err := run.Platform()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Determine which packages to remove
+		var names []string
+		if len(args) > 0 {
+			names = []string{args[0]}
+		} else {
+			st, err := state.Load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+				os.Exit(1)
+			}
+			if st != nil {
+				names = st.InstalledPackages
+			}
+			if len(names) == 0 {
+				fmt.Println("No packages recorded as installed by go4dot.")
+				return
+			}
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		// Confirm unless --force
+		if !force {
+			fmt.Printf("This will remove the following packages: %s\n", strings.Join(names, ", "))
+			fmt.Println("Warning: removing shared packages can affect other software on this system.")
+			fmt.Print("\nAre you sure? [y/N] ")
+
+			reader := bufio.NewReader(os.Stdin)
+			response, _ := reader.ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+
+			if response != "y" && response != "yes" {
+				fmt.Println("Aborted.")
+				return
+			}
+			fmt.Println()
+		}
+
+		opts := deps.UninstallOptions{
+			ProgressFunc: func(current, total int, msg string) {
+				if total > 0 && current > 0 {
+					fmt.Printf("[%d/%d] %s\n", current, total, msg)
+				} else {
+					fmt.Println(msg)
+				}
+			},
+		}
+
+		result, err := deps.Uninstall(cmd.Context(), cfg, p, names, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error during uninstall: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Drop removed packages from state
+		if st, stErr := state.Load(); stErr == nil && st != nil {
+			for _, name := range result.Removed {
+				st.RemoveInstalledPackage(name)
+			}
+			if err := st.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
+			}
+		}
+
+		// Show results
+		fmt.Println()
+		fmt.Printf("Removed: %d packages\n", len(result.Removed))
+		if len(result.Failed) > 0 {
+			fmt.Printf("Failed: %d packages\n", len(result.Failed))
+			for _, fail := range result.Failed {
+				fmt.Printf("  - %s: %v\n", fail.Name, fail.Error)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+var depsExportCmd = &cobra.Command{
+	Use:   "export [config-path]",
+	Short: "Export the dependency list for native tooling",
+	Long: `Emit the config's dependencies as a native package list.
 
-	if dep.Status == deps.StatusInstalled {
-		status = "+"
+Formats: brewfile (Homebrew Bundle), apt-list, pacman-list.
+Without --format, the format matching the detected package manager is
+used. Pipe or redirect the output, e.g.:
+
+  g4d deps export --format brewfile > Brewfile && brew bundle`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg *config.Config
+		var err error
+
+		if len(args) > 0 {
+			cfg, err = config.LoadFromPath(args[0])
+		} else {
+			cfg, _, err = run.DiscoverConfig()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		p, err := run.Platform()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
+			os.Exit(1)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = deps.DefaultExportFormat(p)
+			if format == "" {
+				fmt.Fprintf(os.Stderr, "Error: no export format matches package manager %q, use --format\n", p.PackageManager)
+				os.Exit(1)
+			}
+		}
+
+		out, err := deps.ExportList(cfg, p, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Print(out)
+	},
+}
+
+// applyAssumeInstalled merges the --assume-installed flag values into the
+// config's allowlist so the checks below treat those names as installed
+func applyAssumeInstalled(cmd *cobra.Command, cfg *config.Config) {
+	assume, _ := cmd.Flags().GetStringSlice("assume-installed")
+	cfg.Dependencies.AssumeInstalled = append(cfg.Dependencies.AssumeInstalled, assume...)
+}
+
+// printDepTier prints one tier of checks as an aligned table, grouped
+// by category when any item declares one
+func printDepTier(checks []deps.DependencyCheck) {
+	groups := deps.ByCategory(checks)
+	flat := len(groups) == 1 && groups[0].Name == "Other"
+
+	for _, group := range groups {
+		if !flat {
+			fmt.Printf("  %s:\n", group.Name)
+		}
+		table := ui.NewTable("NAME", "STATUS", "DETAIL")
+		for _, dep := range group.Checks {
+			status, info := depStatusInfo(dep)
+			table.AddRow(ui.StatusStyle(status), dep.Item.Name, status, info)
+		}
+		table.Print()
+	}
+}
+
+// depStatusInfo maps a check to its display status word and detail
+func depStatusInfo(dep deps.DependencyCheck) (status, info string) {
+	switch dep.Status {
+	case deps.StatusInstalled:
+		status = "installed"
 		info = dep.InstalledPath
+		if dep.SatisfiedBy != "" {
+			info = fmt.Sprintf("%s, via %s", info, dep.SatisfiedBy)
+		}
 		if dep.InstalledVersion != "" {
 			info = fmt.Sprintf("%s (v%s)", info, dep.InstalledVersion)
 		}
-	} else if dep.Status == deps.StatusVersionMismatch {
-		status = "!"
-		info = fmt.Sprintf("version mismatch: found v%s, want %s", dep.InstalledVersion, dep.RequiredVersion)
-	} else if dep.Status == deps.StatusCheckFailed {
-		status = "?"
-		info = fmt.Sprintf("check failed: %v", dep.Error)
+	case deps.StatusAssumed:
+		status = "assumed installed"
+		info = "via assume_installed override"
+	case deps.StatusVersionMismatch:
+		status = "version mismatch"
+		info = fmt.Sprintf("found v%s, want %s", dep.InstalledVersion, dep.RequiredVersion)
+	case deps.StatusCheckFailed:
+		status = "check failed"
+		info = fmt.Sprintf("%v", dep.Error)
+	default:
+		status = "missing"
 	}
-
-	fmt.Printf("  %s %s (%s)\n", status, dep.Item.Name, info)
+	return status, info
 }
 
 func init() {
 	rootCmd.AddCommand(depsCmd)
 	depsCmd.AddCommand(depsCheckCmd)
 	depsCmd.AddCommand(depsInstallCmd)
+	depsCmd.AddCommand(depsUninstallCmd)
+	depsCmd.AddCommand(depsExportCmd)
+
+	depsExportCmd.Flags().String("format", "", "Output format: brewfile, apt-list, pacman-list")
+
+	depsUninstallCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	depsCheckCmd.Flags().String("group", "", "Only consider dependencies in this group")
+	depsCheckCmd.Flags().String("format", "", "Output format: \"table\" (default) or a Go template over the check result (fields: .Critical, .Core, .Optional)")
+	depsInstallCmd.Flags().String("group", "", "Only install dependencies in this group")
+	depsCheckCmd.Flags().StringSlice("assume-installed", nil, "Treat these dependency names as installed regardless of detection")
+	depsInstallCmd.Flags().StringSlice("assume-installed", nil, "Treat these dependency names as installed regardless of detection")
 }
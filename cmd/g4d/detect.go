@@ -1,10 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
-	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/stow"
 	"github.com/nvandessel/go4dot/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -14,12 +15,22 @@ var detectCmd = &cobra.Command{
 	Short: "Detect platform information",
 	Long:  "Detect and display information about the current platform (OS, distro, package manager)",
 	Run: func(cmd *cobra.Command, args []string) {
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			ui.Error("Error detecting platform: %v", err)
 			os.Exit(1)
 		}
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(p, "", "  ")
+			if err != nil {
+				ui.Error("Error encoding platform info: %v", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
 		ui.Section("Platform Information")
 		fmt.Printf("OS:              %s\n", p.OS)
 		if p.Distro != "" {
@@ -29,12 +40,26 @@ var detectCmd = &cobra.Command{
 			fmt.Printf("Version:         %s\n", p.DistroVersion)
 		}
 		fmt.Printf("Package Manager: %s\n", p.PackageManager)
+		if p.InitSystem != "" {
+			fmt.Printf("Init System:     %s\n", p.InitSystem)
+		}
+		if v, err := stow.ValidateStow(); err == nil && v != "" {
+			fmt.Printf("GNU Stow:        %s\n", v)
+		}
 		if p.IsWSL {
 			ui.Info("Running inside WSL")
 		}
+		if p.IsContainer {
+			ui.Info("Running inside a container")
+		}
+		if p.IsCI {
+			ui.Info("Running in CI")
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(detectCmd)
+
+	detectCmd.Flags().Bool("json", false, "Output platform info as JSON")
 }
@@ -21,11 +21,16 @@ var doctorCmd = &cobra.Command{
 		var dotfilesPath string
 		var err error
 
-		if len(args) > 0 {
+		configFlag, _ := cmd.Flags().GetString("config")
+
+		if configFlag != "" {
+			cfg, err = config.LoadFromPath(configFlag)
+			dotfilesPath = filepath.Dir(configFlag)
+		} else if len(args) > 0 {
 			cfg, err = config.LoadFromPath(args[0])
 			dotfilesPath = filepath.Dir(args[0])
 		} else {
-			cfg, dotfilesPath, err = config.LoadFromDiscovery()
+			cfg, dotfilesPath, err = run.DiscoverConfig()
 			if dotfilesPath != "" {
 				dotfilesPath = filepath.Dir(dotfilesPath)
 			}
@@ -68,4 +73,5 @@ func init() {
 
 	// Flags for doctor
 	doctorCmd.Flags().BoolP("verbose", "v", false, "Show detailed output including individual items")
+	doctorCmd.Flags().String("config", "", "Path to the config file to check")
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print platform facts for shell scripts",
+	Long: `Print GopherDot's view of the current machine in a shell-eval-able
+format, using the same variables hooks receive:
+
+    eval "$(g4d env)"
+
+GOPHERDOT_DOTFILES is only set when a config file can be discovered.
+Use --json for machine-readable output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := run.Platform()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Facts in a stable order so eval'd output is diffable
+		keys := []string{
+			"GOPHERDOT_OS",
+			"GOPHERDOT_DISTRO",
+			"GOPHERDOT_ARCH",
+			"GOPHERDOT_PACKAGE_MANAGER",
+			"GOPHERDOT_WSL",
+		}
+		facts := map[string]string{
+			"GOPHERDOT_OS":              p.OS,
+			"GOPHERDOT_DISTRO":          p.Distro,
+			"GOPHERDOT_ARCH":            p.Architecture,
+			"GOPHERDOT_PACKAGE_MANAGER": p.PackageManager,
+			"GOPHERDOT_WSL":             strconv.FormatBool(p.IsWSL),
+		}
+
+		// The dotfiles directory is only known when a config is discoverable
+		if _, configPath, err := run.DiscoverConfig(); err == nil {
+			keys = append(keys, "GOPHERDOT_DOTFILES")
+			facts["GOPHERDOT_DOTFILES"] = filepath.Dir(configPath)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(facts, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding facts: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		for _, key := range keys {
+			fmt.Printf("export %s=%s\n", key, shellQuote(facts[key]))
+		}
+	},
+}
+
+// shellQuote single-quotes a value for safe shell eval, escaping any
+// embedded single quotes
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+
+	envCmd.Flags().Bool("json", false, "Output the facts as JSON")
+}
@@ -1,13 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
-	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +36,7 @@ var externalStatusCmd = &cobra.Command{
 			}
 		} else {
 			var configPath string
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 			if err == nil {
 				repoRoot = filepath.Dir(configPath)
 			}
@@ -50,7 +52,7 @@ var externalStatusCmd = &cobra.Command{
 			return
 		}
 
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
@@ -62,30 +64,27 @@ var externalStatusCmd = &cobra.Command{
 		fmt.Println("----------------------------")
 
 		var installed, missing, skipped int
+		table := ui.NewTable("NAME", "STATUS", "DETAIL")
 		for _, s := range statuses {
-			var statusIcon string
 			var info string
 
 			switch s.Status {
 			case "installed":
-				statusIcon = "+"
 				info = s.Path
 				installed++
 			case "missing":
-				statusIcon = "x"
 				info = "not installed"
 				missing++
 			case "skipped":
-				statusIcon = "o"
 				info = s.Reason
 				skipped++
 			case "error":
-				statusIcon = "!"
 				info = s.Reason
 			}
 
-			fmt.Printf("  %s %s (%s)\n", statusIcon, s.Dep.Name, info)
+			table.AddRow(ui.StatusStyle(s.Status), s.Dep.Name, s.Status, info)
 		}
+		table.Print()
 
 		fmt.Printf("\nSummary: %d installed, %d missing, %d skipped\n", installed, missing, skipped)
 
@@ -95,13 +94,138 @@ var externalStatusCmd = &cobra.Command{
 	},
 }
 
+var externalTreeCmd = &cobra.Command{
+	Use:   "tree [config-path]",
+	Short: "Show the external dependency graph",
+	Long: `Print external dependencies as a tree in clone order.
+
+Deps declaring depends_on appear indented under their prerequisites, so
+the layout mirrors the order 'external clone' installs them; deps
+without ordering are listed flat. Each entry is marked installed or
+missing, and dependency cycles are reported as errors.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg *config.Config
+		var err error
+		var repoRoot string
+
+		if len(args) > 0 {
+			cfg, err = config.LoadFromPath(args[0])
+			if err == nil {
+				repoRoot, _ = config.ResolveRepoRoot(args[0])
+			}
+		} else {
+			var configPath string
+			cfg, configPath, err = run.DiscoverConfig()
+			if err == nil {
+				repoRoot = filepath.Dir(configPath)
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.External) == 0 {
+			fmt.Println("No external dependencies defined in config")
+			return
+		}
+
+		nodes, err := deps.ExternalTree(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		p, err := run.Platform()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
+			os.Exit(1)
+		}
+
+		statusByID := make(map[string]string)
+		for _, s := range deps.CheckExternalStatus(cfg, p, repoRoot) {
+			statusByID[s.Dep.ID] = s.Status
+		}
+
+		fmt.Println("External Dependency Tree")
+		fmt.Println("------------------------")
+
+		for _, node := range nodes {
+			marker := "✗"
+			switch statusByID[node.Dep.ID] {
+			case "installed":
+				marker = "✓"
+			case "skipped":
+				marker = "⊘"
+			}
+			fmt.Printf("%s%s %s (%s)\n", strings.Repeat("  ", node.Depth), marker, node.Dep.ID, node.Dep.Name)
+		}
+
+		fmt.Println("\n✓ installed  ✗ missing  ⊘ skipped on this platform")
+	},
+}
+
+var externalLockCmd = &cobra.Command{
+	Use:   "lock [config-path]",
+	Short: "Pin external dependencies to their installed commits",
+	Long: `Record the currently checked-out commit of every external dependency
+into ` + deps.LockFileName + ` next to the config.
+
+Commit the lockfile to the dotfiles repo, then replay the exact same
+commits on other machines with 'g4d install --from-lock'.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg *config.Config
+		var err error
+		var repoRoot string
+
+		if len(args) > 0 {
+			cfg, err = config.LoadFromPath(args[0])
+			if err == nil {
+				repoRoot, _ = config.ResolveRepoRoot(args[0])
+			}
+		} else {
+			var configPath string
+			cfg, configPath, err = run.DiscoverConfig()
+			if err == nil {
+				repoRoot = filepath.Dir(configPath)
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.External) == 0 {
+			fmt.Println("No external dependencies defined in config")
+			return
+		}
+
+		lock, err := deps.WriteLock(cmd.Context(), cfg, repoRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing lockfile: %v\n", err)
+			os.Exit(1)
+		}
+
+		ui.Success("Pinned %d external dependencies to %s", len(lock.External), filepath.Join(repoRoot, deps.LockFileName))
+	},
+}
+
 var externalCloneCmd = &cobra.Command{
 	Use:   "clone [id] [config-path]",
 	Short: "Clone external dependencies",
 	Long: `Clone external dependencies from their repositories.
 
 Without arguments, clones all missing external dependencies.
-With an ID argument, clones only that specific dependency.`,
+With an ID argument, clones only that specific dependency.
+Use the global --config flag to point at a specific config file.
+
+By default all dependencies are attempted and the command exits 1 if
+any of them failed. Use --keep-going to exit 0 as long as at least one
+succeeded, or --fail-fast to stop at the first failure.`,
 	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		var cfg *config.Config
@@ -109,20 +233,7 @@ With an ID argument, clones only that specific dependency.`,
 		var specificID string
 		var repoRoot string
 
-		// Parse arguments
-		configPathArg := ""
-		if len(args) >= 1 {
-			// Could be ID or config path
-			// If it looks like a path, treat it as such
-			if _, statErr := os.Stat(args[0]); statErr == nil || filepath.Ext(args[0]) == ".yaml" || filepath.Ext(args[0]) == ".yml" {
-				configPathArg = args[0]
-			} else {
-				specificID = args[0]
-				if len(args) >= 2 {
-					configPathArg = args[1]
-				}
-			}
-		}
+		specificID, configPathArg := resolveIDArg(args)
 
 		if configPathArg != "" {
 			cfg, err = config.LoadFromPath(configPathArg)
@@ -131,7 +242,7 @@ With an ID argument, clones only that specific dependency.`,
 			}
 		} else {
 			var configPath string
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 			if err == nil {
 				repoRoot = filepath.Dir(configPath)
 			}
@@ -147,14 +258,21 @@ With an ID argument, clones only that specific dependency.`,
 			return
 		}
 
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
 		}
 
+		keepGoing, _ := cmd.Flags().GetBool("keep-going")
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+		skipPostClone, _ := cmd.Flags().GetBool("skip-post-clone")
+
 		opts := deps.ExternalOptions{
-			RepoRoot: repoRoot,
+			FailFast:      failFast,
+			SkipPostClone: skipPostClone,
+			Offline:       offline,
+			RepoRoot:      repoRoot,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -167,7 +285,7 @@ With an ID argument, clones only that specific dependency.`,
 		if specificID != "" {
 			// Clone single
 			fmt.Printf("Cloning %s...\n\n", specificID)
-			err = deps.CloneSingle(cfg, p, specificID, opts)
+			err = deps.CloneSingle(cmd.Context(), cfg, p, specificID, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -176,7 +294,7 @@ With an ID argument, clones only that specific dependency.`,
 		} else {
 			// Clone all
 			fmt.Printf("Cloning %d external dependencies...\n\n", len(cfg.External))
-			result, err := deps.CloneExternal(cfg, p, opts)
+			result, err := deps.CloneExternal(cmd.Context(), cfg, p, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -190,6 +308,12 @@ With an ID argument, clones only that specific dependency.`,
 			if len(result.Updated) > 0 {
 				fmt.Printf("Updated: %d\n", len(result.Updated))
 			}
+			if len(result.Pruned) > 0 {
+				fmt.Printf("Pruned: %d\n", len(result.Pruned))
+				for _, p := range result.Pruned {
+					fmt.Printf("  - %s (%s)\n", p.ID, p.Path)
+				}
+			}
 			if len(result.Skipped) > 0 {
 				fmt.Printf("Skipped: %d\n", len(result.Skipped))
 			}
@@ -198,7 +322,10 @@ With an ID argument, clones only that specific dependency.`,
 				for _, fail := range result.Failed {
 					fmt.Printf("  - %s: %v\n", fail.Dep.Name, fail.Error)
 				}
-				os.Exit(1)
+				// With --keep-going, a partial success still exits 0
+				if !keepGoing || len(result.Cloned)+len(result.Updated) == 0 {
+					os.Exit(1)
+				}
 			}
 		}
 	},
@@ -210,7 +337,8 @@ var externalUpdateCmd = &cobra.Command{
 	Long: `Pull updates for installed external dependencies.
 
 Without arguments, updates all installed external dependencies.
-With an ID argument, updates only that specific dependency.`,
+With an ID argument, updates only that specific dependency.
+Use the global --config flag to point at a specific config file.`,
 	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		var cfg *config.Config
@@ -218,17 +346,7 @@ With an ID argument, updates only that specific dependency.`,
 		var specificID string
 		var repoRoot string
 
-		configPathArg := ""
-		if len(args) >= 1 {
-			if _, statErr := os.Stat(args[0]); statErr == nil || filepath.Ext(args[0]) == ".yaml" || filepath.Ext(args[0]) == ".yml" {
-				configPathArg = args[0]
-			} else {
-				specificID = args[0]
-				if len(args) >= 2 {
-					configPathArg = args[1]
-				}
-			}
-		}
+		specificID, configPathArg := resolveIDArg(args)
 
 		if configPathArg != "" {
 			cfg, err = config.LoadFromPath(configPathArg)
@@ -237,7 +355,7 @@ With an ID argument, updates only that specific dependency.`,
 			}
 		} else {
 			var configPath string
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 			if err == nil {
 				repoRoot = filepath.Dir(configPath)
 			}
@@ -253,15 +371,47 @@ With an ID argument, updates only that specific dependency.`,
 			return
 		}
 
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
 		}
 
+		skipPostClone, _ := cmd.Flags().GetBool("skip-post-clone")
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		// Pruning deletes directories, so confirm the candidates first
+		if prune && specificID == "" && !nonInteractive {
+			candidates, err := deps.PruneCandidates(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if len(candidates) > 0 {
+				fmt.Println("The following installs are no longer in config and will be removed:")
+				for _, c := range candidates {
+					fmt.Printf("  - %s (%s)\n", c.ID, c.Path)
+				}
+				fmt.Print("\nAre you sure? [y/N] ")
+
+				reader := bufio.NewReader(os.Stdin)
+				response, _ := reader.ReadString('\n')
+				response = strings.TrimSpace(strings.ToLower(response))
+
+				if response != "y" && response != "yes" {
+					fmt.Println("Skipping prune.")
+					prune = false
+				}
+				fmt.Println()
+			}
+		}
+
 		opts := deps.ExternalOptions{
-			Update:   true,
-			RepoRoot: repoRoot,
+			Update:        true,
+			SkipPostClone: skipPostClone,
+			Prune:         prune,
+			Offline:       offline,
+			RepoRoot:      repoRoot,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -274,7 +424,7 @@ With an ID argument, updates only that specific dependency.`,
 		if specificID != "" {
 			// Update single
 			fmt.Printf("Updating %s...\n\n", specificID)
-			err = deps.CloneSingle(cfg, p, specificID, opts)
+			err = deps.CloneSingle(cmd.Context(), cfg, p, specificID, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -283,7 +433,7 @@ With an ID argument, updates only that specific dependency.`,
 		} else {
 			// Update all
 			fmt.Printf("Updating %d external dependencies...\n\n", len(cfg.External))
-			result, err := deps.CloneExternal(cfg, p, opts)
+			result, err := deps.CloneExternal(cmd.Context(), cfg, p, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -294,9 +444,18 @@ With an ID argument, updates only that specific dependency.`,
 			if len(result.Updated) > 0 {
 				fmt.Printf("Updated: %d\n", len(result.Updated))
 			}
+			if len(result.UpToDate) > 0 {
+				fmt.Printf("Already up to date: %d\n", len(result.UpToDate))
+			}
 			if len(result.Cloned) > 0 {
 				fmt.Printf("Cloned (new): %d\n", len(result.Cloned))
 			}
+			if len(result.Pruned) > 0 {
+				fmt.Printf("Pruned: %d\n", len(result.Pruned))
+				for _, p := range result.Pruned {
+					fmt.Printf("  - %s (%s)\n", p.ID, p.Path)
+				}
+			}
 			if len(result.Skipped) > 0 {
 				fmt.Printf("Skipped: %d\n", len(result.Skipped))
 			}
@@ -311,6 +470,77 @@ With an ID argument, updates only that specific dependency.`,
 	},
 }
 
+var externalAddCmd = &cobra.Command{
+	Use:   "add <name> <url> [config-path]",
+	Short: "Add an external dependency to the config",
+	Long: `Append an external dependency (plugin, theme, etc.) to the config.
+
+The ID is generated from the name. Example:
+
+  g4d external add "Pure Prompt" https://github.com/sindresorhus/pure.git \
+    --dest ~/.zsh/pure --condition os=linux`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		url := args[1]
+
+		var cfg *config.Config
+		var configPath string
+		var err error
+
+		if len(args) > 2 {
+			cfg, err = config.LoadFromPath(args[2])
+			configPath = args[2]
+		} else {
+			cfg, configPath, err = run.DiscoverConfig()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		dest, _ := cmd.Flags().GetString("dest")
+		method, _ := cmd.Flags().GetString("method")
+		branch, _ := cmd.Flags().GetString("branch")
+		conditions, _ := cmd.Flags().GetStringArray("condition")
+
+		dep := config.ExternalDep{
+			Name:        name,
+			URL:         url,
+			Branch:      branch,
+			Destination: dest,
+			Method:      method,
+		}
+
+		for _, cond := range conditions {
+			parts := strings.SplitN(cond, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid condition '%s', expected key=value\n", cond)
+				os.Exit(1)
+			}
+			if dep.Condition == nil {
+				dep.Condition = make(map[string]string)
+			}
+			dep.Condition[parts[0]] = parts[1]
+		}
+
+		if err := cfg.AddExternal(dep); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := cfg.Save(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		added := cfg.External[len(cfg.External)-1]
+		fmt.Printf("Added external dependency '%s' (id: %s)\n", added.Name, added.ID)
+		fmt.Println("Run 'g4d external clone' to install it.")
+	},
+}
+
 var externalRemoveCmd = &cobra.Command{
 	Use:   "remove <id> [config-path]",
 	Short: "Remove an external dependency",
@@ -330,7 +560,7 @@ var externalRemoveCmd = &cobra.Command{
 			}
 		} else {
 			var configPath string
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 			if err == nil {
 				repoRoot = filepath.Dir(configPath)
 			}
@@ -363,7 +593,21 @@ var externalRemoveCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(externalCmd)
 	externalCmd.AddCommand(externalStatusCmd)
+	externalCmd.AddCommand(externalTreeCmd)
+	externalCmd.AddCommand(externalLockCmd)
 	externalCmd.AddCommand(externalCloneCmd)
 	externalCmd.AddCommand(externalUpdateCmd)
+	externalCmd.AddCommand(externalAddCmd)
 	externalCmd.AddCommand(externalRemoveCmd)
+
+	externalCloneCmd.Flags().Bool("keep-going", false, "Exit 0 if at least one dependency succeeded")
+	externalCloneCmd.Flags().Bool("fail-fast", false, "Stop at the first failure")
+	externalCloneCmd.Flags().Bool("skip-post-clone", false, "Don't run post_clone commands")
+	externalUpdateCmd.Flags().Bool("skip-post-clone", false, "Don't run post_clone commands")
+	externalUpdateCmd.Flags().Bool("prune", false, "Remove installs that are no longer in config")
+
+	externalAddCmd.Flags().String("dest", "", "Destination path for the clone (required)")
+	externalAddCmd.Flags().String("method", "clone", "Install method: clone or copy")
+	externalAddCmd.Flags().String("branch", "", "Branch or tag to clone")
+	externalAddCmd.Flags().StringArray("condition", nil, "Condition like os=linux (repeatable)")
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// renderFormatTemplate executes a user-supplied Go template (--format)
+// against the same typed struct the JSON output uses, printing the
+// result to stdout. Template errors come back as clear messages rather
+// than panics.
+func renderFormatTemplate(format string, data interface{}) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute --format template: %w", err)
+	}
+
+	out := buf.String()
+	fmt.Print(out)
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		fmt.Println()
+	}
+	return nil
+}
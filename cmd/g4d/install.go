@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/deps"
 	"github.com/nvandessel/go4dot/internal/setup"
 	"github.com/nvandessel/go4dot/internal/ui"
 	"github.com/spf13/cobra"
@@ -39,7 +40,7 @@ Use flags to customize the installation:
 			cfg, err = config.LoadFromPath(args[0])
 			configPath = args[0]
 		} else {
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -53,19 +54,60 @@ Use flags to customize the installation:
 		auto, _ := cmd.Flags().GetBool("auto")
 		minimal, _ := cmd.Flags().GetBool("minimal")
 		skipDeps, _ := cmd.Flags().GetBool("skip-deps")
+		skipOptionalDeps, _ := cmd.Flags().GetBool("skip-optional-deps")
 		skipExternal, _ := cmd.Flags().GetBool("skip-external")
 		skipMachine, _ := cmd.Flags().GetBool("skip-machine")
 		skipStow, _ := cmd.Flags().GetBool("skip-stow")
 		overwrite, _ := cmd.Flags().GetBool("overwrite")
+		force, _ := cmd.Flags().GetBool("force")
+		keepGoing, _ := cmd.Flags().GetBool("keep-going")
+		fromLock, _ := cmd.Flags().GetBool("from-lock")
+		include, _ := cmd.Flags().GetStringSlice("include")
+		exclude, _ := cmd.Flags().GetStringSlice("exclude")
+		includeArchived, _ := cmd.Flags().GetBool("include-archived")
+		applyAssumeInstalled(cmd, cfg)
+
+		if len(include) > 0 && !minimal {
+			fmt.Fprintln(os.Stderr, "Error: --include only applies to --minimal installs")
+			os.Exit(1)
+		}
+		if len(exclude) > 0 && minimal {
+			fmt.Fprintln(os.Stderr, "Error: --exclude cannot be combined with --minimal (use --include instead)")
+			os.Exit(1)
+		}
+
+		// Reproducible installs: load and verify the lockfile up front
+		// so a stale lock fails with one clear message instead of a
+		// per-dep error halfway through
+		var lock *deps.LockFile
+		if fromLock {
+			lock, err = deps.ReadLock(dotfilesPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := lock.Verify(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
 		opts := setup.InstallOptions{
-			Auto:         auto,
-			Minimal:      minimal,
-			SkipDeps:     skipDeps,
-			SkipExternal: skipExternal,
-			SkipMachine:  skipMachine,
-			SkipStow:     skipStow,
-			Overwrite:    overwrite,
+			Auto:             auto,
+			Minimal:          minimal,
+			IncludeConfigs:   include,
+			ExcludeConfigs:   exclude,
+			IncludeArchived:  includeArchived,
+			SkipDeps:         skipDeps,
+			SkipOptionalDeps: skipOptionalDeps,
+			SkipExternal:     skipExternal,
+			SkipMachine:      skipMachine,
+			SkipStow:         skipStow,
+			Overwrite:        overwrite,
+			Force:            force,
+			Offline:          offline,
+			Lock:             lock,
+			KeepGoing:        keepGoing,
 			ProgressFunc: func(current, total int, msg string) {
 				// Simple heuristic to style the output from setup package
 				if len(msg) > 0 && msg[0] == '\n' {
@@ -124,7 +166,7 @@ Use flags to customize the installation:
 			fmt.Printf("Config:   %s\n", cfg.Metadata.Name)
 		}
 
-		result, err := setup.Install(cfg, dotfilesPath, opts)
+		result, err := setup.Install(cmd.Context(), cfg, dotfilesPath, opts)
 		if err != nil {
 			ui.Error("%s", err.Error())
 			os.Exit(1)
@@ -143,6 +185,9 @@ Use flags to customize the installation:
 			}
 			for _, e := range result.ConfigsFailed {
 				ui.Error("Config %s: %v", e.ConfigName, e.Error)
+				if e.Hint != "" {
+					ui.Info("Hint: %s", e.Hint)
+				}
 			}
 			for _, e := range result.ExternalFailed {
 				ui.Error("External %s: %v", e.Dep.Name, e.Error)
@@ -176,9 +221,17 @@ func init() {
 	// Flags for install
 	installCmd.Flags().Bool("auto", false, "Non-interactive mode, use defaults")
 	installCmd.Flags().Bool("minimal", false, "Only install core configs, skip optional")
+	installCmd.Flags().StringSlice("include", nil, "With --minimal, optional configs to install anyway")
+	installCmd.Flags().StringSlice("exclude", nil, "Configs to skip during a full install")
+	installCmd.Flags().Bool("include-archived", false, "Also stow archived (soft-deleted) configs")
 	installCmd.Flags().Bool("skip-deps", false, "Skip dependency installation")
+	installCmd.Flags().Bool("skip-optional-deps", false, "Install critical and core dependencies only, skip the optional tier")
 	installCmd.Flags().Bool("skip-external", false, "Skip external dependency cloning")
 	installCmd.Flags().Bool("skip-machine", false, "Skip machine-specific configuration")
 	installCmd.Flags().Bool("skip-stow", false, "Skip stowing configs")
 	installCmd.Flags().Bool("overwrite", false, "Overwrite existing files")
+	installCmd.Flags().Bool("force", false, "Restow configs even when their links are already correct")
+	installCmd.Flags().Bool("keep-going", false, "Attempt every step and item, report all failures at the end")
+	installCmd.Flags().Bool("from-lock", false, "Only install external deps pinned in "+deps.LockFileName+", at exactly the locked commits")
+	installCmd.Flags().StringSlice("assume-installed", nil, "Treat these dependency names as installed regardless of detection")
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,7 +9,6 @@ import (
 	"github.com/charmbracelet/huh"
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/machine"
-	"github.com/nvandessel/go4dot/internal/platform"
 	"github.com/nvandessel/go4dot/internal/setup"
 	"github.com/nvandessel/go4dot/internal/state"
 	"github.com/nvandessel/go4dot/internal/stow"
@@ -35,6 +35,10 @@ func runInteractive(cmd *cobra.Command, args []string) {
 	// Check for updates in background
 	updateMsgChan := make(chan string, 1)
 	go func() {
+		if offline {
+			updateMsgChan <- ""
+			return
+		}
 		res, err := version.CheckForUpdates(Version)
 		if err == nil && res != nil && res.IsOutdated {
 			updateMsgChan <- fmt.Sprintf("Update: %s -> %s", res.CurrentVersion, res.LatestVersion)
@@ -47,7 +51,7 @@ func runInteractive(cmd *cobra.Command, args []string) {
 	updateMsg := ""
 
 	// Detect platform once
-	p, _ := platform.Detect()
+	p, _ := run.Platform()
 
 	// Main application loop - stays in the app until user quits
 	for {
@@ -61,7 +65,7 @@ func runInteractive(cmd *cobra.Command, args []string) {
 		}
 
 		// Try to load config to determine context
-		cfg, configPath, err := config.LoadFromDiscovery()
+		cfg, configPath, err := run.DiscoverConfig()
 		hasConfig := err == nil && cfg != nil
 
 		var result *dashboard.Result
@@ -89,7 +93,8 @@ func runInteractive(cmd *cobra.Command, args []string) {
 			// Get link status for all configs
 			linkStatus, _ := stow.GetAllConfigLinkStatus(cfg, dotfilesPath)
 
-			machineStatus := machine.CheckMachineConfigStatus(cfg)
+			dashPlatform, _ := run.Platform()
+			machineStatus := machine.CheckMachineConfigStatus(cfg, dashPlatform)
 
 			// Convert to dashboard type
 			var dashStatus []dashboard.MachineStatus
@@ -115,7 +120,7 @@ func runInteractive(cmd *cobra.Command, args []string) {
 		}
 
 		// Handle the action
-		shouldExit := handleAction(result, cfg, configPath)
+		shouldExit := handleAction(cmd.Context(), result, cfg, configPath)
 		if shouldExit {
 			fmt.Println("Bye!")
 			return
@@ -153,7 +158,7 @@ func runNoConfigPrompt() *dashboard.Result {
 }
 
 // handleAction processes the user's action and returns true if we should exit
-func handleAction(result *dashboard.Result, cfg *config.Config, configPath string) bool {
+func handleAction(ctx context.Context, result *dashboard.Result, cfg *config.Config, configPath string) bool {
 	switch result.Action {
 	case dashboard.ActionQuit:
 		return true
@@ -162,7 +167,7 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 		initCmd.Run(initCmd, nil)
 
 		// Check if config now exists and prompt for install
-		if newCfg, newConfigPath, err := config.LoadFromDiscovery(); err == nil {
+		if newCfg, newConfigPath, err := run.DiscoverConfig(); err == nil {
 			var runInstall bool
 			form := huh.NewForm(
 				huh.NewGroup(
@@ -201,7 +206,8 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 			if st == nil {
 				st = state.New()
 			}
-			_, err := stow.SyncAll(dotfilesPath, cfg, st, true, stow.StowOptions{
+			_, err := stow.SyncAll(ctx, dotfilesPath, cfg, st, true, stow.StowOptions{
+				DotfilesMode: cfg.Stow.Dotfiles,
 				ProgressFunc: func(current, total int, msg string) {
 					if total > 0 && current > 0 {
 						fmt.Printf("  [%d/%d] %s\n", current, total, msg)
@@ -225,7 +231,8 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 			if st == nil {
 				st = state.New()
 			}
-			err := stow.SyncSingle(dotfilesPath, result.ConfigName, cfg, st, stow.StowOptions{
+			err := stow.SyncSingle(ctx, dotfilesPath, result.ConfigName, cfg, st, stow.StowOptions{
+				DotfilesMode: cfg.Stow.Dotfiles,
 				ProgressFunc: func(current, total int, msg string) {
 					if total > 0 && current > 0 {
 						fmt.Printf("  [%d/%d] %s\n", current, total, msg)
@@ -275,6 +282,7 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 			st, _ := state.Load()
 			opts := setup.UpdateOptions{
 				UpdateExternal: true,
+				Offline:        offline,
 				ProgressFunc: func(current, total int, msg string) {
 					if total > 0 && current > 0 {
 						fmt.Printf("  [%d/%d] %s\n", current, total, msg)
@@ -283,7 +291,7 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 					}
 				},
 			}
-			if err := setup.Update(cfg, dotfilesPath, st, opts); err != nil {
+			if err := setup.Update(ctx, cfg, dotfilesPath, st, opts); err != nil {
 				ui.Error("%v", err)
 			} else {
 				ui.Success("Update complete")
@@ -293,13 +301,13 @@ func handleAction(result *dashboard.Result, cfg *config.Config, configPath strin
 
 	case dashboard.ActionList:
 		// This is the "More" menu
-		runMoreMenu(cfg, configPath)
+		runMoreMenu(ctx, cfg, configPath)
 	}
 
 	return false
 }
 
-func runMoreMenu(cfg *config.Config, configPath string) {
+func runMoreMenu(ctx context.Context, cfg *config.Config, configPath string) {
 	var action string
 	form := huh.NewForm(
 		huh.NewGroup(
@@ -322,8 +330,8 @@ func runMoreMenu(cfg *config.Config, configPath string) {
 	switch action {
 	case "list":
 		st, _ := state.Load()
-		p, _ := platform.Detect()
-		ui.PrintConfigList(cfg, st, p, true)
+		p, _ := run.Platform()
+		setup.PrintInventory(setup.Inventory(cfg, st, p), true)
 		waitForEnter()
 
 	case "external":
@@ -354,7 +362,7 @@ func runMoreMenu(cfg *config.Config, configPath string) {
 					}
 				},
 			}
-			if err := setup.Uninstall(cfg, dotfilesPath, st, opts); err != nil {
+			if err := setup.Uninstall(ctx, cfg, dotfilesPath, st, opts); err != nil {
 				ui.Error("%v", err)
 			} else {
 				ui.Success("Uninstall complete")
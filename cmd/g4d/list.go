@@ -1,13 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/nvandessel/go4dot/internal/config"
-	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/setup"
 	"github.com/nvandessel/go4dot/internal/state"
-	"github.com/nvandessel/go4dot/internal/ui"
+	"github.com/nvandessel/go4dot/internal/stow"
 	"github.com/spf13/cobra"
 )
 
@@ -25,12 +27,14 @@ Displays:
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load config
 		var cfg *config.Config
+		var configPath string
 		var err error
 
 		if len(args) > 0 {
 			cfg, err = config.LoadFromPath(args[0])
+			configPath = args[0]
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -45,20 +49,113 @@ Displays:
 		}
 
 		// Detect platform
-		p, err := platform.Detect()
+		p, err := run.Platform()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error detecting platform: %v\n", err)
 			os.Exit(1)
 		}
 
+		if tree, _ := cmd.Flags().GetBool("tree"); tree {
+			if err := printConfigTree(cfg, filepath.Dir(configPath), st); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		showAll, _ := cmd.Flags().GetBool("all")
+		installedOnly, _ := cmd.Flags().GetBool("installed")
+		pendingOnly, _ := cmd.Flags().GetBool("pending")
+		listType, _ := cmd.Flags().GetString("type")
+
+		switch listType {
+		case "", "configs", "external", "machine":
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid --type %q (expected configs, external, or machine)\n", listType)
+			os.Exit(1)
+		}
+
+		inventory := setup.Inventory(cfg, st, p).Filter(setup.InventoryFilter{
+			Installed: installedOnly,
+			Pending:   pendingOnly,
+			Type:      listType,
+		})
 
-		ui.PrintConfigList(cfg, st, p, showAll)
+		if format, _ := cmd.Flags().GetString("format"); format != "" && format != "table" {
+			if err := renderFormatTemplate(format, inventory); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(inventory, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding inventory: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		setup.PrintInventory(inventory, showAll)
 	},
 }
 
+// printConfigTree renders a file-level tree for each installed config:
+// every file in the package, its resolved $HOME target, and whether the
+// symlink currently exists and points at the right place. Falls back to
+// all configs when no state is recorded yet.
+func printConfigTree(cfg *config.Config, dotfilesPath string, st *state.State) error {
+	statuses, err := stow.GetAllConfigLinkStatus(cfg, dotfilesPath)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range cfg.GetAllConfigs() {
+		if st != nil && len(st.Configs) > 0 && !st.HasConfig(item.Name) {
+			continue
+		}
+		status, ok := statuses[item.Name]
+		if !ok {
+			continue
+		}
+
+		if status.TotalCount == 0 {
+			fmt.Printf("%s (no files)\n\n", item.Name)
+			continue
+		}
+
+		fmt.Printf("%s (%d/%d linked)\n", item.Name, status.LinkedCount, status.TotalCount)
+		for i, f := range status.Files {
+			branch := "├──"
+			if i == len(status.Files)-1 {
+				branch = "└──"
+			}
+
+			target := filepath.Join("~", f.TargetRel)
+
+			if f.IsLinked {
+				fmt.Printf("%s %s -> %s ✓\n", branch, f.RelPath, target)
+			} else {
+				fmt.Printf("%s %s -> %s ✗ %s\n", branch, f.RelPath, target, f.Issue)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().BoolP("all", "a", false, "Show all configs including platform-specific and archived")
+	listCmd.Flags().Bool("json", false, "Output the inventory as JSON")
+	listCmd.Flags().Bool("installed", false, "Only show installed/configured entries")
+	listCmd.Flags().Bool("pending", false, "Only show entries not yet installed/configured")
+	listCmd.Flags().String("type", "", "Limit output to one section: configs, external, or machine")
+	listCmd.Flags().String("format", "", "Output format: \"table\" (default) or a Go template over the inventory (fields: .Configs, .External, .Machine, .Archived)")
+	listCmd.Flags().Bool("tree", false, "Show a file-level tree of each installed config's symlink targets")
 }
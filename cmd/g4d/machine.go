@@ -3,10 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/machine"
+	"github.com/nvandessel/go4dot/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +29,7 @@ var machineStatusCmd = &cobra.Command{
 		if len(args) > 0 {
 			cfg, err = config.LoadFromPath(args[0])
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -41,7 +42,8 @@ var machineStatusCmd = &cobra.Command{
 			return
 		}
 
-		statuses := machine.CheckMachineConfigStatus(cfg)
+		p, _ := run.Platform()
+		statuses := machine.CheckMachineConfigStatus(cfg, p)
 		machine.PrintStatus(statuses)
 	},
 }
@@ -52,29 +54,22 @@ var machineConfigureCmd = &cobra.Command{
 	Long: `Interactively configure machine-specific settings.
 
 Without arguments, configures all machine settings.
-With an ID argument, configures only that specific setting.`,
+With an ID argument, configures only that specific setting.
+With --only field1,field2 (requires an ID), only the named fields are
+re-prompted; the rest keep their values from the previous run.
+Use the global --config flag to point at a specific config file.`,
 	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		var cfg *config.Config
 		var err error
 		var specificID string
 
-		configPathArg := ""
-		if len(args) >= 1 {
-			if _, statErr := os.Stat(args[0]); statErr == nil || filepath.Ext(args[0]) == ".yaml" || filepath.Ext(args[0]) == ".yml" {
-				configPathArg = args[0]
-			} else {
-				specificID = args[0]
-				if len(args) >= 2 {
-					configPathArg = args[1]
-				}
-			}
-		}
+		specificID, configPathArg := resolveIDArg(args)
 
 		if configPathArg != "" {
 			cfg, err = config.LoadFromPath(configPathArg)
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -89,9 +84,19 @@ With an ID argument, configures only that specific setting.`,
 
 		skipPrompts, _ := cmd.Flags().GetBool("defaults")
 		overwrite, _ := cmd.Flags().GetBool("overwrite")
+		updateManaged, _ := cmd.Flags().GetBool("update-managed")
+		merge, _ := cmd.Flags().GetBool("merge")
+		only, _ := cmd.Flags().GetStringSlice("only")
+
+		if len(only) > 0 && specificID == "" {
+			fmt.Fprintln(os.Stderr, "Error: --only requires a machine config ID")
+			os.Exit(1)
+		}
 
+		promptPlatform, _ := run.Platform()
 		promptOpts := machine.PromptOptions{
 			SkipPrompts: skipPrompts,
+			Platform:    promptPlatform,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -103,6 +108,7 @@ With an ID argument, configures only that specific setting.`,
 
 		renderOpts := machine.RenderOptions{
 			Overwrite: overwrite,
+			Merge:     merge,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -111,9 +117,32 @@ With an ID argument, configures only that specific setting.`,
 				}
 			},
 		}
+		if updateManaged {
+			renderOpts.OverwriteIfManaged = true
+			if st, stErr := state.Load(); stErr == nil && st != nil {
+				renderOpts.ManagedHashes = machineContentHashes(st)
+			}
+		}
 
 		if specificID != "" {
 			// Configure single
+			mc := machine.GetMachineConfigByID(cfg, specificID)
+			if mc == nil {
+				fmt.Fprintf(os.Stderr, "Error: machine config '%s' not found\n", specificID)
+				os.Exit(1)
+			}
+
+			if len(only) > 0 {
+				if err := validateOnlyFields(cfg, mc, only); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				promptOpts.Only = only
+				if st, stErr := state.Load(); stErr == nil && st != nil {
+					promptOpts.Existing = st.MachineConfig[specificID].Values
+				}
+			}
+
 			fmt.Printf("Configuring %s...\n\n", specificID)
 
 			result, err := machine.CollectSingleConfig(cfg, specificID, promptOpts)
@@ -122,12 +151,13 @@ With an ID argument, configures only that specific setting.`,
 				os.Exit(1)
 			}
 
-			mc := machine.GetMachineConfigByID(cfg, specificID)
-			_, err = machine.RenderAndWrite(mc, result.Values, renderOpts)
+			renderResult, err := machine.RenderAndWrite(cfg, mc, result.Values, renderOpts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+
+			saveMachineValues(cfg, mc, result.Values, renderResult)
 		} else {
 			// Configure all
 			fmt.Printf("Configuring %d machine settings...\n\n", len(cfg.MachineConfig))
@@ -138,17 +168,82 @@ With an ID argument, configures only that specific setting.`,
 				os.Exit(1)
 			}
 
-			_, err = machine.RenderAll(cfg, results, renderOpts)
+			renderResults, err := machine.RenderAll(cfg, results, renderOpts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+
+			renderedByID := make(map[string]*machine.RenderResult, len(renderResults))
+			for i := range renderResults {
+				renderedByID[renderResults[i].ID] = &renderResults[i]
+			}
+
+			for _, result := range results {
+				if mc := machine.GetMachineConfigByID(cfg, result.ID); mc != nil {
+					saveMachineValues(cfg, mc, result.Values, renderedByID[result.ID])
+				}
+			}
 		}
 
 		fmt.Println("\nConfiguration complete")
 	},
 }
 
+// validateOnlyFields checks every --only name against the config's own
+// prompts and the shared prompts, so typos fail up front instead of
+// silently keeping an old value
+func validateOnlyFields(cfg *config.Config, mc *config.MachinePrompt, only []string) error {
+	known := make(map[string]bool)
+	for _, field := range mc.Prompts {
+		known[field.ID] = true
+	}
+	for _, field := range cfg.SharedPrompts {
+		known[field.ID] = true
+	}
+
+	for _, name := range only {
+		if !known[name] {
+			return fmt.Errorf("unknown field '%s' in --only (config '%s')", name, mc.ID)
+		}
+	}
+	return nil
+}
+
+// saveMachineValues persists non-secret prompt values to state so later
+// 'configure --only' runs can carry over the unchanged fields
+func saveMachineValues(cfg *config.Config, mc *config.MachinePrompt, values map[string]string, rendered *machine.RenderResult) {
+	st, err := state.Load()
+	if err != nil {
+		return
+	}
+	if st == nil {
+		st = state.New()
+	}
+	st.SetMachineValues(mc.ID, machine.NonSecretValues(cfg, mc, values))
+	// Record what was actually written so overwrite-if-managed runs can
+	// tell untouched files from hand-edited ones; skipped files keep
+	// their previous hash
+	if rendered != nil && !rendered.Skipped {
+		st.SetMachineContentHash(mc.ID, machine.ContentHash(rendered.Content))
+	}
+	if err := st.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
+	}
+}
+
+// machineContentHashes returns the recorded generated-content hash per
+// machine config ID, for overwrite-if-managed rendering
+func machineContentHashes(st *state.State) map[string]string {
+	hashes := make(map[string]string, len(st.MachineConfig))
+	for id, ms := range st.MachineConfig {
+		if ms.ContentHash != "" {
+			hashes[id] = ms.ContentHash
+		}
+	}
+	return hashes
+}
+
 var machineShowCmd = &cobra.Command{
 	Use:   "show <id> [config-path]",
 	Short: "Preview a machine configuration",
@@ -163,7 +258,7 @@ var machineShowCmd = &cobra.Command{
 		if len(args) > 1 {
 			cfg, err = config.LoadFromPath(args[1])
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -184,7 +279,10 @@ var machineShowCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		content, err := machine.PreviewRender(mc, result.Values)
+		// Secrets never appear in preview output
+		values := machine.MaskSecretValues(cfg, mc, result.Values)
+
+		content, err := machine.PreviewRender(mc, values)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error rendering preview: %v\n", err)
 			os.Exit(1)
@@ -210,7 +308,7 @@ var machineRemoveCmd = &cobra.Command{
 		if len(args) > 1 {
 			cfg, err = config.LoadFromPath(args[1])
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -242,6 +340,77 @@ var machineRemoveCmd = &cobra.Command{
 	},
 }
 
+var machineTemplateTestCmd = &cobra.Command{
+	Use:   "template-test <id> [config-path]",
+	Short: "Render a machine config template with explicit values",
+	Long: `Render a machine configuration template without prompting.
+
+Values are supplied with repeated --set key=value flags and must cover
+every prompt field the template's config defines. The rendered output
+is printed to stdout, making this useful for CI checks and for quick
+iteration while authoring templates.
+
+Example:
+  g4d machine template-test gitconfig --set name="Jane Doe" --set email=jane@example.com`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+
+		var cfg *config.Config
+		var err error
+
+		if len(args) > 1 {
+			cfg, err = config.LoadFromPath(args[1])
+		} else {
+			cfg, _, err = run.DiscoverConfig()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		mc := machine.GetMachineConfigByID(cfg, id)
+		if mc == nil {
+			fmt.Fprintf(os.Stderr, "Error: machine config '%s' not found\n", id)
+			os.Exit(1)
+		}
+
+		// Parse --set key=value pairs
+		sets, _ := cmd.Flags().GetStringArray("set")
+		values := make(map[string]string)
+		for _, kv := range sets {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid --set value %q (expected key=value)\n", kv)
+				os.Exit(1)
+			}
+			values[parts[0]] = parts[1]
+		}
+
+		// Every prompt field must have an explicit value so the render
+		// is deterministic and missing references fail loudly.
+		var missing []string
+		for _, field := range mc.Prompts {
+			if _, ok := values[field.ID]; !ok {
+				missing = append(missing, field.ID)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: missing values for fields: %s\n", strings.Join(missing, ", "))
+			os.Exit(1)
+		}
+
+		content, err := machine.PreviewRender(mc, values)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering template: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Print(content)
+	},
+}
+
 var machineInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show system information for machine config",
@@ -264,8 +433,15 @@ func init() {
 	machineCmd.AddCommand(machineShowCmd)
 	machineCmd.AddCommand(machineRemoveCmd)
 	machineCmd.AddCommand(machineInfoCmd)
+	machineCmd.AddCommand(machineTemplateTestCmd)
 
 	// Flags for machine configure
 	machineConfigureCmd.Flags().Bool("defaults", false, "Use default values without prompting")
 	machineConfigureCmd.Flags().Bool("overwrite", false, "Overwrite existing configuration files")
+	machineConfigureCmd.Flags().Bool("update-managed", false, "Overwrite generated files only if unedited since the last run, skip hand-edited ones")
+	machineConfigureCmd.Flags().Bool("merge", false, "Merge rendered content between sentinel markers, keeping manual edits")
+	machineConfigureCmd.Flags().StringSlice("only", nil, "Only re-prompt these field IDs, keep previous values for the rest")
+
+	// Flags for machine template-test
+	machineTemplateTestCmd.Flags().StringArray("set", nil, "Set a template value (key=value, repeatable)")
 }
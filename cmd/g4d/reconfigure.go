@@ -3,11 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
+	"github.com/charmbracelet/huh"
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/machine"
 	"github.com/nvandessel/go4dot/internal/state"
+	"github.com/nvandessel/go4dot/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,7 @@ var reconfigureCmd = &cobra.Command{
 
 Without arguments, reconfigures all machine settings.
 With an ID argument, reconfigures only that specific setting.
+Use the global --config flag to point at a specific config file.
 
 This is useful when:
 - You want to change git user/email
@@ -30,22 +32,12 @@ This is useful when:
 		var err error
 		var specificID string
 
-		configPathArg := ""
-		if len(args) >= 1 {
-			if _, statErr := os.Stat(args[0]); statErr == nil || filepath.Ext(args[0]) == ".yaml" || filepath.Ext(args[0]) == ".yml" {
-				configPathArg = args[0]
-			} else {
-				specificID = args[0]
-				if len(args) >= 2 {
-					configPathArg = args[1]
-				}
-			}
-		}
+		specificID, configPathArg := resolveIDArg(args)
 
 		if configPathArg != "" {
 			cfg, err = config.LoadFromPath(configPathArg)
 		} else {
-			cfg, _, err = config.LoadFromDiscovery()
+			cfg, _, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -67,8 +59,10 @@ This is useful when:
 		overwrite := true // Always overwrite when reconfiguring
 		skipPrompts, _ := cmd.Flags().GetBool("defaults")
 
+		promptPlatform, _ := run.Platform()
 		promptOpts := machine.PromptOptions{
 			SkipPrompts: skipPrompts,
+			Platform:    promptPlatform,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -105,15 +99,18 @@ This is useful when:
 				os.Exit(1)
 			}
 
-			renderResult, err := machine.RenderAndWrite(mc, result.Values, renderOpts)
+			renderResult, err := machine.RenderAndWrite(cfg, mc, result.Values, renderOpts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
 
+			migrateOldDestination(st, cfg, specificID, renderOpts)
+
 			// Update state
 			if st != nil {
 				st.SetMachineConfig(specificID, renderResult.Destination, false, false)
+				st.SetMachineContentHash(specificID, machine.ContentHash(renderResult.Content))
 				if err := st.Save(); err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
 				}
@@ -139,7 +136,9 @@ This is useful when:
 			// Update state
 			if st != nil {
 				for _, r := range renderResults {
+					migrateOldDestination(st, cfg, r.ID, renderOpts)
 					st.SetMachineConfig(r.ID, r.Destination, false, false)
+					st.SetMachineContentHash(r.ID, machine.ContentHash(r.Content))
 				}
 				if err := st.Save(); err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: failed to save state: %v\n", err)
@@ -151,6 +150,57 @@ This is useful when:
 	},
 }
 
+// migrateOldDestination offers to remove the previously generated file
+// when a machine config's destination changed in the config file, so
+// refactored configs don't leave orphans behind. Non-interactive runs
+// leave the old file in place with a note.
+func migrateOldDestination(st *state.State, cfg *config.Config, id string, renderOpts machine.RenderOptions) {
+	if st == nil {
+		return
+	}
+	ms, ok := st.MachineConfig[id]
+	if !ok || ms.ConfigPath == "" {
+		return
+	}
+	mc := machine.GetMachineConfigByID(cfg, id)
+	if mc == nil {
+		return
+	}
+
+	// Check whether a stale file actually exists before prompting
+	dryOpts := renderOpts
+	dryOpts.DryRun = true
+	would, err := machine.MigrateDestination(mc, ms.ConfigPath, dryOpts)
+	if err != nil || !would {
+		return
+	}
+
+	if !ui.IsInteractive() {
+		fmt.Printf("Note: destination for %s changed; old file %s left in place\n", id, ms.ConfigPath)
+		return
+	}
+
+	var remove bool
+	err = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Destination for %s changed. Remove old file %s?", id, ms.ConfigPath)).
+				Affirmative("Yes").
+				Negative("No").
+				Value(&remove),
+		),
+	).Run()
+	if err != nil || !remove {
+		return
+	}
+
+	if _, err := machine.MigrateDestination(mc, ms.ConfigPath, renderOpts); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	fmt.Printf("Removed old machine config %s\n", ms.ConfigPath)
+}
+
 func init() {
 	rootCmd.AddCommand(reconfigureCmd)
 
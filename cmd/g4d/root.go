@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/nvandessel/go4dot/internal/ui"
+	"github.com/nvandessel/go4dot/internal/verbose"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +20,8 @@ var (
 
 	// Global flags
 	nonInteractive bool
+	offline        bool
+	configFlag     string
 )
 
 var rootCmd = &cobra.Command{
@@ -51,9 +57,16 @@ func init() {
 	// Global persistent flags
 	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Run without interactive prompts")
 	rootCmd.PersistentFlags().BoolP("yes", "y", false, "Alias for --non-interactive")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Print external commands as they are run")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Skip all network operations (git clone/pull, update checks)")
+	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colors and animated progress output")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Path to the config file or its directory (overrides discovery)")
 
 	// Set up PersistentPreRun to handle env vars and flag aliases
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		// Fresh memoization cache for this invocation
+		run = &runCache{}
+
 		// Check environment variables for non-interactive mode
 		if os.Getenv("GO4DOT_NON_INTERACTIVE") == "1" || os.Getenv("CI") == "true" {
 			nonInteractive = true
@@ -66,13 +79,39 @@ func init() {
 
 		// Propagate to ui package for use throughout the codebase
 		ui.SetNonInteractive(nonInteractive)
+
+		// Plain output also applies when running non-interactively
+		if noColor, _ := cmd.Flags().GetBool("no-color"); noColor || nonInteractive {
+			ui.SetPlainProgress(true)
+		}
+
+		// Enable verbose command logging if requested
+		if v, _ := cmd.Flags().GetBool("verbose"); v {
+			verbose.Set(true)
+		}
 	}
 
 	rootCmd.AddCommand(versionCmd)
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel the command context on Ctrl-C so long-running operations
+	// (git clones, package installs, stow runs) stop cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+
+	// On Ctrl-C the in-flight step unwinds via context cancellation;
+	// report what to do next instead of a raw "context canceled" error.
+	// A second Ctrl-C after stop() kills the process immediately.
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		stop()
+		fmt.Fprintln(os.Stderr, "\nInterrupted. Run 'g4d status' to check what completed.")
+		os.Exit(130)
+	}
+
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+// runCache memoizes per-invocation lookups so a single command only
+// detects the platform and discovers the config file once. Repeated
+// detection is wasteful on slow filesystems, and two detections in one
+// command could disagree (e.g. if PATH changes after a dep install).
+type runCache struct {
+	platformOnce sync.Once
+	platform     *platform.Platform
+	platformErr  error
+
+	configOnce sync.Once
+	config     *config.Config
+	configPath string
+	configErr  error
+}
+
+// run is the cache for the current command invocation. It is reset in
+// PersistentPreRun so each command starts fresh.
+var run = &runCache{}
+
+// Platform returns the detected platform, detecting it on first use.
+func (c *runCache) Platform() (*platform.Platform, error) {
+	c.platformOnce.Do(func() {
+		c.platform, c.platformErr = platform.Detect()
+	})
+	return c.platform, c.platformErr
+}
+
+// DiscoverConfig returns the discovered config and its path, walking the
+// search locations on first use only. The global --config flag bypasses
+// discovery entirely.
+func (c *runCache) DiscoverConfig() (*config.Config, string, error) {
+	c.configOnce.Do(func() {
+		if configFlag != "" {
+			c.configPath, c.configErr = config.ResolveConfigFilePath(configFlag)
+			if c.configErr == nil {
+				c.config, c.configErr = config.Load(c.configPath)
+			}
+			return
+		}
+		c.config, c.configPath, c.configErr = config.LoadFromDiscovery()
+	})
+	return c.config, c.configPath, c.configErr
+}
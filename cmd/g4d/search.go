@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/state"
+	"github.com/nvandessel/go4dot/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find configs, dependencies, and external deps by keyword",
+	Long: `Search the config for anything matching a keyword.
+
+Matches case-insensitively against config names and descriptions,
+dependency names, external dependency names and URLs, and machine
+config descriptions. Use --type to limit the scope.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, _, err := run.DiscoverConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		searchType, _ := cmd.Flags().GetString("type")
+		switch searchType {
+		case "", "config", "dependency", "external", "machine":
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid --type %q (expected config, dependency, external, or machine)\n", searchType)
+			os.Exit(1)
+		}
+
+		results := config.Search(cfg, args[0])
+		if searchType != "" {
+			var scoped []config.SearchResult
+			for _, r := range results {
+				if r.Type == searchType {
+					scoped = append(scoped, r)
+				}
+			}
+			results = scoped
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("No matches for %q\n", args[0])
+			return
+		}
+
+		// State is optional; without it everything shows as not installed
+		st, err := state.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load state: %v\n", err)
+		}
+
+		sections := []struct {
+			resultType string
+			title      string
+		}{
+			{"config", "Configs"},
+			{"dependency", "Dependencies"},
+			{"external", "External Dependencies"},
+			{"machine", "Machine Configurations"},
+		}
+
+		for _, section := range sections {
+			first := true
+			for _, r := range results {
+				if r.Type != section.resultType {
+					continue
+				}
+				if first {
+					ui.Section(section.title)
+					first = false
+				}
+				printSearchResult(r, st)
+			}
+		}
+	},
+}
+
+func printSearchResult(r config.SearchResult, st *state.State) {
+	label := r.Name
+	if r.Group != "" {
+		label = fmt.Sprintf("%s [%s]", label, r.Group)
+	}
+	if r.Description != "" {
+		label = fmt.Sprintf("%s - %s", label, r.Description)
+	}
+
+	if searchResultInstalled(r, st) {
+		ui.Success("%s (installed)", label)
+	} else {
+		fmt.Printf("  • %s (not installed)\n", label)
+	}
+}
+
+func searchResultInstalled(r config.SearchResult, st *state.State) bool {
+	switch r.Type {
+	case "config":
+		return st != nil && st.HasConfig(r.Name)
+	case "dependency":
+		_, err := exec.LookPath(r.Binary)
+		return err == nil
+	case "external":
+		return st != nil && st.ExternalDeps[r.ID].Installed
+	case "machine":
+		if st == nil {
+			return false
+		}
+		_, ok := st.MachineConfig[r.ID]
+		return ok
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().String("type", "", "Limit search to one type: config, dependency, external, or machine")
+}
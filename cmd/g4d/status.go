@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/setup"
+	"github.com/nvandessel/go4dot/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [config-path]",
+	Short: "Show dotfiles repository sync status",
+	Long: `Show whether the dotfiles repository is ahead of or behind its
+upstream without pulling any changes.
+
+An incremental 'git fetch' is run first so the counts are current; use
+--no-fetch to skip it (e.g. when offline).`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var configPath string
+		var err error
+
+		if len(args) > 0 {
+			_, err = config.LoadFromPath(args[0])
+			configPath = args[0]
+		} else {
+			_, configPath, err = run.DiscoverConfig()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		dotfilesPath := filepath.Dir(configPath)
+
+		noFetch, _ := cmd.Flags().GetBool("no-fetch")
+
+		status, err := setup.GitRepoStatus(cmd.Context(), dotfilesPath, !noFetch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Repository: %s\n", dotfilesPath)
+		fmt.Printf("Branch:     %s\n", status.Branch)
+
+		if status.FetchFailed {
+			ui.Warning("Could not fetch from remote (offline?); counts may be stale")
+		}
+
+		if status.Upstream == "" {
+			fmt.Println("No upstream configured for this branch.")
+		} else {
+			fmt.Printf("Upstream:   %s\n", status.Upstream)
+			switch {
+			case status.Ahead == 0 && status.Behind == 0:
+				ui.Success("Up to date with %s", status.Upstream)
+			case status.Behind > 0 && status.Ahead == 0:
+				ui.Warning("%d commit(s) behind %s — run 'g4d update' to catch up", status.Behind, status.Upstream)
+			case status.Ahead > 0 && status.Behind == 0:
+				ui.Warning("%d commit(s) ahead of %s — push your changes", status.Ahead, status.Upstream)
+			default:
+				ui.Warning("Diverged: %d ahead, %d behind %s", status.Ahead, status.Behind, status.Upstream)
+			}
+		}
+
+		if status.Dirty {
+			ui.Warning("Uncommitted local changes in the dotfiles repo")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().Bool("no-fetch", false, "Skip the incremental git fetch")
+}
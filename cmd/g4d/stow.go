@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/stow"
+	"github.com/nvandessel/go4dot/internal/verbose"
 	"github.com/spf13/cobra"
 )
 
@@ -17,23 +19,23 @@ var stowCmd = &cobra.Command{
 }
 
 var stowAddCmd = &cobra.Command{
-	Use:   "add <config-name> [config-path]",
-	Short: "Stow a specific config",
-	Long:  "Create symlinks for a specific dotfile configuration",
-	Args:  cobra.RangeArgs(1, 2),
+	Use:   "add <config-name>... [config-path]",
+	Short: "Stow one or more configs",
+	Long:  "Create symlinks for one or more dotfile configurations",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		configName := args[0]
+		names, configPathArg := splitStowArgs(args)
 
 		// Load config
 		var cfg *config.Config
 		var configPath string
 		var err error
 
-		if len(args) > 1 {
-			cfg, err = config.LoadFromPath(args[1])
-			configPath = args[1]
+		if configPathArg != "" {
+			cfg, err = config.LoadFromPath(configPathArg)
+			configPath = configPathArg
 		} else {
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -41,18 +43,28 @@ var stowAddCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Find the config item
-		cfgItem := cfg.GetConfigByName(configName)
-		if cfgItem == nil {
-			fmt.Fprintf(os.Stderr, "Error: config '%s' not found\n", configName)
+		// Resolve every name before touching anything so a typo
+		// doesn't leave a half-applied batch
+		items, err := resolveConfigNames(cfg, names)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Get dotfiles directory
 		dotfilesPath := filepath.Dir(configPath)
 
-		// Stow it
+		excludes, _ := cmd.Flags().GetStringArray("exclude")
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+		force, _ := cmd.Flags().GetBool("force")
+
+		// Stow them
+		warnIfDotfilesUnsupported(cfg)
 		opts := stow.StowOptions{
+			Exclude:      excludes,
+			Force:        force,
+			FailFast:     failFast,
+			DotfilesMode: cfg.Stow.Dotfiles,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -62,31 +74,95 @@ var stowAddCmd = &cobra.Command{
 			},
 		}
 
-		err = stow.Stow(dotfilesPath, cfgItem.Path, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		result := stow.StowConfigs(cmd.Context(), dotfilesPath, items, opts)
+		printStowResult(result, "Stowed")
 	},
 }
 
+// splitStowArgs separates config names from the deprecated trailing
+// config-path positional. A trailing argument is only treated as a
+// config path when it looks like one (YAML extension or a path
+// separator), so bare config names are never misread; use the global
+// --config flag for anything ambiguous.
+func splitStowArgs(args []string) (names []string, configPathArg string) {
+	if configFlag != "" || len(args) < 2 {
+		return args, ""
+	}
+	last := args[len(args)-1]
+	if config.IsConfigPath(last) || strings.ContainsRune(last, os.PathSeparator) {
+		deprecatedConfigArg(last)
+		return args[:len(args)-1], last
+	}
+	return args, ""
+}
+
+// resolveConfigNames looks up every requested config, failing on the
+// first unknown name so batch operations validate up front
+func resolveConfigNames(cfg *config.Config, names []string) ([]config.ConfigItem, error) {
+	var items []config.ConfigItem
+	for _, name := range names {
+		item := cfg.GetConfigByName(name)
+		if item == nil {
+			return nil, fmt.Errorf("config '%s' not found", name)
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// printStowResult prints the combined summary for a batch stow
+// operation, exiting non-zero when any config failed
+func printStowResult(result *stow.StowResult, verb string) {
+	fmt.Println()
+	if len(result.Success) > 0 {
+		if count := result.LinkCount(); count > 0 {
+			fmt.Printf("%s: %d configs (%d symlinks)\n", verb, len(result.Success), count)
+		} else {
+			fmt.Printf("%s: %d configs\n", verb, len(result.Success))
+		}
+		if verbose.Enabled() {
+			for _, name := range result.Success {
+				for _, link := range result.Links[name] {
+					fmt.Printf("  %s\n", link)
+				}
+			}
+		}
+	}
+	if len(result.AlreadyStowed) > 0 {
+		fmt.Printf("Already stowed: %d configs\n", len(result.AlreadyStowed))
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Skipped: %d configs\n", len(result.Skipped))
+	}
+	if len(result.Failed) > 0 {
+		fmt.Printf("Failed: %d configs\n", len(result.Failed))
+		for _, fail := range result.Failed {
+			fmt.Printf("  - %s: %v\n", fail.ConfigName, fail.Error)
+			if fail.Hint != "" {
+				fmt.Printf("    Hint: %s\n", fail.Hint)
+			}
+		}
+		os.Exit(1)
+	}
+}
+
 var stowRemoveCmd = &cobra.Command{
-	Use:   "remove <config-name> [config-path]",
-	Short: "Unstow a specific config",
-	Long:  "Remove symlinks for a specific dotfile configuration",
-	Args:  cobra.RangeArgs(1, 2),
+	Use:   "remove <config-name>... [config-path]",
+	Short: "Unstow one or more configs",
+	Long:  "Remove symlinks for one or more dotfile configurations",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		configName := args[0]
+		names, configPathArg := splitStowArgs(args)
 
 		var cfg *config.Config
 		var configPath string
 		var err error
 
-		if len(args) > 1 {
-			cfg, err = config.LoadFromPath(args[1])
-			configPath = args[1]
+		if configPathArg != "" {
+			cfg, err = config.LoadFromPath(configPathArg)
+			configPath = configPathArg
 		} else {
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -94,15 +170,20 @@ var stowRemoveCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		cfgItem := cfg.GetConfigByName(configName)
-		if cfgItem == nil {
-			fmt.Fprintf(os.Stderr, "Error: config '%s' not found\n", configName)
+		items, err := resolveConfigNames(cfg, names)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		dotfilesPath := filepath.Dir(configPath)
 
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+
+		warnIfDotfilesUnsupported(cfg)
 		opts := stow.StowOptions{
+			FailFast:     failFast,
+			DotfilesMode: cfg.Stow.Dotfiles,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -112,11 +193,8 @@ var stowRemoveCmd = &cobra.Command{
 			},
 		}
 
-		err = stow.Unstow(dotfilesPath, cfgItem.Path, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		result := stow.UnstowConfigs(cmd.Context(), dotfilesPath, items, opts)
+		printStowResult(result, "Unstowed")
 	},
 }
 
@@ -134,7 +212,7 @@ var stowRefreshCmd = &cobra.Command{
 			cfg, err = config.LoadFromPath(args[0])
 			configPath = args[0]
 		} else {
-			cfg, configPath, err = config.LoadFromDiscovery()
+			cfg, configPath, err = run.DiscoverConfig()
 		}
 
 		if err != nil {
@@ -144,8 +222,15 @@ var stowRefreshCmd = &cobra.Command{
 
 		dotfilesPath := filepath.Dir(configPath)
 
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+		force, _ := cmd.Flags().GetBool("force")
+
 		// Restow all configs
+		warnIfDotfilesUnsupported(cfg)
 		opts := stow.StowOptions{
+			Force:        force,
+			FailFast:     failFast,
+			DotfilesMode: cfg.Stow.Dotfiles,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("[%d/%d] %s\n", current, total, msg)
@@ -156,31 +241,58 @@ var stowRefreshCmd = &cobra.Command{
 		}
 
 		allConfigs := cfg.GetAllConfigs()
-		fmt.Printf("Refreshing %d configs...\n\n", len(allConfigs))
 
-		result := stow.RestowConfigs(dotfilesPath, allConfigs, opts)
-
-		// Show results
-		fmt.Println()
-		if len(result.Success) > 0 {
-			fmt.Printf("Refreshed: %d configs\n", len(result.Success))
-		}
-		if len(result.Skipped) > 0 {
-			fmt.Printf("Skipped: %d configs\n", len(result.Skipped))
+		// Archived configs are a soft-delete: only refresh them when
+		// explicitly requested
+		if includeArchived, _ := cmd.Flags().GetBool("include-archived"); includeArchived {
+			allConfigs = append(allConfigs, cfg.Archived...)
 		}
-		if len(result.Failed) > 0 {
-			fmt.Printf("Failed: %d configs\n", len(result.Failed))
-			for _, fail := range result.Failed {
-				fmt.Printf("  - %s: %v\n", fail.ConfigName, fail.Error)
+
+		// Narrow to an explicit selection when one was given
+		if configNames, _ := cmd.Flags().GetStringSlice("configs"); len(configNames) > 0 {
+			var selected []config.ConfigItem
+			for _, name := range configNames {
+				item := cfg.GetConfigByName(name)
+				if item == nil {
+					fmt.Fprintf(os.Stderr, "Error: config '%s' not found in %s\n", name, config.ConfigFileName)
+					os.Exit(1)
+				}
+				selected = append(selected, *item)
 			}
-			os.Exit(1)
+			allConfigs = selected
 		}
+
+		fmt.Printf("Refreshing %d configs...\n\n", len(allConfigs))
+
+		result := stow.RestowConfigs(cmd.Context(), dotfilesPath, allConfigs, opts)
+		printStowResult(result, "Refreshed")
 	},
 }
 
+// warnIfDotfilesUnsupported prints a warning when the config enables
+// dotfiles mode but the installed stow predates --dotfiles
+func warnIfDotfilesUnsupported(cfg *config.Config) {
+	if !cfg.Stow.Dotfiles {
+		return
+	}
+	if err := stow.CheckDotfilesSupport(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(stowCmd)
 	stowCmd.AddCommand(stowAddCmd)
+
+	stowAddCmd.Flags().StringArray("exclude", nil, "Glob of files to skip for this run (repeatable)")
+	stowAddCmd.Flags().Bool("fail-fast", false, "Stop at the first failure instead of continuing")
+	stowAddCmd.Flags().Bool("force", false, "Restow even when links are already correct (adopts conflicting files)")
 	stowCmd.AddCommand(stowRemoveCmd)
+	stowRemoveCmd.Flags().Bool("fail-fast", false, "Stop at the first failure instead of continuing")
 	stowCmd.AddCommand(stowRefreshCmd)
+
+	stowRefreshCmd.Flags().StringSlice("configs", nil, "Only restow these configs (comma-separated)")
+	stowRefreshCmd.Flags().Bool("fail-fast", false, "Stop at the first failure instead of continuing")
+	stowRefreshCmd.Flags().Bool("force", false, "Restow even when links are already correct (adopts conflicting files)")
+	stowRefreshCmd.Flags().Bool("include-archived", false, "Also restow archived (soft-deleted) configs")
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -37,7 +38,7 @@ func init() {
 
 func runSync(cmd *cobra.Command, args []string) {
 	// Load config
-	cfg, configPath, err := config.LoadFromDiscovery()
+	cfg, configPath, err := run.DiscoverConfig()
 	if err != nil {
 		ui.Error("Failed to load config: %v", err)
 		os.Exit(1)
@@ -53,15 +54,15 @@ func runSync(cmd *cobra.Command, args []string) {
 
 	// If a specific config is specified, sync just that one
 	if len(args) > 0 {
-		syncSingleConfig(args[0], cfg, dotfilesPath, st)
+		syncSingleConfig(cmd.Context(), args[0], cfg, dotfilesPath, st)
 		return
 	}
 
 	// Sync all configs
-	syncAllConfigs(cfg, dotfilesPath, st)
+	syncAllConfigs(cmd.Context(), cfg, dotfilesPath, st)
 }
 
-func syncSingleConfig(configName string, cfg *config.Config, dotfilesPath string, st *state.State) {
+func syncSingleConfig(ctx context.Context, configName string, cfg *config.Config, dotfilesPath string, st *state.State) {
 	// Find the config
 	var configItem *config.ConfigItem
 	for _, c := range cfg.GetAllConfigs() {
@@ -122,7 +123,8 @@ func syncSingleConfig(configName string, cfg *config.Config, dotfilesPath string
 	}
 
 	// Do the sync
-	err = stow.SyncSingle(dotfilesPath, configName, cfg, st, stow.StowOptions{
+	err = stow.SyncSingle(ctx, dotfilesPath, configName, cfg, st, stow.StowOptions{
+		DotfilesMode: cfg.Stow.Dotfiles,
 		ProgressFunc: func(current, total int, msg string) {
 			if total > 0 && current > 0 {
 				fmt.Printf("  [%d/%d] %s\n", current, total, msg)
@@ -140,7 +142,7 @@ func syncSingleConfig(configName string, cfg *config.Config, dotfilesPath string
 	ui.Success("Synced %s", configName)
 }
 
-func syncAllConfigs(cfg *config.Config, dotfilesPath string, st *state.State) {
+func syncAllConfigs(ctx context.Context, cfg *config.Config, dotfilesPath string, st *state.State) {
 	// Check what will be synced
 	summary, err := stow.FullDriftCheck(cfg, dotfilesPath)
 	if err != nil {
@@ -186,7 +188,8 @@ func syncAllConfigs(cfg *config.Config, dotfilesPath string, st *state.State) {
 	}
 
 	// Do the sync
-	result, err := stow.SyncAll(dotfilesPath, cfg, st, ui.IsInteractive(), stow.StowOptions{
+	result, err := stow.SyncAll(ctx, dotfilesPath, cfg, st, ui.IsInteractive(), stow.StowOptions{
+		DotfilesMode: cfg.Stow.Dotfiles,
 		ProgressFunc: func(current, total int, msg string) {
 			if total > 0 && current > 0 {
 				fmt.Printf("  [%d/%d] %s\n", current, total, msg)
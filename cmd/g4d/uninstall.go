@@ -53,7 +53,7 @@ Note: This does NOT delete your dotfiles repository, only the symlinks.`,
 				os.Exit(1)
 			}
 		} else {
-			cfg, dotfilesPath, err = config.LoadFromDiscovery()
+			cfg, dotfilesPath, err = run.DiscoverConfig()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 				os.Exit(1)
@@ -62,11 +62,13 @@ Note: This does NOT delete your dotfiles repository, only the symlinks.`,
 		}
 
 		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		removeExternal, _ := cmd.Flags().GetBool("remove-external")
 		removeMachine, _ := cmd.Flags().GetBool("remove-machine")
 
-		// Confirm unless --force
-		if !force {
+		// Confirm unless --force; a dry run touches nothing so no
+		// confirmation is needed either
+		if !force && !dryRun {
 			fmt.Println("This will remove all dotfile symlinks from your home directory.")
 			if removeExternal {
 				fmt.Println("It will also remove external dependencies (plugins, themes, etc.)")
@@ -87,10 +89,15 @@ Note: This does NOT delete your dotfiles repository, only the symlinks.`,
 			fmt.Println()
 		}
 
-		fmt.Println("Uninstalling dotfiles...")
+		if dryRun {
+			fmt.Println("Dry run - showing what would be removed...")
+		} else {
+			fmt.Println("Uninstalling dotfiles...")
+		}
 		fmt.Printf("Directory: %s\n\n", dotfilesPath)
 
 		opts := setup.UninstallOptions{
+			DryRun:         dryRun,
 			RemoveExternal: removeExternal,
 			RemoveMachine:  removeMachine,
 			ProgressFunc: func(current, total int, msg string) {
@@ -102,11 +109,16 @@ Note: This does NOT delete your dotfiles repository, only the symlinks.`,
 			},
 		}
 
-		if err := setup.Uninstall(cfg, dotfilesPath, st, opts); err != nil {
+		if err := setup.Uninstall(cmd.Context(), cfg, dotfilesPath, st, opts); err != nil {
 			ui.Error("%v", err)
 			os.Exit(1)
 		}
 
+		if dryRun {
+			fmt.Println("\nDry run complete. Nothing was removed.")
+			return
+		}
+
 		fmt.Println("\nUninstall complete!")
 		fmt.Println("Your dotfiles repository is still intact at:", dotfilesPath)
 	},
@@ -116,6 +128,7 @@ func init() {
 	rootCmd.AddCommand(uninstallCmd)
 
 	uninstallCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	uninstallCmd.Flags().Bool("dry-run", false, "Show what would be removed without removing anything")
 	uninstallCmd.Flags().Bool("remove-external", false, "Also remove external dependencies")
 	uninstallCmd.Flags().Bool("remove-machine", false, "Also remove machine-specific config files")
 }
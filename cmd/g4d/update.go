@@ -49,7 +49,7 @@ This command:
 				os.Exit(1)
 			}
 		} else {
-			cfg, dotfilesPath, err = config.LoadFromDiscovery()
+			cfg, dotfilesPath, err = run.DiscoverConfig()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 				os.Exit(1)
@@ -60,12 +60,22 @@ This command:
 		updateExternal, _ := cmd.Flags().GetBool("external")
 		skipRestow, _ := cmd.Flags().GetBool("skip-restow")
 
+		configNames, _ := cmd.Flags().GetStringSlice("configs")
+		for _, name := range configNames {
+			if cfg.GetConfigByName(name) == nil {
+				fmt.Fprintf(os.Stderr, "Error: config '%s' not found in %s\n", name, config.ConfigFileName)
+				os.Exit(1)
+			}
+		}
+
 		fmt.Println("Updating dotfiles...")
 		fmt.Printf("Directory: %s\n\n", dotfilesPath)
 
 		opts := setup.UpdateOptions{
 			UpdateExternal: updateExternal,
 			SkipRestow:     skipRestow,
+			Offline:        offline,
+			Configs:        configNames,
 			ProgressFunc: func(current, total int, msg string) {
 				if total > 0 && current > 0 {
 					fmt.Printf("  [%d/%d] %s\n", current, total, msg)
@@ -75,7 +85,7 @@ This command:
 			},
 		}
 
-		if err := setup.Update(cfg, dotfilesPath, st, opts); err != nil {
+		if err := setup.Update(cmd.Context(), cfg, dotfilesPath, st, opts); err != nil {
 			ui.Error("%v", err)
 			os.Exit(1)
 		}
@@ -89,4 +99,5 @@ func init() {
 
 	updateCmd.Flags().Bool("external", false, "Also update external dependencies")
 	updateCmd.Flags().Bool("skip-restow", false, "Skip restowing configs after pull")
+	updateCmd.Flags().StringSlice("configs", nil, "Only restow these configs (comma-separated)")
 }
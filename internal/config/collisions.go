@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TargetCollision is a home-relative target path that more than one
+// config package would symlink, so stowing both conflicts or one
+// silently wins
+type TargetCollision struct {
+	Target  string   // Path relative to $HOME
+	Configs []string // Names of the configs that map a file there
+}
+
+// DetectTargetCollisions walks each config package under dotfilesPath
+// and reports target paths claimed by more than one config. Packages
+// whose directory doesn't exist are skipped; validation reports those
+// separately.
+func DetectTargetCollisions(cfg *Config, dotfilesPath string) ([]TargetCollision, error) {
+	owners := make(map[string][]string)
+
+	for _, item := range cfg.GetAllConfigs() {
+		pkgDir := filepath.Join(dotfilesPath, item.Path)
+		info, err := os.Stat(pkgDir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		item := item
+		err = filepath.Walk(pkgDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			rel, relErr := filepath.Rel(pkgDir, path)
+			if relErr != nil {
+				return nil
+			}
+			owners[rel] = append(owners[rel], item.Name)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan config '%s': %w", item.Name, err)
+		}
+	}
+
+	var collisions []TargetCollision
+	for target, names := range owners {
+		if len(names) > 1 {
+			collisions = append(collisions, TargetCollision{Target: target, Configs: names})
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].Target < collisions[j].Target
+	})
+
+	return collisions, nil
+}
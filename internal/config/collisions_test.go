@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCollisionFile(t *testing.T, root, pkg, rel string) {
+	t.Helper()
+	path := filepath.Join(root, pkg, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+}
+
+func TestDetectTargetCollisions(t *testing.T) {
+	root := t.TempDir()
+	writeCollisionFile(t, root, "zsh", ".zshrc")
+	writeCollisionFile(t, root, "zsh", ".config/zsh/aliases")
+	writeCollisionFile(t, root, "shell", ".zshrc")
+	writeCollisionFile(t, root, "git", ".gitconfig")
+
+	cfg := &Config{
+		Configs: ConfigGroups{
+			Core: []ConfigItem{
+				{Name: "zsh", Path: "zsh"},
+				{Name: "git", Path: "git"},
+			},
+			Optional: []ConfigItem{
+				{Name: "shell", Path: "shell"},
+				{Name: "missing", Path: "does-not-exist"},
+			},
+		},
+	}
+
+	collisions, err := DetectTargetCollisions(cfg, root)
+	if err != nil {
+		t.Fatalf("DetectTargetCollisions() failed: %v", err)
+	}
+
+	if len(collisions) != 1 {
+		t.Fatalf("collisions = %+v, want exactly one", collisions)
+	}
+	if collisions[0].Target != ".zshrc" {
+		t.Errorf("Target = %q, want .zshrc", collisions[0].Target)
+	}
+	if len(collisions[0].Configs) != 2 {
+		t.Errorf("Configs = %v, want zsh and shell", collisions[0].Configs)
+	}
+}
+
+func TestDetectTargetCollisionsNone(t *testing.T) {
+	root := t.TempDir()
+	writeCollisionFile(t, root, "zsh", ".zshrc")
+	writeCollisionFile(t, root, "git", ".gitconfig")
+
+	cfg := &Config{
+		Configs: ConfigGroups{
+			Core: []ConfigItem{
+				{Name: "zsh", Path: "zsh"},
+				{Name: "git", Path: "git"},
+			},
+		},
+	}
+
+	collisions, err := DetectTargetCollisions(cfg, root)
+	if err != nil {
+		t.Fatalf("DetectTargetCollisions() failed: %v", err)
+	}
+	if len(collisions) != 0 {
+		t.Errorf("collisions = %+v, want none", collisions)
+	}
+}
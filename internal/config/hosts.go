@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"sort"
+)
+
+// ApplyHostOverrides merges the overrides for the matching hostname over
+// the config. An exact hostname key wins; otherwise keys are tried as
+// glob patterns (path.Match semantics, so "work-*" covers a fleet) in
+// sorted order and the first match applies. Returns the matched key, or
+// "" when no entry matches.
+func ApplyHostOverrides(cfg *Config, hostname string) (string, error) {
+	if len(cfg.Hosts) == 0 || hostname == "" {
+		return "", nil
+	}
+
+	matched := ""
+	if _, ok := cfg.Hosts[hostname]; ok {
+		matched = hostname
+	} else {
+		keys := make([]string, 0, len(cfg.Hosts))
+		for key := range cfg.Hosts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			ok, err := path.Match(key, hostname)
+			if err != nil {
+				return "", fmt.Errorf("invalid host pattern '%s': %w", key, err)
+			}
+			if ok {
+				matched = key
+				break
+			}
+		}
+	}
+
+	if matched == "" {
+		return "", nil
+	}
+
+	applyHostOverride(cfg, cfg.Hosts[matched])
+	return matched, nil
+}
+
+// applyHostOverride mutates cfg in place with a single host's overrides
+func applyHostOverride(cfg *Config, override HostOverride) {
+	// Promote enabled optional configs to core so they install by default
+	for _, name := range override.EnableConfigs {
+		for i, item := range cfg.Configs.Optional {
+			if item.Name == name {
+				cfg.Configs.Core = append(cfg.Configs.Core, item)
+				cfg.Configs.Optional = append(cfg.Configs.Optional[:i], cfg.Configs.Optional[i+1:]...)
+				break
+			}
+		}
+	}
+
+	// Disabled configs are dropped entirely
+	for _, name := range override.DisableConfigs {
+		cfg.Configs.Core = removeConfigByName(cfg.Configs.Core, name)
+		cfg.Configs.Optional = removeConfigByName(cfg.Configs.Optional, name)
+	}
+
+	// Override machine prompt defaults by field ID
+	if len(override.PromptDefaults) > 0 {
+		for i := range cfg.MachineConfig {
+			for j := range cfg.MachineConfig[i].Prompts {
+				field := &cfg.MachineConfig[i].Prompts[j]
+				if value, ok := override.PromptDefaults[field.ID]; ok {
+					field.Default = value
+				}
+			}
+		}
+	}
+
+	// Extra external deps: same ID replaces, new IDs append
+	for _, ext := range override.External {
+		replaced := false
+		for i := range cfg.External {
+			if cfg.External[i].ID == ext.ID {
+				cfg.External[i] = ext
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cfg.External = append(cfg.External, ext)
+		}
+	}
+}
+
+// removeConfigByName filters a config list down to entries not matching name
+func removeConfigByName(items []ConfigItem, name string) []ConfigItem {
+	result := items[:0]
+	for _, item := range items {
+		if item.Name != name {
+			result = append(result, item)
+		}
+	}
+	return result
+}
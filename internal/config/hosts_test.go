@@ -0,0 +1,159 @@
+package config
+
+import "testing"
+
+func hostTestConfig() *Config {
+	return &Config{
+		Configs: ConfigGroups{
+			Core: []ConfigItem{
+				{Name: "git", Path: "git"},
+				{Name: "zsh", Path: "zsh"},
+			},
+			Optional: []ConfigItem{
+				{Name: "nvim", Path: "nvim"},
+			},
+		},
+		External: []ExternalDep{
+			{ID: "pure", Name: "Pure", URL: "https://github.com/example/pure.git"},
+		},
+		MachineConfig: []MachinePrompt{
+			{
+				ID: "gitconfig",
+				Prompts: []PromptField{
+					{ID: "email", Default: "base@example.com"},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyHostOverridesMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    map[string]HostOverride
+		hostname string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Exact match",
+			hosts:    map[string]HostOverride{"laptop": {}, "work-*": {}},
+			hostname: "laptop",
+			want:     "laptop",
+		},
+		{
+			name:     "Glob match",
+			hosts:    map[string]HostOverride{"work-*": {}},
+			hostname: "work-desktop",
+			want:     "work-*",
+		},
+		{
+			name:     "Exact beats glob",
+			hosts:    map[string]HostOverride{"work-laptop": {}, "work-*": {}},
+			hostname: "work-laptop",
+			want:     "work-laptop",
+		},
+		{
+			name:     "No match",
+			hosts:    map[string]HostOverride{"work-*": {}},
+			hostname: "laptop",
+			want:     "",
+		},
+		{
+			name:     "Bad pattern",
+			hosts:    map[string]HostOverride{"work-[": {}},
+			hostname: "laptop",
+			wantErr:  true,
+		},
+		{
+			name:     "Empty hostname",
+			hosts:    map[string]HostOverride{"*": {}},
+			hostname: "",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := hostTestConfig()
+			cfg.Hosts = tt.hosts
+
+			got, err := ApplyHostOverrides(cfg, tt.hostname)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyHostOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyHostOverrides() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyHostOverridesMerge(t *testing.T) {
+	cfg := hostTestConfig()
+	cfg.Hosts = map[string]HostOverride{
+		"work-*": {
+			PromptDefaults: map[string]string{"email": "work@example.com"},
+			EnableConfigs:  []string{"nvim"},
+			DisableConfigs: []string{"zsh"},
+			External: []ExternalDep{
+				{ID: "pure", Name: "Pure Fork", URL: "https://github.com/work/pure.git"},
+				{ID: "work-theme", Name: "Work Theme", URL: "https://github.com/work/theme.git"},
+			},
+		},
+	}
+
+	matched, err := ApplyHostOverrides(cfg, "work-desktop")
+	if err != nil {
+		t.Fatalf("ApplyHostOverrides() failed: %v", err)
+	}
+	if matched != "work-*" {
+		t.Fatalf("matched = %q, want work-*", matched)
+	}
+
+	// nvim promoted to core, zsh dropped
+	coreNames := make(map[string]bool)
+	for _, item := range cfg.Configs.Core {
+		coreNames[item.Name] = true
+	}
+	if !coreNames["nvim"] {
+		t.Error("nvim should be promoted to core")
+	}
+	if coreNames["zsh"] {
+		t.Error("zsh should be disabled")
+	}
+	if len(cfg.Configs.Optional) != 0 {
+		t.Errorf("Optional = %+v, want empty", cfg.Configs.Optional)
+	}
+
+	// Prompt default overridden
+	if got := cfg.MachineConfig[0].Prompts[0].Default; got != "work@example.com" {
+		t.Errorf("email default = %q, want work@example.com", got)
+	}
+
+	// External: pure replaced, work-theme appended
+	if len(cfg.External) != 2 {
+		t.Fatalf("len(External) = %d, want 2", len(cfg.External))
+	}
+	if cfg.External[0].URL != "https://github.com/work/pure.git" {
+		t.Errorf("pure URL = %q, want the fork", cfg.External[0].URL)
+	}
+	if cfg.External[1].ID != "work-theme" {
+		t.Errorf("External[1].ID = %q, want work-theme", cfg.External[1].ID)
+	}
+}
+
+func TestApplyHostOverridesNoHosts(t *testing.T) {
+	cfg := hostTestConfig()
+
+	matched, err := ApplyHostOverrides(cfg, "laptop")
+	if err != nil {
+		t.Fatalf("ApplyHostOverrides() failed: %v", err)
+	}
+	if matched != "" {
+		t.Errorf("matched = %q, want empty for config without hosts", matched)
+	}
+	if len(cfg.Configs.Core) != 2 || len(cfg.Configs.Optional) != 1 {
+		t.Error("config without hosts section should be untouched")
+	}
+}
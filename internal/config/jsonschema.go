@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONSchema returns a JSON Schema (draft-07) describing the .go4dot.yaml
+// format. The schema is generated from the Config struct's yaml tags, so
+// it stays in sync with the code; editors can use it via a
+// "# yaml-language-server: $schema=..." comment.
+func JSONSchema() ([]byte, error) {
+	schema := schemaForType(reflect.TypeOf(Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "go4dot configuration"
+	schema["description"] = "Schema for .go4dot.yaml dotfiles configuration files"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate schema: %w", err)
+	}
+	return data, nil
+}
+
+// schemaForType maps a Go type to its JSON Schema fragment. Struct fields
+// are keyed by their yaml tags; fields without a tag are skipped.
+func schemaForType(t reflect.Type) map[string]interface{} {
+	// DependencyItem has a custom unmarshaler that accepts a bare string
+	// as shorthand for {name: X, binary: X}
+	if t == reflect.TypeOf(DependencyItem{}) {
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				structSchema(t),
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaForType(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaForType(t.Elem()),
+		}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		// No other kinds appear in the config structs
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema builds the object schema for a struct from its yaml tags
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		name := strings.Split(tag, ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = schemaForType(field.Type)
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
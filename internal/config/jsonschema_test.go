@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONSchemaTopLevelFields(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() failed: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("schema has no properties object")
+	}
+
+	for _, field := range []string{
+		"schema_version", "metadata", "dependencies", "configs",
+		"external", "machine_config", "archived", "post_install",
+		"git", "hosts",
+	} {
+		if _, ok := properties[field]; !ok {
+			t.Errorf("schema is missing top-level property %q", field)
+		}
+	}
+}
+
+// TestJSONSchemaExamples validates the shipped example configs against the
+// generated schema, so a new struct field can't land without the schema
+// (and examples) staying consistent.
+func TestJSONSchemaExamples(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() failed: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	examples := []string{
+		filepath.Join("..", "..", "examples", "minimal", ".go4dot.yaml"),
+		filepath.Join("..", "..", "examples", "advanced", ".go4dot.yaml"),
+	}
+
+	for _, path := range examples {
+		t.Run(filepath.Base(filepath.Dir(path)), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read example: %v", err)
+			}
+
+			var doc interface{}
+			if err := yaml.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("failed to parse example: %v", err)
+			}
+
+			for _, problem := range validateAgainstSchema(schema, doc, "$") {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// validateAgainstSchema is a minimal structural validator covering the
+// subset of JSON Schema that JSONSchema emits: type, properties,
+// additionalProperties, items, and oneOf.
+func validateAgainstSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	if value == nil {
+		return nil
+	}
+
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		for _, alt := range alternatives {
+			altSchema, ok := alt.(map[string]interface{})
+			if ok && len(validateAgainstSchema(altSchema, value, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: matches none of the allowed forms", path)}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %T", path, value)}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		var problems []string
+		for key, item := range obj {
+			keyPath := path + "." + key
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				problems = append(problems, validateAgainstSchema(propSchema, item, keyPath)...)
+				continue
+			}
+			if extraSchema, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				problems = append(problems, validateAgainstSchema(extraSchema, item, keyPath)...)
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s: unknown field", keyPath))
+		}
+		return problems
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %T", path, value)}
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		var problems []string
+		for i, item := range arr {
+			problems = append(problems, validateAgainstSchema(itemSchema, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected a string, got %T", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %T", path, value)}
+		}
+	case "integer":
+		if _, ok := value.(int); !ok {
+			return []string{fmt.Sprintf("%s: expected an integer, got %T", path, value)}
+		}
+	}
+
+	return nil
+}
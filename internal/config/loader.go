@@ -12,6 +12,27 @@ const (
 	ConfigFileName = ".go4dot.yaml"
 )
 
+// ConfigFileNames are the accepted config filenames, tried in order
+// wherever a directory is searched for a config. The gopherdot names
+// come first; the go4dot ones remain accepted during the rename.
+var ConfigFileNames = []string{
+	".gopherdot.yaml",
+	".gopherdot.yml",
+	".go4dot.yaml",
+	".go4dot.yml",
+	"gopherdot.yaml",
+}
+
+// IsConfigPath reports whether a CLI argument looks like a config file
+// path rather than a name or ID, based on the accepted YAML extensions
+func IsConfigPath(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
 // Load reads and parses a .go4dot.yaml file
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -24,13 +45,32 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	// Merge per-machine overrides for this hostname, if any, keeping the
+	// pristine parsed struct aside so Save writes the shared file back
+	// without this host's view baked in
+	hostname, _ := os.Hostname()
+	if len(cfg.Hosts) > 0 {
+		var pristine Config
+		if err := yaml.Unmarshal(data, &pristine); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+
+		matched, err := ApplyHostOverrides(&cfg, hostname)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply host overrides: %w", err)
+		}
+		if matched != "" {
+			cfg.pristine = &pristine
+		}
+	}
+
 	return &cfg, nil
 }
 
-// FindConfig searches for .go4dot.yaml in common locations
-func FindConfig() (string, error) {
-	// Search locations in order of priority
-	searchPaths := []string{
+// ConfigSearchPaths returns the directories FindConfig scans, in order
+// of priority
+func ConfigSearchPaths() []string {
+	return []string{
 		// Current directory
 		".",
 		// Home dotfiles directory
@@ -38,20 +78,52 @@ func FindConfig() (string, error) {
 		// Hidden dotfiles directory
 		filepath.Join(os.Getenv("HOME"), ".dotfiles"),
 	}
+}
+
+// FindConfig searches for a config file, first walking up from the
+// current directory (like git does with .git, so the tool works from
+// subdirectories of the dotfiles repo) and then in common locations
+func FindConfig() (string, error) {
+	if cwd, err := os.Getwd(); err == nil {
+		if found := findConfigUpward(cwd); found != "" {
+			return found, nil
+		}
+	}
 
-	for _, basePath := range searchPaths {
-		configPath := filepath.Join(basePath, ConfigFileName)
-		if _, err := os.Stat(configPath); err == nil {
-			// Found it!
-			absPath, err := filepath.Abs(configPath)
-			if err != nil {
-				return configPath, nil
+	for _, basePath := range ConfigSearchPaths() {
+		for _, name := range ConfigFileNames {
+			configPath := filepath.Join(basePath, name)
+			if _, err := os.Stat(configPath); err == nil {
+				// Found it!
+				absPath, err := filepath.Abs(configPath)
+				if err != nil {
+					return configPath, nil
+				}
+				return absPath, nil
 			}
-			return absPath, nil
 		}
 	}
 
-	return "", fmt.Errorf("could not find %s in any standard location", ConfigFileName)
+	return "", fmt.Errorf("could not find %s in this directory, its parents, or any standard location", ConfigFileName)
+}
+
+// findConfigUpward checks dir and each of its parents for a config file,
+// stopping at the filesystem root. Returns "" when none is found.
+func findConfigUpward(dir string) string {
+	for {
+		for _, name := range ConfigFileNames {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
 }
 
 // LoadFromDiscovery finds and loads the config file
@@ -71,17 +143,34 @@ func LoadFromDiscovery() (*Config, string, error) {
 
 // LoadFromPath loads config from a specific path
 func LoadFromPath(path string) (*Config, error) {
-	// If path is a directory, append the config filename
+	configPath, err := ResolveConfigFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(configPath)
+}
+
+// ResolveConfigFilePath resolves a user-provided path - either a config
+// file or a directory containing one - to the absolute config file path
+// that LoadFromPath would read
+func ResolveConfigFilePath(path string) (string, error) {
 	stat, err := os.Stat(path)
 	if err != nil {
-		return nil, fmt.Errorf("path does not exist: %w", err)
+		return "", fmt.Errorf("path does not exist: %w", err)
 	}
 
-	if stat.IsDir() {
-		path = filepath.Join(path, ConfigFileName)
+	if !stat.IsDir() {
+		return filepath.Abs(path)
+	}
+
+	for _, name := range ConfigFileNames {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return filepath.Abs(candidate)
+		}
 	}
 
-	return Load(path)
+	return "", fmt.Errorf("no %s found in %s", ConfigFileName, path)
 }
 
 // ResolveRepoRoot determines the repository root from a path
@@ -160,3 +160,125 @@ func TestDependencyItemUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestIsConfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"yaml extension", "dotfiles/.go4dot.yaml", true},
+		{"yml extension", ".go4dot.yml", true},
+		{"plain name", "nvim", false},
+		{"directory", "/home/user/dotfiles", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConfigPath(tt.path); got != tt.want {
+				t.Errorf("IsConfigPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromPathYmlExtension(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("schema_version: \"1.0\"\nmetadata:\n  name: test\n")
+	if err := os.WriteFile(filepath.Join(dir, ".go4dot.yml"), content, 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(dir)
+	if err != nil {
+		t.Fatalf("LoadFromPath() should find a .yml config: %v", err)
+	}
+	if cfg.Metadata.Name != "test" {
+		t.Errorf("Metadata.Name = %q, want test", cfg.Metadata.Name)
+	}
+}
+
+func TestResolveConfigFilePath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".go4dot.yaml")
+	if err := os.WriteFile(file, []byte("schema_version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Run("Directory resolves to the config inside it", func(t *testing.T) {
+		got, err := ResolveConfigFilePath(dir)
+		if err != nil {
+			t.Fatalf("ResolveConfigFilePath() failed: %v", err)
+		}
+		if got != file {
+			t.Errorf("ResolveConfigFilePath(%q) = %q, want %q", dir, got, file)
+		}
+	})
+
+	t.Run("File path passes through", func(t *testing.T) {
+		got, err := ResolveConfigFilePath(file)
+		if err != nil {
+			t.Fatalf("ResolveConfigFilePath() failed: %v", err)
+		}
+		if got != file {
+			t.Errorf("ResolveConfigFilePath(%q) = %q, want %q", file, got, file)
+		}
+	})
+
+	t.Run("Directory without a config fails", func(t *testing.T) {
+		if _, err := ResolveConfigFilePath(t.TempDir()); err == nil {
+			t.Error("ResolveConfigFilePath() should fail for a directory without a config")
+		}
+	})
+
+	t.Run("Missing path fails", func(t *testing.T) {
+		if _, err := ResolveConfigFilePath(filepath.Join(dir, "nope")); err == nil {
+			t.Error("ResolveConfigFilePath() should fail for a missing path")
+		}
+	})
+}
+
+func TestFindConfigUpward(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gopherdot.yaml"), []byte("schema_version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deep := filepath.Join(root, "configs", "nvim")
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Found from a subdirectory by walking up, like git with .git
+	found := findConfigUpward(deep)
+	if found != filepath.Join(root, ".gopherdot.yaml") {
+		t.Errorf("findConfigUpward(%q) = %q, want config in %q", deep, found, root)
+	}
+
+	// Nothing above an empty tree
+	if found := findConfigUpward(t.TempDir()); found != "" {
+		t.Errorf("findConfigUpward() = %q, want empty", found)
+	}
+}
+
+func TestConfigFileNameDiscoveryOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".go4dot.yaml", ".gopherdot.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("schema_version: \"1.0\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The new name wins over the legacy one when both are present
+	found := findConfigUpward(dir)
+	if filepath.Base(found) != ".gopherdot.yaml" {
+		t.Errorf("findConfigUpward() picked %q, want .gopherdot.yaml", found)
+	}
+
+	resolved, err := ResolveConfigFilePath(dir)
+	if err != nil {
+		t.Fatalf("ResolveConfigFilePath() failed: %v", err)
+	}
+	if filepath.Base(resolved) != ".gopherdot.yaml" {
+		t.Errorf("ResolveConfigFilePath() picked %q, want .gopherdot.yaml", resolved)
+	}
+}
@@ -8,8 +8,51 @@ type Config struct {
 	Configs       ConfigGroups    `yaml:"configs"`
 	External      []ExternalDep   `yaml:"external"`
 	MachineConfig []MachinePrompt `yaml:"machine_config"`
-	Archived      []ConfigItem    `yaml:"archived"`
-	PostInstall   string          `yaml:"post_install"`
+	// SharedPrompts are collected once and injected into every machine
+	// config's template context; per-config prompt values take precedence
+	SharedPrompts []PromptField `yaml:"shared_prompts,omitempty"`
+	Archived      []ConfigItem  `yaml:"archived"`
+	PostInstall   string        `yaml:"post_install"`
+	Git           GitSettings   `yaml:"git,omitempty"`
+	Stow          StowSettings  `yaml:"stow,omitempty"`
+	// Hosts maps hostnames (glob patterns allowed, e.g. "work-*") to
+	// per-machine overrides merged over the base config at load time
+	Hosts map[string]HostOverride `yaml:"hosts,omitempty"`
+	// pristine holds the config exactly as parsed from disk, before host
+	// overrides were merged in. Save marshals it when set, so one host's
+	// merged view never rewrites the shared file; mutating methods apply
+	// their change to both views.
+	pristine *Config `yaml:"-"`
+}
+
+// GitSettings controls how external repo URLs are rewritten before git
+// runs, so one shared config works behind different network policies
+type GitSettings struct {
+	PreferProtocol string       `yaml:"prefer_protocol,omitempty"` // "ssh" or "https"
+	URLRewrites    []URLRewrite `yaml:"url_rewrites,omitempty"`
+}
+
+// URLRewrite replaces a URL prefix with another before cloning
+type URLRewrite struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// StowSettings controls how stow is invoked
+type StowSettings struct {
+	// Dotfiles passes --dotfiles to stow, translating "dot-foo" entries
+	// in config packages to ".foo" in the target so the repo can avoid
+	// leading-dot filenames. Requires GNU Stow 2.3+.
+	Dotfiles bool `yaml:"dotfiles,omitempty"`
+}
+
+// HostOverride holds per-machine tweaks applied when the local hostname
+// matches the owning key in Config.Hosts
+type HostOverride struct {
+	PromptDefaults map[string]string `yaml:"prompt_defaults,omitempty"` // Machine prompt field ID -> new default
+	EnableConfigs  []string          `yaml:"enable_configs,omitempty"`  // Optional configs promoted to core
+	DisableConfigs []string          `yaml:"disable_configs,omitempty"` // Configs removed for this host
+	External       []ExternalDep     `yaml:"external,omitempty"`        // Extra external deps for this host
 }
 
 // Metadata contains project information
@@ -19,6 +62,10 @@ type Metadata struct {
 	Repository  string `yaml:"repository"`
 	Description string `yaml:"description"`
 	Version     string `yaml:"version"`
+	// PreferredManagers orders package managers by preference for
+	// hybrid systems (e.g. Linuxbrew next to apt). The first one that
+	// is available on the machine wins.
+	PreferredManagers []string `yaml:"preferred_managers,omitempty"`
 }
 
 // Dependencies lists required system packages
@@ -26,16 +73,26 @@ type Dependencies struct {
 	Critical []DependencyItem `yaml:"critical"`
 	Core     []DependencyItem `yaml:"core"`
 	Optional []DependencyItem `yaml:"optional"`
+	// AssumeInstalled names deps to treat as installed without checking,
+	// for environments where detection can't see them (busybox applets,
+	// custom PATH setups)
+	AssumeInstalled []string `yaml:"assume_installed,omitempty"`
 }
 
 // DependencyItem represents a single dependency
 // Can be a simple string or a complex object with package mappings
 type DependencyItem struct {
 	Name       string            `yaml:"name"`
-	Binary     string            `yaml:"binary"`      // Binary name to check in PATH
-	Package    map[string]string `yaml:"package"`     // Package name per manager
-	Version    string            `yaml:"version"`     // Required version (e.g. "0.11+")
-	VersionCmd string            `yaml:"version_cmd"` // Command to check version (defaults to --version)
+	Binary     string            `yaml:"binary"`             // Binary name to check in PATH
+	Group      string            `yaml:"group"`              // Optional group label (e.g. "cli", "gui")
+	Category   string            `yaml:"category,omitempty"` // Display category for grouped output (e.g. "editors", "shell")
+	AnyOf      []string          `yaml:"any_of"`             // Alternative binaries/packages, any one satisfies
+	Requires   []string          `yaml:"requires,omitempty"` // Names of deps that must install before this one
+	Arch       []string          `yaml:"arch"`               // Limit to these architectures (empty = all)
+	Package    map[string]string `yaml:"package"`            // Package name per manager
+	Source     string            `yaml:"source,omitempty"`   // "aur" installs via an AUR helper (yay/paru) on Arch
+	Version    string            `yaml:"version"`            // Required version (e.g. "0.11+")
+	VersionCmd string            `yaml:"version_cmd"`        // Command to check version (defaults to --version)
 }
 
 // UnmarshalYAML allows DependencyItem to accept both string and object formats
@@ -65,19 +122,35 @@ type ConfigItem struct {
 	Path                  string   `yaml:"path"`
 	Description           string   `yaml:"description"`
 	Platforms             []string `yaml:"platforms"`
+	Arch                  []string `yaml:"arch"` // Limit to these architectures (empty = all)
 	DependsOn             []string `yaml:"depends_on"`
 	RequiresMachineConfig bool     `yaml:"requires_machine_config"`
+	// Condition gates the config on richer platform facts than Platforms
+	// allows, with the same semantics as external dep conditions
+	// (e.g. package_manager: brew, wsl: "true")
+	Condition map[string]string `yaml:"condition,omitempty"`
+	// Template renders files ending in .tmpl inside the package to the
+	// non-.tmpl name in the target (with the shared template context)
+	// instead of symlinking them, for configs that need a machine-specific
+	// value baked in without a full machine config
+	Template bool `yaml:"template,omitempty"`
 }
 
 // ExternalDep represents an external dependency to clone (plugins, themes, etc.)
 type ExternalDep struct {
-	Name          string            `yaml:"name"`
-	ID            string            `yaml:"id"`
-	URL           string            `yaml:"url"`
-	Destination   string            `yaml:"destination"`
-	Method        string            `yaml:"method"`         // "clone" or "copy"
-	MergeStrategy string            `yaml:"merge_strategy"` // "overwrite" (default) or "keep_existing"
-	Condition     map[string]string `yaml:"condition"`
+	Name              string            `yaml:"name"`
+	ID                string            `yaml:"id"`
+	URL               string            `yaml:"url"`
+	Branch            string            `yaml:"branch,omitempty"`              // Branch or tag to clone (default branch if empty)
+	Destination       string            `yaml:"destination"`                   // Absolute/~ path outside the repo, or a repo-relative path that gets stowed
+	Method            string            `yaml:"method"`                        // "clone" or "copy"
+	MergeStrategy     string            `yaml:"merge_strategy"`                // "overwrite" (default) or "keep_existing"
+	PostClone         []string          `yaml:"post_clone"`                    // Commands to run in the destination after clone/update
+	PostCloneOptional bool              `yaml:"post_clone_optional,omitempty"` // Warn instead of failing when a post_clone command fails
+	Condition         map[string]string `yaml:"condition"`
+	DependsOn         []string          `yaml:"depends_on,omitempty"` // IDs of external deps that must be installed first
+	UseSSH            bool              `yaml:"use_ssh"`              // Clone via SSH instead of HTTPS (for private repos)
+	SSHKey            string            `yaml:"ssh_key"`              // SSH identity file to use for this repo (implies use_ssh)
 }
 
 // MachinePrompt represents machine-specific configuration prompts
@@ -87,14 +160,35 @@ type MachinePrompt struct {
 	Destination string        `yaml:"destination"`
 	Prompts     []PromptField `yaml:"prompts"`
 	Template    string        `yaml:"template"`
+	// TemplateCommand pipes the template through an external command
+	// (e.g. "envsubst", "jinja2 -") instead of Go templating. Prompt
+	// values are exported as environment variables and the command's
+	// stdout becomes the rendered content.
+	TemplateCommand string `yaml:"template_command,omitempty"`
+	// Merge inserts the rendered content between sentinel markers in the
+	// destination instead of replacing the whole file, so hand-maintained
+	// content outside the markers survives re-renders
+	Merge bool `yaml:"merge,omitempty"`
+	// CommentPrefix sets the comment syntax used for the merge markers
+	// (default "#"; use "//" for C-style files)
+	CommentPrefix string            `yaml:"comment_prefix,omitempty"`
+	Condition     map[string]string `yaml:"condition,omitempty"` // Only applies when the condition matches (e.g. os: darwin)
 }
 
 // PromptField represents a single prompt for user input
 type PromptField struct {
 	ID       string   `yaml:"id"`
 	Prompt   string   `yaml:"prompt"`
-	Type     string   `yaml:"type"` // text, password, confirm, select
+	Type     string   `yaml:"type"` // text, multiline, number, password, confirm, select, computed
 	Required bool     `yaml:"required"`
 	Default  string   `yaml:"default"`
 	Options  []string `yaml:"options,omitempty"` // Options for select type
+	Source   string   `yaml:"source,omitempty"`  // System info source (computed) or secret source (password)
+	Min      *int     `yaml:"min,omitempty"`     // Lower bound for number type (nil = unbounded)
+	Max      *int     `yaml:"max,omitempty"`     // Upper bound for number type (nil = unbounded)
+	// Secret marks the value as sensitive regardless of type: input is
+	// hidden, previews mask it, it's never persisted to state, and the
+	// rendered file gets 0600 permissions. Password fields are always
+	// treated as secret.
+	Secret bool `yaml:"secret,omitempty"`
 }
@@ -0,0 +1,89 @@
+package config
+
+import "strings"
+
+// SearchResult is one item matched by Search
+type SearchResult struct {
+	Type        string // "config", "dependency", "external", "machine"
+	Name        string
+	ID          string // State key for external/machine results
+	Group       string // Config group or dependency tier the item belongs to
+	Description string
+	Binary      string // Binary to probe for dependency results
+}
+
+// Search case-insensitively matches query against config names and
+// descriptions, dependency names, external names and URLs, and machine
+// config descriptions. Results keep the config's declaration order.
+func Search(cfg *Config, query string) []SearchResult {
+	query = strings.ToLower(query)
+	matches := func(fields ...string) bool {
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), query) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var results []SearchResult
+
+	addConfigs := func(items []ConfigItem, group string) {
+		for _, c := range items {
+			if matches(c.Name, c.Description) {
+				results = append(results, SearchResult{
+					Type:        "config",
+					Name:        c.Name,
+					Group:       group,
+					Description: c.Description,
+				})
+			}
+		}
+	}
+	addConfigs(cfg.Configs.Core, "core")
+	addConfigs(cfg.Configs.Optional, "optional")
+
+	addDeps := func(items []DependencyItem, tier string) {
+		for _, d := range items {
+			if matches(d.Name, d.Binary) {
+				binary := d.Binary
+				if binary == "" {
+					binary = d.Name
+				}
+				results = append(results, SearchResult{
+					Type:   "dependency",
+					Name:   d.Name,
+					Group:  tier,
+					Binary: binary,
+				})
+			}
+		}
+	}
+	addDeps(cfg.Dependencies.Critical, "critical")
+	addDeps(cfg.Dependencies.Core, "core")
+	addDeps(cfg.Dependencies.Optional, "optional")
+
+	for _, e := range cfg.External {
+		if matches(e.Name, e.ID, e.URL) {
+			results = append(results, SearchResult{
+				Type:        "external",
+				Name:        e.Name,
+				ID:          e.ID,
+				Description: e.URL,
+			})
+		}
+	}
+
+	for _, mc := range cfg.MachineConfig {
+		if matches(mc.ID, mc.Description) {
+			results = append(results, SearchResult{
+				Type:        "machine",
+				Name:        mc.ID,
+				ID:          mc.ID,
+				Description: mc.Description,
+			})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,108 @@
+package config
+
+import "testing"
+
+func searchTestConfig() *Config {
+	return &Config{
+		Configs: ConfigGroups{
+			Core: []ConfigItem{
+				{Name: "tmux", Description: "Tmux configuration"},
+				{Name: "git", Description: "Git configuration"},
+			},
+			Optional: []ConfigItem{
+				{Name: "nvim", Description: "Neovim editor setup"},
+			},
+		},
+		Dependencies: Dependencies{
+			Critical: []DependencyItem{{Name: "stow"}},
+			Core:     []DependencyItem{{Name: "neovim", Binary: "nvim"}},
+		},
+		External: []ExternalDep{
+			{ID: "tpm", Name: "Tmux Plugin Manager", URL: "https://github.com/tmux-plugins/tpm"},
+		},
+		MachineConfig: []MachinePrompt{
+			{ID: "gitconfig", Description: "Git user configuration"},
+		},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cfg := searchTestConfig()
+
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]int // result type -> count
+	}{
+		{
+			name:  "Matches across types",
+			query: "tmux",
+			want:  map[string]int{"config": 1, "external": 1},
+		},
+		{
+			name:  "Case insensitive",
+			query: "TMUX",
+			want:  map[string]int{"config": 1, "external": 1},
+		},
+		{
+			name:  "Dependency by binary name",
+			query: "nvim",
+			want:  map[string]int{"config": 1, "dependency": 1},
+		},
+		{
+			name:  "Machine config by description",
+			query: "user config",
+			want:  map[string]int{"machine": 1},
+		},
+		{
+			name:  "External by URL",
+			query: "github.com/tmux-plugins",
+			want:  map[string]int{"external": 1},
+		},
+		{
+			name:  "No matches",
+			query: "nothing-here",
+			want:  map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]int)
+			for _, r := range Search(cfg, tt.query) {
+				got[r.Type]++
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) types = %v, want %v", tt.query, got, tt.want)
+			}
+			for typ, count := range tt.want {
+				if got[typ] != count {
+					t.Errorf("Search(%q) %s count = %d, want %d", tt.query, typ, got[typ], count)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchResultFields(t *testing.T) {
+	cfg := searchTestConfig()
+
+	var dep *SearchResult
+	for _, r := range Search(cfg, "neovim") {
+		if r.Type == "dependency" {
+			r := r
+			dep = &r
+		}
+	}
+	if dep == nil {
+		t.Fatal("Search(neovim) should match the neovim dependency")
+	}
+	if dep.Binary != "nvim" || dep.Group != "core" {
+		t.Errorf("dependency result = %+v, want binary nvim in core tier", dep)
+	}
+
+	results := Search(cfg, "plugin manager")
+	if len(results) != 1 || results[0].ID != "tpm" {
+		t.Fatalf("Search(plugin manager) = %+v, want the tpm external dep", results)
+	}
+}
@@ -46,8 +46,9 @@ func (c *Config) Validate() error {
 		})
 	}
 
-	// Validate configs
-	configNames := make(map[string]bool)
+	// Validate configs, remembering where each name first appeared so
+	// duplicate errors can point at both occurrences
+	configNames := make(map[string]string)
 
 	// Check core configs
 	for i, cfg := range c.Configs.Core {
@@ -65,13 +66,15 @@ func (c *Config) Validate() error {
 		}
 
 		// Check for duplicate names
-		if configNames[cfg.Name] {
+		field := fmt.Sprintf("configs.core[%d].name", i)
+		if first, seen := configNames[cfg.Name]; seen {
 			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("configs.core[%d].name", i),
-				Message: fmt.Sprintf("duplicate config name: %s", cfg.Name),
+				Field:   field,
+				Message: fmt.Sprintf("duplicate config name '%s' (first defined at %s)", cfg.Name, first),
 			})
+		} else {
+			configNames[cfg.Name] = field
 		}
-		configNames[cfg.Name] = true
 	}
 
 	// Check optional configs
@@ -90,17 +93,31 @@ func (c *Config) Validate() error {
 		}
 
 		// Check for duplicate names
-		if configNames[cfg.Name] {
+		field := fmt.Sprintf("configs.optional[%d].name", i)
+		if first, seen := configNames[cfg.Name]; seen {
 			errors = append(errors, ValidationError{
-				Field:   fmt.Sprintf("configs.optional[%d].name", i),
-				Message: fmt.Sprintf("duplicate config name: %s", cfg.Name),
+				Field:   field,
+				Message: fmt.Sprintf("duplicate config name '%s' (first defined at %s)", cfg.Name, first),
 			})
+		} else {
+			configNames[cfg.Name] = field
 		}
-		configNames[cfg.Name] = true
 	}
 
 	// Validate external dependencies
+	externalIDs := make(map[string]string)
 	for i, ext := range c.External {
+		if ext.ID != "" {
+			field := fmt.Sprintf("external[%d].id", i)
+			if first, seen := externalIDs[ext.ID]; seen {
+				errors = append(errors, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("duplicate external id '%s' (first defined at %s)", ext.ID, first),
+				})
+			} else {
+				externalIDs[ext.ID] = field
+			}
+		}
 		if ext.ID == "" {
 			errors = append(errors, ValidationError{
 				Field:   fmt.Sprintf("external[%d].id", i),
@@ -121,8 +138,32 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Check that external depends_on references exist
+	for i, ext := range c.External {
+		for _, depID := range ext.DependsOn {
+			if _, ok := externalIDs[depID]; !ok {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("external[%d].depends_on", i),
+					Message: fmt.Sprintf("references unknown external dep '%s'", depID),
+				})
+			}
+		}
+	}
+
 	// Validate machine config
+	machineIDs := make(map[string]string)
 	for i, mc := range c.MachineConfig {
+		if mc.ID != "" {
+			field := fmt.Sprintf("machine_config[%d].id", i)
+			if first, seen := machineIDs[mc.ID]; seen {
+				errors = append(errors, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("duplicate machine config id '%s' (first defined at %s)", mc.ID, first),
+				})
+			} else {
+				machineIDs[mc.ID] = field
+			}
+		}
 		if mc.ID == "" {
 			errors = append(errors, ValidationError{
 				Field:   fmt.Sprintf("machine_config[%d].id", i),
@@ -141,6 +182,34 @@ func (c *Config) Validate() error {
 				Message: "template is required",
 			})
 		}
+		for j, pf := range mc.Prompts {
+			if err := validateNumberBounds(pf, fmt.Sprintf("machine_config[%d].prompts[%d]", i, j)); err != nil {
+				errors = append(errors, *err)
+			}
+		}
+	}
+
+	// Validate shared prompts
+	for i, pf := range c.SharedPrompts {
+		if err := validateNumberBounds(pf, fmt.Sprintf("shared_prompts[%d]", i)); err != nil {
+			errors = append(errors, *err)
+		}
+	}
+
+	// Validate git settings
+	if pp := c.Git.PreferProtocol; pp != "" && pp != "ssh" && pp != "https" {
+		errors = append(errors, ValidationError{
+			Field:   "git.prefer_protocol",
+			Message: fmt.Sprintf("must be 'ssh' or 'https', got '%s'", pp),
+		})
+	}
+	for i, rw := range c.Git.URLRewrites {
+		if rw.From == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("git.url_rewrites[%d].from", i),
+				Message: "from is required",
+			})
+		}
 	}
 
 	if len(errors) > 0 {
@@ -150,6 +219,21 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateNumberBounds checks that a number prompt's min/max range is
+// coherent. Returns nil for non-number fields and unbounded ranges.
+func validateNumberBounds(pf PromptField, fieldPath string) *ValidationError {
+	if pf.Type != "number" || pf.Min == nil || pf.Max == nil {
+		return nil
+	}
+	if *pf.Min > *pf.Max {
+		return &ValidationError{
+			Field:   fieldPath,
+			Message: fmt.Sprintf("min (%d) is greater than max (%d)", *pf.Min, *pf.Max),
+		}
+	}
+	return nil
+}
+
 // GetAllDependencies returns all dependencies (critical + core + optional)
 func (c *Config) GetAllDependencies() []DependencyItem {
 	var all []DependencyItem
@@ -159,7 +243,9 @@ func (c *Config) GetAllDependencies() []DependencyItem {
 	return all
 }
 
-// GetAllConfigs returns all configs (core + optional)
+// GetAllConfigs returns all configs (core + optional). Archived configs
+// are deliberately excluded: archiving is a soft-delete, so install and
+// refresh never touch them unless the caller opts in explicitly.
 func (c *Config) GetAllConfigs() []ConfigItem {
 	var all []ConfigItem
 	all = append(all, c.Configs.Core...)
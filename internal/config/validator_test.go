@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDuplicates(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr string
+	}{
+		{
+			name: "duplicate config name across groups",
+			mutate: func(c *Config) {
+				c.Configs.Optional = append(c.Configs.Optional, ConfigItem{Name: "git", Path: "git2"})
+			},
+			wantErr: "duplicate config name 'git' (first defined at configs.core[0].name)",
+		},
+		{
+			name: "duplicate external id",
+			mutate: func(c *Config) {
+				c.External = append(c.External, c.External[0])
+			},
+			wantErr: "duplicate external id 'pure' (first defined at external[0].id)",
+		},
+		{
+			name: "duplicate machine config id",
+			mutate: func(c *Config) {
+				c.MachineConfig = append(c.MachineConfig, c.MachineConfig[0])
+			},
+			wantErr: "duplicate machine config id 'gitconfig' (first defined at machine_config[0].id)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("base config should validate, got: %v", err)
+			}
+
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Validate() = nil, want duplicate error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %q, want it to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// validTestConfig returns a minimal config that passes validation
+func validTestConfig() *Config {
+	return &Config{
+		SchemaVersion: "1.0",
+		Metadata:      Metadata{Name: "test"},
+		Configs: ConfigGroups{
+			Core: []ConfigItem{{Name: "git", Path: "git"}},
+		},
+		External: []ExternalDep{
+			{ID: "pure", Name: "Pure", URL: "https://example.com/pure.git", Destination: "~/.zsh/pure", Method: "clone"},
+		},
+		MachineConfig: []MachinePrompt{
+			{ID: "gitconfig", Destination: "~/.gitconfig.local", Template: "x"},
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Save writes the config back to disk as YAML with the same header init
+// generates. Hand-written comments elsewhere in the file are not
+// preserved. If path is a directory, the config filename is appended.
+// When host overrides were merged at load time the pristine pre-merge
+// view is written, so saving on one machine never bakes that host's
+// disabled configs or overridden values into the shared file.
+func (c *Config) Save(path string) error {
+	stat, err := os.Stat(path)
+	if err == nil && stat.IsDir() {
+		path = filepath.Join(path, ConfigFileName)
+	}
+
+	target := c
+	if c.pristine != nil {
+		target = c.pristine
+	}
+
+	data, err := yaml.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("failed to generate YAML: %w", err)
+	}
+
+	content := fmt.Sprintf("# Generated by go4dot\n# Edit this file to customize your dotfiles management\n\n%s", string(data))
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// AddExternal appends an external dependency to the config after
+// validating required fields and ID uniqueness. An empty ID is generated
+// from the name.
+func (c *Config) AddExternal(dep ExternalDep) error {
+	if dep.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if dep.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if dep.Destination == "" {
+		return fmt.Errorf("destination is required")
+	}
+	if dep.Method != "" && dep.Method != "clone" && dep.Method != "copy" {
+		return fmt.Errorf("method must be 'clone' or 'copy', got '%s'", dep.Method)
+	}
+
+	if dep.ID == "" {
+		dep.ID = slugify(dep.Name)
+	}
+	if dep.ID == "" {
+		return fmt.Errorf("could not generate an ID from name '%s'", dep.Name)
+	}
+
+	for _, existing := range c.External {
+		if existing.ID == dep.ID {
+			return fmt.Errorf("external dependency '%s' already exists", dep.ID)
+		}
+	}
+
+	c.External = append(c.External, dep)
+	if c.pristine != nil {
+		c.pristine.External = append(c.pristine.External, dep)
+	}
+	return nil
+}
+
+// Archive soft-deletes a config: the item moves from core/optional into
+// the archived list, keeping its files in the repo but taking it out of
+// normal installs. Returns the archived item so callers can unstow it.
+// The caller is responsible for saving the config afterwards.
+func (c *Config) Archive(name string) (ConfigItem, error) {
+	item, err := c.archiveItem(name)
+	if err != nil {
+		return ConfigItem{}, err
+	}
+	// Mirror the move in the pristine view so it survives Save; the item
+	// may sit in a different group there (e.g. promoted by enable_configs)
+	if c.pristine != nil {
+		if _, err := c.pristine.archiveItem(name); err != nil {
+			return ConfigItem{}, fmt.Errorf("config file out of sync with host overrides: %w", err)
+		}
+	}
+	return item, nil
+}
+
+func (c *Config) archiveItem(name string) (ConfigItem, error) {
+	for i, item := range c.Configs.Core {
+		if item.Name == name {
+			c.Configs.Core = append(c.Configs.Core[:i], c.Configs.Core[i+1:]...)
+			c.Archived = append(c.Archived, item)
+			return item, nil
+		}
+	}
+	for i, item := range c.Configs.Optional {
+		if item.Name == name {
+			c.Configs.Optional = append(c.Configs.Optional[:i], c.Configs.Optional[i+1:]...)
+			c.Archived = append(c.Archived, item)
+			return item, nil
+		}
+	}
+	return ConfigItem{}, fmt.Errorf("config '%s' not found in core or optional", name)
+}
+
+// Unarchive moves an archived config back into the named group ("core"
+// or "optional"), making it part of normal installs again. The caller
+// is responsible for saving the config afterwards.
+func (c *Config) Unarchive(name, group string) error {
+	if err := c.unarchiveItem(name, group); err != nil {
+		return err
+	}
+	if c.pristine != nil {
+		if err := c.pristine.unarchiveItem(name, group); err != nil {
+			return fmt.Errorf("config file out of sync with host overrides: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) unarchiveItem(name, group string) error {
+	if group != "core" && group != "optional" {
+		return fmt.Errorf("group must be 'core' or 'optional', got '%s'", group)
+	}
+	if c.GetConfigByName(name) != nil {
+		return fmt.Errorf("config '%s' already exists in core or optional", name)
+	}
+
+	for i, item := range c.Archived {
+		if item.Name != name {
+			continue
+		}
+		c.Archived = append(c.Archived[:i], c.Archived[i+1:]...)
+		if group == "core" {
+			c.Configs.Core = append(c.Configs.Core, item)
+		} else {
+			c.Configs.Optional = append(c.Configs.Optional, item)
+		}
+		return nil
+	}
+	return fmt.Errorf("no archived config named '%s'", name)
+}
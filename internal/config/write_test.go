@@ -0,0 +1,409 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddExternal(t *testing.T) {
+	tests := []struct {
+		name    string
+		dep     ExternalDep
+		wantID  string
+		wantErr bool
+	}{
+		{
+			name: "Generates ID from name",
+			dep: ExternalDep{
+				Name:        "Pure Prompt",
+				URL:         "https://github.com/sindresorhus/pure.git",
+				Destination: "~/.zsh/pure",
+			},
+			wantID: "pure-prompt",
+		},
+		{
+			name: "Explicit ID kept",
+			dep: ExternalDep{
+				ID:          "pure",
+				Name:        "Pure Prompt",
+				URL:         "https://github.com/sindresorhus/pure.git",
+				Destination: "~/.zsh/pure",
+			},
+			wantID: "pure",
+		},
+		{
+			name:    "Missing URL",
+			dep:     ExternalDep{Name: "x", Destination: "~/.x"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing destination",
+			dep:     ExternalDep{Name: "x", URL: "https://example.com/x.git"},
+			wantErr: true,
+		},
+		{
+			name: "Bad method",
+			dep: ExternalDep{
+				Name:        "x",
+				URL:         "https://example.com/x.git",
+				Destination: "~/.x",
+				Method:      "symlink",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.AddExternal(tt.dep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddExternal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(cfg.External) != 1 || cfg.External[0].ID != tt.wantID {
+				t.Errorf("External = %+v, want one entry with ID %s", cfg.External, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAddExternalDuplicateID(t *testing.T) {
+	cfg := &Config{
+		External: []ExternalDep{
+			{ID: "pure", Name: "Pure", URL: "https://example.com/pure.git", Destination: "~/.pure"},
+		},
+	}
+
+	err := cfg.AddExternal(ExternalDep{
+		Name:        "Pure",
+		URL:         "https://example.com/other.git",
+		Destination: "~/.other",
+	})
+	if err == nil {
+		t.Error("AddExternal() should reject a duplicate ID")
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := &Config{
+		SchemaVersion: "1.0",
+		Metadata:      Metadata{Name: "test-dotfiles"},
+		External: []ExternalDep{
+			{
+				ID:          "pure",
+				Name:        "Pure",
+				URL:         "https://github.com/sindresorhus/pure.git",
+				Branch:      "main",
+				Destination: "~/.zsh/pure",
+				Condition:   map[string]string{"os": "linux"},
+			},
+		},
+	}
+
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Header matches what init generates
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if string(data[:1]) != "#" {
+		t.Error("saved config should start with a comment header")
+	}
+
+	loaded, err := LoadFromPath(dir)
+	if err != nil {
+		t.Fatalf("LoadFromPath() failed: %v", err)
+	}
+
+	if len(loaded.External) != 1 {
+		t.Fatalf("len(External) = %d, want 1", len(loaded.External))
+	}
+	ext := loaded.External[0]
+	if ext.ID != "pure" || ext.Branch != "main" || ext.Condition["os"] != "linux" {
+		t.Errorf("round-trip mismatch: %+v", ext)
+	}
+}
+
+func TestSavePreservesHostOverriddenConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ConfigFileName)
+
+	// The "*" host key matches whatever hostname the test machine has,
+	// so Load merges the override like a real matching host would
+	original := `schema_version: "1.0"
+metadata:
+  name: test
+configs:
+  core:
+    - name: git
+      path: git
+  optional:
+    - name: tmux
+      path: tmux
+external:
+  - id: pure
+    name: Pure
+    url: https://example.com/pure.git
+    destination: ~/.zsh/pure
+hosts:
+  "*":
+    enable_configs: [tmux]
+    disable_configs: [git]
+    external:
+      - id: pure
+        name: Pure
+        url: https://internal.example.com/pure.git
+        destination: ~/.zsh/pure
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	// Sanity check: the merged view has the override applied
+	if cfg.GetConfigByName("git") != nil {
+		t.Fatal("merged view should have 'git' disabled")
+	}
+
+	// A mutation via AddExternal lands in both views
+	if err := cfg.AddExternal(ExternalDep{
+		ID: "extra", Name: "Extra", URL: "https://example.com/x.git", Destination: "~/.x",
+	}); err != nil {
+		t.Fatalf("AddExternal() failed: %v", err)
+	}
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Reparse the raw file: the host-merged view must not be baked in
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if len(saved.Configs.Core) != 1 || saved.Configs.Core[0].Name != "git" {
+		t.Errorf("Core = %+v, want the disabled 'git' config kept", saved.Configs.Core)
+	}
+	if len(saved.Configs.Optional) != 1 || saved.Configs.Optional[0].Name != "tmux" {
+		t.Errorf("Optional = %+v, want 'tmux' still optional", saved.Configs.Optional)
+	}
+	if len(saved.External) != 2 || saved.External[0].URL != "https://example.com/pure.git" {
+		t.Errorf("External = %+v, want original URL kept plus the added dep", saved.External)
+	}
+	if saved.External[1].ID != "extra" {
+		t.Errorf("External[1] = %+v, want the added 'extra' dep", saved.External[1])
+	}
+}
+
+func TestArchiveWithHostOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ConfigFileName)
+
+	original := `schema_version: "1.0"
+metadata:
+  name: test
+configs:
+  core:
+    - name: git
+      path: git
+  optional:
+    - name: tmux
+      path: tmux
+hosts:
+  "*":
+    enable_configs: [tmux]
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	// tmux was promoted to core on this host, but archiving must move it
+	// out of optional in the saved file
+	if _, err := cfg.Archive("tmux"); err != nil {
+		t.Fatalf("Archive() failed: %v", err)
+	}
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if len(saved.Configs.Core) != 1 || saved.Configs.Core[0].Name != "git" {
+		t.Errorf("Core = %+v, want only 'git'", saved.Configs.Core)
+	}
+	if len(saved.Configs.Optional) != 0 {
+		t.Errorf("Optional = %+v, want empty", saved.Configs.Optional)
+	}
+	if len(saved.Archived) != 1 || saved.Archived[0].Name != "tmux" {
+		t.Errorf("Archived = %+v, want [tmux]", saved.Archived)
+	}
+}
+
+func TestGetAllConfigsExcludesArchived(t *testing.T) {
+	cfg := &Config{
+		Configs: ConfigGroups{
+			Core:     []ConfigItem{{Name: "git", Path: "git"}},
+			Optional: []ConfigItem{{Name: "tmux", Path: "tmux"}},
+		},
+		Archived: []ConfigItem{{Name: "screen", Path: "screen"}},
+	}
+
+	all := cfg.GetAllConfigs()
+	if len(all) != 2 {
+		t.Fatalf("len(GetAllConfigs()) = %d, want 2", len(all))
+	}
+	for _, item := range all {
+		if item.Name == "screen" {
+			t.Error("GetAllConfigs() should not include archived configs")
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			Configs: ConfigGroups{
+				Core:     []ConfigItem{{Name: "git", Path: "git"}},
+				Optional: []ConfigItem{{Name: "tmux", Path: "tmux"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "Archive core config", config: "git"},
+		{name: "Archive optional config", config: "tmux"},
+		{name: "Unknown name", config: "vim", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			item, err := cfg.Archive(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Archive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if item.Name != tt.config {
+				t.Errorf("Archive() returned %q, want %q", item.Name, tt.config)
+			}
+			if cfg.GetConfigByName(tt.config) != nil {
+				t.Errorf("config '%s' still active after archive", tt.config)
+			}
+			if len(cfg.Archived) != 1 || cfg.Archived[0].Name != tt.config {
+				t.Errorf("Archived = %+v, want [%s]", cfg.Archived, tt.config)
+			}
+		})
+	}
+}
+
+func TestUnarchive(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			Configs: ConfigGroups{
+				Core: []ConfigItem{{Name: "git", Path: "git"}},
+			},
+			Archived: []ConfigItem{{Name: "screen", Path: "screen"}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		group   string
+		wantErr bool
+	}{
+		{name: "Restore to optional", config: "screen", group: "optional"},
+		{name: "Restore to core", config: "screen", group: "core"},
+		{name: "Unknown name", config: "vim", group: "optional", wantErr: true},
+		{name: "Bad group", config: "screen", group: "extras", wantErr: true},
+		{name: "Name already active", config: "git", group: "optional", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			if tt.name == "Name already active" {
+				cfg.Archived = append(cfg.Archived, ConfigItem{Name: "git", Path: "git2"})
+			}
+
+			err := cfg.Unarchive(tt.config, tt.group)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unarchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(cfg.Archived) != 0 {
+				t.Errorf("Archived = %+v, want empty", cfg.Archived)
+			}
+			if cfg.GetConfigByName(tt.config) == nil {
+				t.Errorf("config '%s' not found after unarchive", tt.config)
+			}
+			if tt.group == "core" && len(cfg.Configs.Core) != 2 {
+				t.Errorf("len(Core) = %d, want 2", len(cfg.Configs.Core))
+			}
+			if tt.group == "optional" && len(cfg.Configs.Optional) != 1 {
+				t.Errorf("len(Optional) = %d, want 1", len(cfg.Configs.Optional))
+			}
+		})
+	}
+}
+
+func TestValidateExternalDependsOn(t *testing.T) {
+	cfg := &Config{
+		SchemaVersion: "1.0",
+		Metadata:      Metadata{Name: "test"},
+		External: []ExternalDep{
+			{ID: "theme", Name: "Theme", URL: "https://example.com/t.git", Destination: "~/.theme"},
+			{ID: "plugin", Name: "Plugin", URL: "https://example.com/p.git", Destination: "~/.plugin", DependsOn: []string{"theme"}},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil for valid depends_on", err)
+	}
+
+	cfg.External[1].DependsOn = []string{"no-such-dep"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown depends_on references")
+	}
+}
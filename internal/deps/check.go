@@ -16,6 +16,7 @@ type DepStatus string
 
 const (
 	StatusInstalled       DepStatus = "installed"
+	StatusAssumed         DepStatus = "assumed" // On the assume_installed allowlist, not actually checked
 	StatusMissing         DepStatus = "missing"
 	StatusCheckFailed     DepStatus = "check_failed"
 	StatusVersionMismatch DepStatus = "version_mismatch"
@@ -26,6 +27,7 @@ type DependencyCheck struct {
 	Item             config.DependencyItem
 	Status           DepStatus
 	InstalledPath    string // Path where binary was found
+	SatisfiedBy      string // Which any_of alternative was found, if applicable
 	InstalledVersion string // Version found
 	RequiredVersion  string // Version required
 	Error            error  // Error if check failed
@@ -40,29 +42,68 @@ type CheckResult struct {
 
 // Check verifies if dependencies are installed
 func Check(cfg *config.Config, p *platform.Platform) (*CheckResult, error) {
+	return CheckGroup(cfg, p, "")
+}
+
+// CheckGroup verifies dependencies, limited to the given group. An empty
+// group checks everything.
+func CheckGroup(cfg *config.Config, p *platform.Platform, group string) (*CheckResult, error) {
 	result := &CheckResult{}
 
+	allow := cfg.Dependencies.AssumeInstalled
+
 	// Check critical dependencies
 	for _, dep := range cfg.Dependencies.Critical {
-		check := checkDependency(dep)
+		if !includeDep(dep, p, group) {
+			continue
+		}
+		check := checkOrAssume(dep, allow)
 		result.Critical = append(result.Critical, check)
 	}
 
 	// Check core dependencies
 	for _, dep := range cfg.Dependencies.Core {
-		check := checkDependency(dep)
+		if !includeDep(dep, p, group) {
+			continue
+		}
+		check := checkOrAssume(dep, allow)
 		result.Core = append(result.Core, check)
 	}
 
 	// Check optional dependencies
 	for _, dep := range cfg.Dependencies.Optional {
-		check := checkDependency(dep)
+		if !includeDep(dep, p, group) {
+			continue
+		}
+		check := checkOrAssume(dep, allow)
 		result.Optional = append(result.Optional, check)
 	}
 
 	return result, nil
 }
 
+// includeDep reports whether a dependency applies on this platform and
+// matches the requested group filter ("" matches everything).
+func includeDep(dep config.DependencyItem, p *platform.Platform, group string) bool {
+	if !platform.MatchesArch(dep.Arch, p) {
+		return false
+	}
+	return group == "" || dep.Group == group
+}
+
+// checkOrAssume marks a dependency as assumed-installed if it is on the
+// allowlist, bypassing detection entirely. This unblocks environments
+// where tools exist via mechanisms detection can't see (busybox applets,
+// custom PATH setups).
+func checkOrAssume(dep config.DependencyItem, allow []string) DependencyCheck {
+	for _, name := range allow {
+		if name == dep.Name || (dep.Binary != "" && name == dep.Binary) {
+			return DependencyCheck{Item: dep, Status: StatusAssumed}
+		}
+	}
+	return checkDependency(dep)
+}
+
 // checkDependency checks if a single dependency is installed
 func checkDependency(dep config.DependencyItem) DependencyCheck {
 	check := DependencyCheck{
@@ -70,6 +111,22 @@ func checkDependency(dep config.DependencyItem) DependencyCheck {
 		RequiredVersion: dep.Version,
 	}
 
+	// Alternatives: installed if any listed binary is present
+	if len(dep.AnyOf) > 0 {
+		for _, alt := range dep.AnyOf {
+			path, err := exec.LookPath(alt)
+			if err != nil {
+				continue
+			}
+			check.InstalledPath = path
+			check.SatisfiedBy = alt
+			check.Status = StatusInstalled
+			return check
+		}
+		check.Status = StatusMissing
+		return check
+	}
+
 	// Determine which binary to check for
 	binaryName := dep.Binary
 	if binaryName == "" {
@@ -186,6 +243,42 @@ func versionEqual(v1, v2 []int) bool {
 	return true
 }
 
+// CategoryGroup is one display category of dependency checks within a
+// tier, used to organize long dependency lists.
+type CategoryGroup struct {
+	Name   string
+	Checks []DependencyCheck
+}
+
+// ByCategory groups checks by their item's category in first-seen order.
+// Items without a category land in a trailing "Other" group.
+func ByCategory(checks []DependencyCheck) []CategoryGroup {
+	var groups []CategoryGroup
+	index := make(map[string]int)
+	var other []DependencyCheck
+
+	for _, check := range checks {
+		category := check.Item.Category
+		if category == "" {
+			other = append(other, check)
+			continue
+		}
+		i, ok := index[category]
+		if !ok {
+			i = len(groups)
+			index[category] = i
+			groups = append(groups, CategoryGroup{Name: category})
+		}
+		groups[i].Checks = append(groups[i].Checks, check)
+	}
+
+	if len(other) > 0 {
+		groups = append(groups, CategoryGroup{Name: "Other", Checks: other})
+	}
+
+	return groups
+}
+
 // GetMissing returns all missing dependencies or those with version mismatch
 func (r *CheckResult) GetMissing() []DependencyCheck {
 	var missing []DependencyCheck
@@ -201,6 +294,22 @@ func (r *CheckResult) GetMissing() []DependencyCheck {
 	return missing
 }
 
+// GetMissingRequired returns missing critical and core dependencies or
+// those with version mismatch, leaving the optional tier out
+func (r *CheckResult) GetMissingRequired() []DependencyCheck {
+	var missing []DependencyCheck
+
+	for _, checks := range [][]DependencyCheck{r.Critical, r.Core} {
+		for _, check := range checks {
+			if check.Status == StatusMissing || check.Status == StatusVersionMismatch {
+				missing = append(missing, check)
+			}
+		}
+	}
+
+	return missing
+}
+
 // GetMissingCritical returns only missing critical dependencies or those with version mismatch
 func (r *CheckResult) GetMissingCritical() []DependencyCheck {
 	var missing []DependencyCheck
@@ -226,7 +335,7 @@ func (r *CheckResult) Summary() string {
 
 	for _, checks := range [][]DependencyCheck{r.Critical, r.Core, r.Optional} {
 		for _, check := range checks {
-			if check.Status == StatusInstalled {
+			if check.Status == StatusInstalled || check.Status == StatusAssumed {
 				totalInstalled++
 			} else if check.Status == StatusMissing {
 				totalMissing++
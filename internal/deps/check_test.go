@@ -53,6 +53,106 @@ func TestCheck(t *testing.T) {
 	}
 }
 
+func TestCheckAssumeInstalled(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Core: []config.DependencyItem{
+				{Name: "definitely-does-not-exist-12345", Binary: "definitely-does-not-exist-12345"},
+				{Name: "curl-like", Binary: "also-does-not-exist-12345"},
+			},
+			AssumeInstalled: []string{"definitely-does-not-exist-12345"},
+		},
+	}
+
+	p, err := platform.Detect()
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	result, err := Check(cfg, p)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+
+	if result.Core[0].Status != StatusAssumed {
+		t.Errorf("Core[0].Status = %v, want %v", result.Core[0].Status, StatusAssumed)
+	}
+	if result.Core[1].Status != StatusMissing {
+		t.Errorf("Core[1].Status = %v, want %v", result.Core[1].Status, StatusMissing)
+	}
+
+	// Assumed deps must not land in the missing set the installer uses
+	for _, check := range result.GetMissing() {
+		if check.Item.Name == "definitely-does-not-exist-12345" {
+			t.Error("assumed dep reported as missing")
+		}
+	}
+}
+
+func TestCheckFiltersArch(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Core: []config.DependencyItem{
+				{Name: "sh", Binary: "sh"},
+				{Name: "x86-only-tool", Binary: "sh", Arch: []string{"not-a-real-arch"}},
+			},
+		},
+	}
+
+	p, err := platform.Detect()
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	result, err := Check(cfg, p)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+
+	// The arch-restricted dependency should be filtered out entirely
+	if len(result.Core) != 1 {
+		t.Fatalf("len(Core) = %d, want 1", len(result.Core))
+	}
+	if result.Core[0].Item.Name != "sh" {
+		t.Errorf("Core[0].Item.Name = %s, want sh", result.Core[0].Item.Name)
+	}
+}
+
+func TestCheckGroup(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Core: []config.DependencyItem{
+				{Name: "sh", Binary: "sh", Group: "cli"},
+				{Name: "some-gui-tool", Binary: "sh", Group: "gui"},
+				{Name: "ungrouped", Binary: "sh"},
+			},
+		},
+	}
+
+	p, err := platform.Detect()
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	// Filtering by group keeps only matching deps
+	result, err := CheckGroup(cfg, p, "cli")
+	if err != nil {
+		t.Fatalf("CheckGroup() failed: %v", err)
+	}
+	if len(result.Core) != 1 || result.Core[0].Item.Name != "sh" {
+		t.Errorf("CheckGroup(cli) Core = %+v, want only sh", result.Core)
+	}
+
+	// Empty group checks everything
+	result, err = CheckGroup(cfg, p, "")
+	if err != nil {
+		t.Fatalf("CheckGroup() failed: %v", err)
+	}
+	if len(result.Core) != 3 {
+		t.Errorf("CheckGroup(\"\") len(Core) = %d, want 3", len(result.Core))
+	}
+}
+
 func TestCheckDependency(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -99,6 +199,56 @@ func TestCheckDependency(t *testing.T) {
 	}
 }
 
+func TestCheckDependencyAnyOf(t *testing.T) {
+	tests := []struct {
+		name          string
+		dep           config.DependencyItem
+		wantStatus    DepStatus
+		wantSatisfied string
+	}{
+		{
+			name: "First alternative present",
+			dep: config.DependencyItem{
+				Name:  "shell",
+				AnyOf: []string{"sh", "fake-binary-xyz"},
+			},
+			wantStatus:    StatusInstalled,
+			wantSatisfied: "sh",
+		},
+		{
+			name: "Later alternative present",
+			dep: config.DependencyItem{
+				Name:  "shell",
+				AnyOf: []string{"fake-binary-xyz", "sh"},
+			},
+			wantStatus:    StatusInstalled,
+			wantSatisfied: "sh",
+		},
+		{
+			name: "No alternative present",
+			dep: config.DependencyItem{
+				Name:  "clipboard",
+				AnyOf: []string{"fake-binary-xyz", "fake-binary-abc"},
+			},
+			wantStatus: StatusMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := checkDependency(tt.dep)
+
+			if check.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", check.Status, tt.wantStatus)
+			}
+
+			if check.SatisfiedBy != tt.wantSatisfied {
+				t.Errorf("SatisfiedBy = %q, want %q", check.SatisfiedBy, tt.wantSatisfied)
+			}
+		})
+	}
+}
+
 func TestGetMissing(t *testing.T) {
 	result := &CheckResult{
 		Critical: []DependencyCheck{
@@ -244,3 +394,62 @@ func TestParseVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestByCategory(t *testing.T) {
+	check := func(name, category string) DependencyCheck {
+		return DependencyCheck{Item: config.DependencyItem{Name: name, Category: category}}
+	}
+
+	tests := []struct {
+		name   string
+		checks []DependencyCheck
+		want   []string // group names in order
+	}{
+		{
+			name:   "No categories is one Other group",
+			checks: []DependencyCheck{check("git", ""), check("stow", "")},
+			want:   []string{"Other"},
+		},
+		{
+			name: "Categories in first-seen order with Other last",
+			checks: []DependencyCheck{
+				check("nvim", "editors"),
+				check("zsh", "shell"),
+				check("git", ""),
+				check("helix", "editors"),
+			},
+			want: []string{"editors", "shell", "Other"},
+		},
+		{
+			name:   "Empty input",
+			checks: nil,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := ByCategory(tt.checks)
+			if len(groups) != len(tt.want) {
+				t.Fatalf("ByCategory() returned %d groups, want %d", len(groups), len(tt.want))
+			}
+			for i, group := range groups {
+				if group.Name != tt.want[i] {
+					t.Errorf("group[%d] = %q, want %q", i, group.Name, tt.want[i])
+				}
+			}
+		})
+	}
+
+	groups := ByCategory([]DependencyCheck{
+		check("nvim", "editors"),
+		check("helix", "editors"),
+		check("git", ""),
+	})
+	if len(groups[0].Checks) != 2 {
+		t.Errorf("editors group has %d checks, want 2", len(groups[0].Checks))
+	}
+	if len(groups[1].Checks) != 1 || groups[1].Checks[0].Item.Name != "git" {
+		t.Errorf("Other group = %+v, want git only", groups[1].Checks)
+	}
+}
@@ -0,0 +1,72 @@
+package deps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+// ExportList renders the config's dependency list in a native package
+// tool format (Homebrew Bundle, apt or pacman package lists) so users can
+// bootstrap with native tooling while the config stays the source of
+// truth for the package set.
+func ExportList(cfg *config.Config, p *platform.Platform, format string) (string, error) {
+	var manager string
+	switch format {
+	case "brewfile":
+		manager = "brew"
+	case "apt-list":
+		manager = "apt"
+	case "pacman-list":
+		manager = "pacman"
+	default:
+		return "", fmt.Errorf("unknown export format: %s (supported: brewfile, apt-list, pacman-list)", format)
+	}
+
+	var sb strings.Builder
+	seen := make(map[string]bool)
+
+	for _, dep := range cfg.GetAllDependencies() {
+		if !platform.MatchesArch(dep.Arch, p) {
+			continue
+		}
+
+		pkg := getPackageNameForPlatform(dep, manager)
+		if pkg == "" && len(dep.AnyOf) > 0 {
+			// Alternatives: export the first one
+			pkg = platform.MapPackageName(dep.AnyOf[0], manager)
+		}
+		if pkg == "" {
+			pkg = platform.MapPackageName(dep.Name, manager)
+		}
+
+		if seen[pkg] {
+			continue
+		}
+		seen[pkg] = true
+
+		if format == "brewfile" {
+			sb.WriteString(fmt.Sprintf("brew %q\n", pkg))
+		} else {
+			sb.WriteString(pkg + "\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// DefaultExportFormat returns the export format matching the platform's
+// package manager, or an empty string if there is no natural fit.
+func DefaultExportFormat(p *platform.Platform) string {
+	switch p.PackageManager {
+	case "brew":
+		return "brewfile"
+	case "apt":
+		return "apt-list"
+	case "pacman":
+		return "pacman-list"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package deps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+func TestExportList(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Critical: []config.DependencyItem{
+				{Name: "git"},
+			},
+			Core: []config.DependencyItem{
+				{Name: "fd"}, // Mapped via MapPackageName
+				{Name: "bat", Package: map[string]string{"apt": "bat"}},
+			},
+			Optional: []config.DependencyItem{
+				{Name: "git"}, // Duplicate should be dropped
+			},
+		},
+	}
+	p := &platform.Platform{}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "Brewfile",
+			format: "brewfile",
+			want:   []string{`brew "git"`, `brew "fd"`, `brew "bat"`},
+		},
+		{
+			name:   "APT list maps package names",
+			format: "apt-list",
+			want:   []string{"git", "fd-find", "bat"},
+		},
+		{
+			name:   "Pacman list",
+			format: "pacman-list",
+			want:   []string{"git", "fd", "bat"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ExportList(cfg, p, tt.format)
+			if err != nil {
+				t.Fatalf("ExportList() failed: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d lines, want %d: %q", len(lines), len(tt.want), out)
+			}
+			for i, want := range tt.want {
+				if lines[i] != want {
+					t.Errorf("line %d = %q, want %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExportListUnknownFormat(t *testing.T) {
+	_, err := ExportList(&config.Config{}, &platform.Platform{}, "rpm-spec")
+	if err == nil {
+		t.Error("ExportList() should fail for an unknown format")
+	}
+}
+
+func TestDefaultExportFormat(t *testing.T) {
+	tests := []struct {
+		manager string
+		want    string
+	}{
+		{"brew", "brewfile"},
+		{"apt", "apt-list"},
+		{"pacman", "pacman-list"},
+		{"dnf", ""},
+	}
+
+	for _, tt := range tests {
+		p := &platform.Platform{PackageManager: tt.manager}
+		if got := DefaultExportFormat(p); got != tt.want {
+			t.Errorf("DefaultExportFormat(%s) = %q, want %q", tt.manager, got, tt.want)
+		}
+	}
+}
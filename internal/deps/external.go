@@ -1,22 +1,29 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
 	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/state"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // ExternalResult represents the result of cloning external dependencies
 type ExternalResult struct {
-	Cloned  []config.ExternalDep
-	Updated []config.ExternalDep
-	Failed  []ExternalError
-	Skipped []ExternalSkipped
+	Cloned   []config.ExternalDep
+	Updated  []config.ExternalDep
+	UpToDate []config.ExternalDep // Already matched the remote, no pull needed
+	Pruned   []ExternalPruned     // Stale installs removed because they left the config
+	Failed   []ExternalError
+	Skipped  []ExternalSkipped
 }
 
 // ExternalError represents a failed clone operation
@@ -31,19 +38,37 @@ type ExternalSkipped struct {
 	Reason string
 }
 
+// ExternalPruned identifies a stale install removed by pruning
+type ExternalPruned struct {
+	ID   string
+	Path string
+}
+
 // ExternalOptions configures the clone behavior
 type ExternalOptions struct {
-	DryRun       bool                                 // Don't actually clone, just report
-	Update       bool                                 // Pull updates for existing repos
-	RepoRoot     string                               // Path to dotfiles root for @repoRoot expansion
-	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
+	DryRun        bool                                 // Don't actually clone, just report
+	Update        bool                                 // Pull updates for existing repos
+	FailFast      bool                                 // Stop at the first failure instead of continuing
+	SkipPostClone bool                                 // Don't run post_clone commands
+	Offline       bool                                 // Skip all network operations (clone/pull)
+	Prune         bool                                 // Remove state-recorded installs dropped from config
+	Lock          *LockFile                            // When set, refuse unpinned deps and check out exact locked commits
+	RepoRoot      string                               // Path to dotfiles root for @repoRoot expansion
+	ProgressFunc  func(current, total int, msg string) // Called for progress updates with item counts
 }
 
 // CloneExternal clones all external dependencies from the config
-func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOptions) (*ExternalResult, error) {
+func CloneExternal(ctx context.Context, cfg *config.Config, p *platform.Platform, opts ExternalOptions) (*ExternalResult, error) {
 	result := &ExternalResult{}
 
 	if len(cfg.External) == 0 {
+		// Still prune: dropping every external from config is exactly
+		// when stale installs need cleaning up
+		if opts.Prune {
+			if err := pruneStale(cfg, opts, result); err != nil {
+				return result, err
+			}
+		}
 		return result, nil
 	}
 
@@ -52,10 +77,37 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 		return nil, fmt.Errorf("git is required but not found in PATH")
 	}
 
-	total := len(cfg.External)
-	for i, ext := range cfg.External {
+	// Clone prerequisites before their dependents
+	ordered, err := sortExternalDeps(cfg.External)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(ordered)
+	for i, ext := range ordered {
+		// Apply config-level URL rewrites before any git operation
+		ext.URL = rewriteURL(ext.URL, cfg.Git)
+
 		current := i + 1
 
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+			break
+		}
+
+		// A dependent is pointless without its prerequisite
+		if reason := prereqFailure(ext, result); reason != "" {
+			result.Skipped = append(result.Skipped, ExternalSkipped{
+				Dep:    ext,
+				Reason: reason,
+			})
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("⊘ Skipping %s (%s)", ext.Name, reason))
+			}
+			continue
+		}
+
 		// Check condition
 		if !platform.CheckCondition(ext.Condition, p) {
 			result.Skipped = append(result.Skipped, ExternalSkipped{
@@ -68,6 +120,24 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 			continue
 		}
 
+		// Replaying from a lockfile: refuse anything unpinned, and
+		// remember the commit to converge on
+		var pin string
+		if opts.Lock != nil {
+			entry, lockErr := lockedEntry(opts.Lock, ext)
+			if lockErr != nil {
+				result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: lockErr})
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✗ %s: %v", ext.Name, lockErr))
+				}
+				if opts.FailFast {
+					break
+				}
+				continue
+			}
+			pin = entry.Commit
+		}
+
 		// Expand destination path
 		destPath, err := expandPath(ext.Destination, opts.RepoRoot)
 		if err != nil {
@@ -75,6 +145,9 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 				Dep:   ext,
 				Error: fmt.Errorf("failed to expand path: %w", err),
 			})
+			if opts.FailFast {
+				break
+			}
 			continue
 		}
 
@@ -86,22 +159,100 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 				goto Execute
 			}
 
+			// Pinned installs converge on the locked commit even
+			// without --update
+			if pin != "" && isGit {
+				if head, headErr := gitHead(ctx, destPath); headErr == nil && head == pin {
+					result.UpToDate = append(result.UpToDate, ext)
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(current, total, fmt.Sprintf("✓ %s already at pinned commit", ext.Name))
+					}
+					continue
+				}
+
+				if opts.Offline {
+					result.Failed = append(result.Failed, ExternalError{
+						Dep:   ext,
+						Error: fmt.Errorf("offline and not at pinned commit"),
+					})
+					if opts.FailFast {
+						break
+					}
+					continue
+				}
+
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("↻ Pinning %s...", ext.Name))
+				}
+
+				if !opts.DryRun {
+					if err := gitCheckoutCommit(ctx, destPath, pin, gitEnv(ext)); err != nil {
+						result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+						if opts.FailFast {
+							break
+						}
+						continue
+					}
+				}
+
+				result.Updated = append(result.Updated, ext)
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✓ Pinned %s", ext.Name))
+				}
+				continue
+			}
+
 			if opts.Update && isGit {
 				// Update existing repo
+				if opts.Offline {
+					result.Skipped = append(result.Skipped, ExternalSkipped{
+						Dep:    ext,
+						Reason: "offline",
+					})
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(current, total, fmt.Sprintf("⊘ Skipping %s (offline)", ext.Name))
+					}
+					continue
+				}
+
 				if opts.ProgressFunc != nil {
 					opts.ProgressFunc(current, total, fmt.Sprintf("↻ Updating %s...", ext.Name))
 				}
 
 				if !opts.DryRun {
-					if err := gitPull(destPath); err != nil {
+					// A repo whose HEAD already matches the remote
+					// doesn't need the full pull
+					if gitUpToDate(ctx, destPath, gitEnv(ext)) {
+						result.UpToDate = append(result.UpToDate, ext)
+						if opts.ProgressFunc != nil {
+							opts.ProgressFunc(current, total, fmt.Sprintf("✓ %s already up to date", ext.Name))
+						}
+						continue
+					}
+
+					if err := gitPull(ctx, destPath, gitEnv(ext)); err != nil {
 						result.Failed = append(result.Failed, ExternalError{
 							Dep:   ext,
 							Error: fmt.Errorf("failed to update: %w", err),
 						})
+						if opts.FailFast {
+							break
+						}
 						continue
 					}
 				}
 
+				if err := runPostClone(ctx, ext, destPath, current, total, p, opts); err != nil {
+					result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(current, total, fmt.Sprintf("✗ %s: %v", ext.Name, err))
+					}
+					if opts.FailFast {
+						break
+					}
+					continue
+				}
+
 				result.Updated = append(result.Updated, ext)
 				if opts.ProgressFunc != nil {
 					opts.ProgressFunc(current, total, fmt.Sprintf("✓ Updated %s", ext.Name))
@@ -121,6 +272,17 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 
 	Execute:
 		// Clone the repository
+		if opts.Offline {
+			result.Skipped = append(result.Skipped, ExternalSkipped{
+				Dep:    ext,
+				Reason: "offline",
+			})
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("⊘ Skipping %s (offline)", ext.Name))
+			}
+			continue
+		}
+
 		if opts.ProgressFunc != nil {
 			opts.ProgressFunc(current, total, fmt.Sprintf("⬇ Cloning %s...", ext.Name))
 		}
@@ -130,6 +292,19 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(current, total, fmt.Sprintf("✓ Would clone %s to %s", ext.Name, destPath))
 			}
+			runPostClone(ctx, ext, destPath, current, total, p, opts)
+			continue
+		}
+
+		// Fail with a clear message before git trips over permissions
+		if err := pathutil.CheckWritable(destPath); err != nil {
+			result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("✗ %s: %v", ext.Name, err))
+			}
+			if opts.FailFast {
+				break
+			}
 			continue
 		}
 
@@ -142,9 +317,9 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 		var cloneErr error
 		switch method {
 		case "clone":
-			cloneErr = gitClone(ext.URL, destPath)
+			cloneErr = gitClone(ctx, ext, destPath)
 		case "copy":
-			cloneErr = gitCloneThenCopy(ext.URL, destPath, ext.MergeStrategy)
+			cloneErr = gitCloneThenCopy(ctx, ext, destPath)
 		default:
 			cloneErr = fmt.Errorf("unknown method: %s", method)
 		}
@@ -157,7 +332,34 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(current, total, fmt.Sprintf("✗ Failed to clone %s: %v", ext.Name, cloneErr))
 			}
+			if opts.FailFast {
+				break
+			}
 		} else {
+			if pin != "" {
+				if err := gitCheckoutCommit(ctx, destPath, pin, gitEnv(ext)); err != nil {
+					result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(current, total, fmt.Sprintf("✗ %s: %v", ext.Name, err))
+					}
+					if opts.FailFast {
+						break
+					}
+					continue
+				}
+			}
+
+			if err := runPostClone(ctx, ext, destPath, current, total, p, opts); err != nil {
+				result.Failed = append(result.Failed, ExternalError{Dep: ext, Error: err})
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✗ %s: %v", ext.Name, err))
+				}
+				if opts.FailFast {
+					break
+				}
+				continue
+			}
+
 			result.Cloned = append(result.Cloned, ext)
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(current, total, fmt.Sprintf("✓ Cloned %s", ext.Name))
@@ -165,11 +367,281 @@ func CloneExternal(cfg *config.Config, p *platform.Platform, opts ExternalOption
 		}
 	}
 
+	if opts.Prune {
+		if err := pruneStale(cfg, opts, result); err != nil {
+			return result, err
+		}
+	}
+
 	return result, nil
 }
 
+// sortExternalDeps orders external deps so prerequisites come before
+// their dependents, keeping config order otherwise. References to
+// unknown IDs are ignored here (Validate reports them); cycles are an
+// error.
+func sortExternalDeps(exts []config.ExternalDep) ([]config.ExternalDep, error) {
+	index := make(map[string]int, len(exts))
+	for i, ext := range exts {
+		index[ext.ID] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(exts))
+	ordered := make([]config.ExternalDep, 0, len(exts))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle involving external dep '%s'", exts[i].ID)
+		}
+
+		states[i] = visiting
+		for _, id := range exts[i].DependsOn {
+			if j, ok := index[id]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		ordered = append(ordered, exts[i])
+		return nil
+	}
+
+	for i := range exts {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// ExternalTreeNode is one entry in the flattened external dependency
+// tree, at its indentation depth
+type ExternalTreeNode struct {
+	Dep   config.ExternalDep
+	Depth int
+}
+
+// ExternalTree flattens the external dependency graph into clone order
+// with tree depths: roots are deps with no known prerequisites, and each
+// dep's dependents appear under it. A dep with several prerequisites
+// shows up under each of them. Cycles are an error, like during cloning.
+func ExternalTree(cfg *config.Config) ([]ExternalTreeNode, error) {
+	if _, err := sortExternalDeps(cfg.External); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(cfg.External))
+	for _, ext := range cfg.External {
+		known[ext.ID] = true
+	}
+
+	dependents := make(map[string][]config.ExternalDep)
+	var roots []config.ExternalDep
+	for _, ext := range cfg.External {
+		hasPrereq := false
+		for _, id := range ext.DependsOn {
+			if known[id] {
+				hasPrereq = true
+				dependents[id] = append(dependents[id], ext)
+			}
+		}
+		if !hasPrereq {
+			roots = append(roots, ext)
+		}
+	}
+
+	var nodes []ExternalTreeNode
+	var walk func(ext config.ExternalDep, depth int)
+	walk = func(ext config.ExternalDep, depth int) {
+		nodes = append(nodes, ExternalTreeNode{Dep: ext, Depth: depth})
+		for _, child := range dependents[ext.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return nodes, nil
+}
+
+// prereqFailure returns a skip reason when one of the dep's
+// prerequisites failed (or was itself skipped over a failed
+// prerequisite), "" otherwise.
+func prereqFailure(ext config.ExternalDep, result *ExternalResult) string {
+	if len(ext.DependsOn) == 0 {
+		return ""
+	}
+
+	blocked := make(map[string]bool)
+	for _, f := range result.Failed {
+		blocked[f.Dep.ID] = true
+	}
+	for _, s := range result.Skipped {
+		if strings.HasPrefix(s.Reason, "prerequisite") {
+			blocked[s.Dep.ID] = true
+		}
+	}
+
+	for _, id := range ext.DependsOn {
+		if blocked[id] {
+			return fmt.Sprintf("prerequisite '%s' failed", id)
+		}
+	}
+	return ""
+}
+
+// PruneCandidates returns state-recorded external installs whose IDs are
+// no longer in the config. Only entries whose recorded install path still
+// exists on disk are returned, sorted by ID.
+func PruneCandidates(cfg *config.Config) ([]ExternalPruned, error) {
+	st, err := state.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if st == nil {
+		return nil, nil
+	}
+
+	inConfig := make(map[string]bool)
+	for _, ext := range cfg.External {
+		inConfig[ext.ID] = true
+	}
+
+	var stale []ExternalPruned
+	for id, entry := range st.ExternalDeps {
+		if inConfig[id] || !entry.Installed {
+			continue
+		}
+		// Only paths go4dot recorded itself are ever deleted
+		if entry.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			continue
+		}
+		stale = append(stale, ExternalPruned{ID: id, Path: entry.Path})
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].ID < stale[j].ID })
+	return stale, nil
+}
+
+// pruneStale removes installs recorded in state that were dropped from
+// the config, appending them to result.Pruned
+func pruneStale(cfg *config.Config, opts ExternalOptions, result *ExternalResult) error {
+	stale, err := PruneCandidates(cfg)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	st, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	total := len(stale)
+	for i, candidate := range stale {
+		if opts.DryRun {
+			result.Pruned = append(result.Pruned, candidate)
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(i+1, total, fmt.Sprintf("✓ Would prune %s (%s)", candidate.ID, candidate.Path))
+			}
+			continue
+		}
+
+		if err := os.RemoveAll(candidate.Path); err != nil {
+			return fmt.Errorf("failed to prune %s: %w", candidate.ID, err)
+		}
+
+		st.RemoveExternalDep(candidate.ID)
+		result.Pruned = append(result.Pruned, candidate)
+		if opts.ProgressFunc != nil {
+			opts.ProgressFunc(i+1, total, fmt.Sprintf("✓ Pruned %s", candidate.ID))
+		}
+	}
+
+	if !opts.DryRun {
+		if err := st.Save(); err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// hookEnv is the environment post_clone commands run with: the parent
+// environment plus the clone destination and platform facts, so hooks
+// can branch per OS without re-detecting anything.
+func hookEnv(destPath string, p *platform.Platform) []string {
+	env := append(os.Environ(), "GOPHERDOT_DEST="+destPath)
+	if p != nil {
+		env = append(env,
+			"GOPHERDOT_OS="+p.OS,
+			"GOPHERDOT_DISTRO="+p.Distro,
+			"GOPHERDOT_ARCH="+p.Architecture,
+			"GOPHERDOT_PACKAGE_MANAGER="+p.PackageManager,
+		)
+	}
+	return env
+}
+
+// runPostClone executes an external dep's post_clone commands in the
+// destination directory after a successful clone or update. Commands run
+// through the shell so pipelines and env vars work. In dry-run mode the
+// commands are only printed.
+func runPostClone(ctx context.Context, ext config.ExternalDep, destPath string, current, total int, p *platform.Platform, opts ExternalOptions) error {
+	if len(ext.PostClone) == 0 || opts.SkipPostClone {
+		return nil
+	}
+
+	for _, cmdLine := range ext.PostClone {
+		if opts.DryRun {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("  Would run: %s", cmdLine))
+			}
+			continue
+		}
+
+		if opts.ProgressFunc != nil {
+			opts.ProgressFunc(current, total, fmt.Sprintf("  Running: %s", cmdLine))
+		}
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", cmdLine)
+		cmd.Dir = destPath
+		cmd.Env = hookEnv(destPath, p)
+		verbose.Command(cmd.Args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			if ext.PostCloneOptional {
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("  ⚠ Post-clone command %q failed (optional): %v", cmdLine, err))
+				}
+				continue
+			}
+			return fmt.Errorf("post-clone command %q failed: %w\nOutput: %s", cmdLine, err, string(output))
+		}
+	}
+
+	return nil
+}
+
 // CloneSingle clones a single external dependency by ID
-func CloneSingle(cfg *config.Config, p *platform.Platform, id string, opts ExternalOptions) error {
+func CloneSingle(ctx context.Context, cfg *config.Config, p *platform.Platform, id string, opts ExternalOptions) error {
 	var found *config.ExternalDep
 	for i := range cfg.External {
 		if cfg.External[i].ID == id {
@@ -182,6 +654,11 @@ func CloneSingle(cfg *config.Config, p *platform.Platform, id string, opts Exter
 		return fmt.Errorf("external dependency '%s' not found", id)
 	}
 
+	// Apply config-level URL rewrites before any git operation
+	dep := *found
+	dep.URL = rewriteURL(dep.URL, cfg.Git)
+	found = &dep
+
 	// Check condition
 	if !platform.CheckCondition(found.Condition, p) {
 		return fmt.Errorf("condition not met for '%s'", id)
@@ -201,14 +678,27 @@ func CloneSingle(cfg *config.Config, p *platform.Platform, id string, opts Exter
 		}
 
 		if opts.Update && isGit {
+			if opts.Offline {
+				return fmt.Errorf("cannot update '%s' in offline mode", id)
+			}
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(1, 1, fmt.Sprintf("↻ Updating %s...", found.Name))
 			}
 			if !opts.DryRun {
-				if err := gitPull(destPath); err != nil {
+				if gitUpToDate(ctx, destPath, gitEnv(*found)) {
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(1, 1, fmt.Sprintf("✓ %s already up to date", found.Name))
+					}
+					return nil
+				}
+
+				if err := gitPull(ctx, destPath, gitEnv(*found)); err != nil {
 					return fmt.Errorf("failed to update: %w", err)
 				}
 			}
+			if err := runPostClone(ctx, *found, destPath, 1, 1, p, opts); err != nil {
+				return err
+			}
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(1, 1, fmt.Sprintf("✓ Updated %s", found.Name))
 			}
@@ -218,6 +708,10 @@ func CloneSingle(cfg *config.Config, p *platform.Platform, id string, opts Exter
 	}
 
 Execute:
+	if opts.Offline {
+		return fmt.Errorf("cannot clone '%s' in offline mode", id)
+	}
+
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(1, 1, fmt.Sprintf("⬇ Cloning %s...", found.Name))
 	}
@@ -226,7 +720,11 @@ Execute:
 		if opts.ProgressFunc != nil {
 			opts.ProgressFunc(1, 1, fmt.Sprintf("✓ Would clone %s to %s", found.Name, destPath))
 		}
-		return nil
+		return runPostClone(ctx, *found, destPath, 1, 1, p, opts)
+	}
+
+	if err := pathutil.CheckWritable(destPath); err != nil {
+		return err
 	}
 
 	method := found.Method
@@ -236,12 +734,17 @@ Execute:
 
 	switch method {
 	case "clone":
-		return gitClone(found.URL, destPath)
+		err = gitClone(ctx, *found, destPath)
 	case "copy":
-		return gitCloneThenCopy(found.URL, destPath, found.MergeStrategy)
+		err = gitCloneThenCopy(ctx, *found, destPath)
 	default:
 		return fmt.Errorf("unknown method: %s", method)
 	}
+	if err != nil {
+		return err
+	}
+
+	return runPostClone(ctx, *found, destPath, 1, 1, p, opts)
 }
 
 // CheckExternalStatus returns the status of all external dependencies
@@ -249,6 +752,9 @@ func CheckExternalStatus(cfg *config.Config, p *platform.Platform, repoRoot stri
 	var statuses []ExternalStatus
 
 	for _, ext := range cfg.External {
+		// Report the URL the same way clone/update would use it
+		ext.URL = rewriteURL(ext.URL, cfg.Git)
+
 		status := ExternalStatus{
 			Dep: ext,
 		}
@@ -300,21 +806,39 @@ type ExternalStatus struct {
 	Path   string
 }
 
-// expandPath expands ~ to home directory and resolves @repoRoot
+// ExpandPath expands ~ to the home directory and resolves @repoRoot in
+// an external dependency destination. Exposed for callers (like doctor)
+// that need to inspect destinations without cloning.
+func ExpandPath(path, repoRoot string) (string, error) {
+	return expandPath(path, repoRoot)
+}
+
+// expandPath expands ~, ~user, and $VAR references, and resolves @repoRoot
+// expandPath resolves an external dep destination. Absolute and ~ paths
+// land outside the repo as usual; relative paths resolve against the
+// dotfiles repo root so vendored content ends up inside the repo and
+// gets stowed with everything else.
 func expandPath(path, repoRoot string) (string, error) {
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		path = filepath.Join(home, path[2:])
-	} else if strings.HasPrefix(path, "@repoRoot/") {
+	if strings.HasPrefix(path, "@repoRoot/") {
 		if repoRoot == "" {
 			return "", fmt.Errorf("repoRoot is not set, cannot expand @repoRoot")
 		}
-		path = filepath.Join(repoRoot, path[10:]) // 10 is length of "@repoRoot/"
+		return filepath.Clean(filepath.Join(repoRoot, path[10:])), nil // 10 is length of "@repoRoot/"
+	}
+
+	expanded, err := pathutil.Expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(expanded) {
+		if repoRoot == "" {
+			return "", fmt.Errorf("relative destination %q requires a repo root", path)
+		}
+		return filepath.Clean(filepath.Join(repoRoot, expanded)), nil
 	}
-	return filepath.Clean(path), nil
+
+	return expanded, nil
 }
 
 // checkDestination returns whether the path exists and if it's a git repo
@@ -338,28 +862,68 @@ func checkDestination(path string) (exists bool, isGit bool) {
 	return true, false
 }
 
-// gitClone clones a repository to the destination
-func gitClone(url, dest string) error {
+// gitClone clones a repository to the destination, applying the dep's
+// auth settings (SSH form, pinned key, or token from the environment).
+// Logs and errors only ever see the redacted URL.
+func gitClone(ctx context.Context, ext config.ExternalDep, dest string) error {
 	// Create parent directory if it doesn't exist
 	parentDir := filepath.Dir(dest)
 	if err := os.MkdirAll(parentDir, 0755); err != nil {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	cmd := exec.Command("git", "clone", "--depth", "1", url, dest)
+	url := cloneURL(ext)
+	args := []string{"clone", "--depth", "1"}
+	if ext.Branch != "" {
+		args = append(args, "--branch", ext.Branch)
+	}
+	args = append(args, url, dest)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = gitEnv(ext)
+	args[len(args)-2] = redactURL(url)
+	verbose.Command(append([]string{"git"}, args...)...)
 	cmd.Stdout = nil // Suppress output
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+		return fmt.Errorf("git clone failed for %s: %w", redactURL(url), err)
 	}
 
 	return nil
 }
 
+// gitUpToDate reports whether the local HEAD already matches the remote
+// HEAD (via git ls-remote, no fetch). Any failure degrades to false so
+// the regular pull path still runs.
+func gitUpToDate(ctx context.Context, path string, env []string) bool {
+	localCmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "HEAD")
+	verbose.Command(localCmd.Args...)
+	local, err := localCmd.Output()
+	if err != nil {
+		return false
+	}
+
+	remoteCmd := exec.CommandContext(ctx, "git", "-C", path, "ls-remote", "origin", "HEAD")
+	remoteCmd.Env = env
+	verbose.Command(remoteCmd.Args...)
+	remote, err := remoteCmd.Output()
+	if err != nil {
+		return false
+	}
+
+	fields := strings.Fields(string(remote))
+	if len(fields) == 0 {
+		return false
+	}
+	return fields[0] == strings.TrimSpace(string(local))
+}
+
 // gitPull pulls updates for an existing repository
-func gitPull(path string) error {
-	cmd := exec.Command("git", "-C", path, "pull", "--ff-only")
+func gitPull(ctx context.Context, path string, env []string) error {
+	cmd := exec.CommandContext(ctx, "git", "-C", path, "pull", "--ff-only")
+	cmd.Env = env
+	verbose.Command(cmd.Args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
@@ -372,7 +936,9 @@ func gitPull(path string) error {
 
 // gitCloneThenCopy clones to a temp directory and copies content (removes .git)
 // This is useful for dependencies where you want to own the files
-func gitCloneThenCopy(url, dest, mergeStrategy string) error {
+func gitCloneThenCopy(ctx context.Context, ext config.ExternalDep, dest string) error {
+	mergeStrategy := ext.MergeStrategy
+
 	// Create a temp directory for cloning
 	tmpDir, err := os.MkdirTemp("", "go4dot-clone-*")
 	if err != nil {
@@ -382,7 +948,7 @@ func gitCloneThenCopy(url, dest, mergeStrategy string) error {
 
 	// Clone to temp
 	tmpDest := filepath.Join(tmpDir, "repo")
-	if err := gitClone(url, tmpDest); err != nil {
+	if err := gitClone(ctx, ext, tmpDest); err != nil {
 		return err
 	}
 
@@ -1,12 +1,16 @@
 package deps
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/state"
 )
 
 func TestExpandPath(t *testing.T) {
@@ -36,10 +40,16 @@ func TestExpandPath(t *testing.T) {
 			expected: "/usr/local/bin",
 		},
 		{
-			name:     "Relative path cleaned",
+			name:     "Relative path resolves into the repo",
 			input:    "./foo/../bar",
-			repoRoot: "",
-			expected: "bar",
+			repoRoot: repoRoot,
+			expected: filepath.Join(repoRoot, "bar"),
+		},
+		{
+			name:     "Nested relative destination",
+			input:    "nvim/pack/plugins/start/foo",
+			repoRoot: repoRoot,
+			expected: filepath.Join(repoRoot, "nvim/pack/plugins/start/foo"),
 		},
 		{
 			name:     "Home only",
@@ -66,6 +76,12 @@ func TestExpandPath(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("Relative destination without repo root", func(t *testing.T) {
+		if _, err := expandPath("nvim/pack/foo", ""); err == nil {
+			t.Error("expandPath() should fail for a relative destination without a repo root")
+		}
+	})
 }
 
 func TestCheckCondition(t *testing.T) {
@@ -93,10 +109,19 @@ func TestCheckCondition(t *testing.T) {
 		Distro:         "ubuntu",
 		DistroVersion:  "22.04",
 		IsWSL:          true,
+		WSLVersion:     2,
 		PackageManager: "apt",
 		Architecture:   "amd64",
 	}
 
+	zshPlatform := &platform.Platform{
+		OS:             "linux",
+		Distro:         "fedora",
+		PackageManager: "dnf",
+		Architecture:   "amd64",
+		Shell:          "zsh",
+	}
+
 	tests := []struct {
 		name      string
 		condition map[string]string
@@ -193,6 +218,36 @@ func TestCheckCondition(t *testing.T) {
 			platform:  linuxPlatform,
 			want:      false,
 		},
+		{
+			name:      "WSL version match",
+			condition: map[string]string{"wsl_version": "2"},
+			platform:  wslPlatform,
+			want:      true,
+		},
+		{
+			name:      "WSL version no match",
+			condition: map[string]string{"wsl_version": "1"},
+			platform:  wslPlatform,
+			want:      false,
+		},
+		{
+			name:      "Shell match",
+			condition: map[string]string{"shell": "zsh"},
+			platform:  zshPlatform,
+			want:      true,
+		},
+		{
+			name:      "Shell no match",
+			condition: map[string]string{"shell": "fish"},
+			platform:  zshPlatform,
+			want:      false,
+		},
+		{
+			name:      "Multiple shells comma separated",
+			condition: map[string]string{"shell": "bash,zsh"},
+			platform:  zshPlatform,
+			want:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -384,7 +439,7 @@ func TestCloneExternalDryRun(t *testing.T) {
 		},
 	}
 
-	result, err := CloneExternal(cfg, p, opts)
+	result, err := CloneExternal(context.Background(), cfg, p, opts)
 	if err != nil {
 		t.Fatalf("CloneExternal() error = %v", err)
 	}
@@ -409,6 +464,565 @@ func TestCloneExternalDryRun(t *testing.T) {
 	}
 }
 
+func TestMatchesArch(t *testing.T) {
+	p := &platform.Platform{Architecture: "arm64"}
+
+	tests := []struct {
+		name  string
+		archs []string
+		want  bool
+	}{
+		{"Empty list matches all", nil, true},
+		{"Single match", []string{"arm64"}, true},
+		{"Single no match", []string{"amd64"}, false},
+		{"One of several", []string{"amd64", "arm64"}, true},
+		{"None of several", []string{"amd64", "386"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := platform.MatchesArch(tt.archs, p); got != tt.want {
+				t.Errorf("MatchesArch(%v) = %v, want %v", tt.archs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPostClone(t *testing.T) {
+	ext := config.ExternalDep{
+		Name:      "plugin",
+		PostClone: []string{"touch built.marker"},
+	}
+
+	t.Run("Commands run in destination", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		err := runPostClone(context.Background(), ext, tmpDir, 1, 1, nil, ExternalOptions{})
+		if err != nil {
+			t.Fatalf("runPostClone() failed: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "built.marker")); err != nil {
+			t.Errorf("Post-clone command should have created marker: %v", err)
+		}
+	})
+
+	t.Run("Failure is reported", func(t *testing.T) {
+		failing := config.ExternalDep{
+			Name:      "plugin",
+			PostClone: []string{"exit 1"},
+		}
+
+		err := runPostClone(context.Background(), failing, t.TempDir(), 1, 1, nil, ExternalOptions{})
+		if err == nil {
+			t.Error("runPostClone() should fail for a failing command")
+		}
+	})
+
+	t.Run("DryRun only prints", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		var messages []string
+
+		opts := ExternalOptions{
+			DryRun: true,
+			ProgressFunc: func(current, total int, msg string) {
+				messages = append(messages, msg)
+			},
+		}
+
+		if err := runPostClone(context.Background(), ext, tmpDir, 1, 1, nil, opts); err != nil {
+			t.Fatalf("runPostClone() failed: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "built.marker")); !os.IsNotExist(err) {
+			t.Error("DryRun should not execute commands")
+		}
+		if len(messages) != 1 || !strings.Contains(messages[0], "Would run") {
+			t.Errorf("Expected a 'Would run' message, got %v", messages)
+		}
+	})
+
+	t.Run("SkipPostClone skips", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		opts := ExternalOptions{SkipPostClone: true}
+		if err := runPostClone(context.Background(), ext, tmpDir, 1, 1, nil, opts); err != nil {
+			t.Fatalf("runPostClone() failed: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "built.marker")); !os.IsNotExist(err) {
+			t.Error("SkipPostClone should not execute commands")
+		}
+	})
+
+	t.Run("Optional failure is a warning", func(t *testing.T) {
+		soft := config.ExternalDep{
+			Name:              "plugin",
+			PostClone:         []string{"exit 1", "touch after.marker"},
+			PostCloneOptional: true,
+		}
+		tmpDir := t.TempDir()
+		var messages []string
+
+		opts := ExternalOptions{
+			ProgressFunc: func(current, total int, msg string) {
+				messages = append(messages, msg)
+			},
+		}
+
+		if err := runPostClone(context.Background(), soft, tmpDir, 1, 1, nil, opts); err != nil {
+			t.Fatalf("runPostClone() should tolerate optional failures: %v", err)
+		}
+
+		warned := false
+		for _, msg := range messages {
+			if strings.Contains(msg, "failed (optional)") {
+				warned = true
+			}
+		}
+		if !warned {
+			t.Errorf("Expected a warning message, got %v", messages)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "after.marker")); err != nil {
+			t.Error("Remaining commands should still run after an optional failure")
+		}
+	})
+
+	t.Run("Hook environment is exported", func(t *testing.T) {
+		envDep := config.ExternalDep{
+			Name:      "plugin",
+			PostClone: []string{`printf '%s %s' "$GOPHERDOT_DEST" "$GOPHERDOT_OS" > hook.env`},
+		}
+		tmpDir := t.TempDir()
+		p := &platform.Platform{OS: "linux"}
+
+		if err := runPostClone(context.Background(), envDep, tmpDir, 1, 1, p, ExternalOptions{}); err != nil {
+			t.Fatalf("runPostClone() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "hook.env"))
+		if err != nil {
+			t.Fatalf("Hook should have written its environment: %v", err)
+		}
+		if got, want := string(data), tmpDir+" linux"; got != want {
+			t.Errorf("hook env = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCloneExternalOffline(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// An existing git repo and a missing one
+	existing := filepath.Join(tmpDir, "existing")
+	os.MkdirAll(filepath.Join(existing, ".git"), 0755)
+
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{
+				ID:          "existing",
+				Name:        "Existing Repo",
+				URL:         "https://github.com/example/existing.git",
+				Destination: existing,
+			},
+			{
+				ID:          "missing",
+				Name:        "Missing Repo",
+				URL:         "https://github.com/example/missing.git",
+				Destination: filepath.Join(tmpDir, "missing"),
+			},
+		},
+	}
+
+	p := &platform.Platform{OS: "linux"}
+
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{
+		Update:  true,
+		Offline: true,
+	})
+	if err != nil {
+		t.Fatalf("CloneExternal() failed: %v", err)
+	}
+
+	// Nothing should be cloned, updated, or failed - everything skips
+	if len(result.Cloned) != 0 || len(result.Updated) != 0 || len(result.Failed) != 0 {
+		t.Errorf("offline run should not touch anything: %+v", result)
+	}
+
+	if len(result.Skipped) != 2 {
+		t.Fatalf("len(Skipped) = %d, want 2", len(result.Skipped))
+	}
+	for _, s := range result.Skipped {
+		if s.Reason != "offline" {
+			t.Errorf("Skipped reason for %s = %q, want offline", s.Dep.ID, s.Reason)
+		}
+	}
+}
+
+// testGitRepo initializes a git repo with a single commit and returns its path.
+func testGitRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed, skipping git test")
+	}
+
+	dir := t.TempDir()
+	cmds := [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "--allow-empty", "--quiet", "-m", "initial"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestSortExternalDeps(t *testing.T) {
+	exts := []config.ExternalDep{
+		{ID: "plugin", DependsOn: []string{"theme"}},
+		{ID: "theme"},
+		{ID: "standalone"},
+	}
+
+	ordered, err := sortExternalDeps(exts)
+	if err != nil {
+		t.Fatalf("sortExternalDeps() failed: %v", err)
+	}
+
+	var ids []string
+	for _, ext := range ordered {
+		ids = append(ids, ext.ID)
+	}
+	want := []string{"theme", "plugin", "standalone"}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("order = %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestSortExternalDepsCycle(t *testing.T) {
+	exts := []config.ExternalDep{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+	}
+
+	if _, err := sortExternalDeps(exts); err == nil {
+		t.Error("sortExternalDeps() should fail on a cycle")
+	}
+}
+
+func TestExternalTree(t *testing.T) {
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{ID: "plugin", Name: "Plugin", DependsOn: []string{"theme"}},
+			{ID: "theme", Name: "Theme"},
+			{ID: "standalone", Name: "Standalone"},
+		},
+	}
+
+	nodes, err := ExternalTree(cfg)
+	if err != nil {
+		t.Fatalf("ExternalTree() failed: %v", err)
+	}
+
+	type entry struct {
+		id    string
+		depth int
+	}
+	var got []entry
+	for _, node := range nodes {
+		got = append(got, entry{node.Dep.ID, node.Depth})
+	}
+
+	want := []entry{
+		{"theme", 0},
+		{"plugin", 1},
+		{"standalone", 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tree = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("tree = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestExternalTreeCycle(t *testing.T) {
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{ID: "a", DependsOn: []string{"b"}},
+			{ID: "b", DependsOn: []string{"a"}},
+		},
+	}
+
+	if _, err := ExternalTree(cfg); err == nil {
+		t.Error("ExternalTree() should fail on a cycle")
+	}
+}
+
+func TestCloneExternalSkipsDependents(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{
+				ID:          "broken",
+				Name:        "Broken",
+				URL:         "https://github.com/example/broken.git",
+				Destination: "~no-such-user-g4d/broken",
+			},
+			{
+				ID:          "dependent",
+				Name:        "Dependent",
+				URL:         "https://github.com/example/dependent.git",
+				Destination: filepath.Join(tmpDir, "dependent"),
+				DependsOn:   []string{"broken"},
+			},
+		},
+	}
+
+	p := &platform.Platform{OS: "linux"}
+
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("CloneExternal() failed: %v", err)
+	}
+
+	if len(result.Failed) != 1 || result.Failed[0].Dep.ID != "broken" {
+		t.Fatalf("Failed = %+v, want only broken", result.Failed)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Dep.ID != "dependent" {
+		t.Fatalf("Skipped = %+v, want only dependent", result.Skipped)
+	}
+	if !strings.Contains(result.Skipped[0].Reason, "prerequisite") {
+		t.Errorf("skip reason = %q, want a prerequisite failure", result.Skipped[0].Reason)
+	}
+	if len(result.Cloned) != 0 {
+		t.Errorf("Cloned = %+v, want empty", result.Cloned)
+	}
+}
+
+func TestCloneExternalPrune(t *testing.T) {
+	t.Setenv("GO4DOT_STATE_DIR", t.TempDir())
+	tmpDir := t.TempDir()
+
+	// A stale install recorded in state but dropped from config
+	staleDir := filepath.Join(tmpDir, "stale")
+	os.MkdirAll(staleDir, 0755)
+	os.WriteFile(filepath.Join(staleDir, "plugin.sh"), []byte("echo"), 0644)
+
+	// A kept install that is still in config
+	keptDir := filepath.Join(tmpDir, "kept")
+	os.MkdirAll(filepath.Join(keptDir, ".git"), 0755)
+
+	st := state.New()
+	st.SetExternalDep("stale", staleDir, true)
+	st.SetExternalDep("kept", keptDir, true)
+	if err := st.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{
+				ID:          "kept",
+				Name:        "Kept Repo",
+				URL:         "https://github.com/example/kept.git",
+				Destination: keptDir,
+			},
+		},
+	}
+
+	p := &platform.Platform{OS: "linux"}
+
+	// Offline keeps the update itself from hitting the network; pruning
+	// is purely local and should still run
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{
+		Update:  true,
+		Offline: true,
+		Prune:   true,
+	})
+	if err != nil {
+		t.Fatalf("CloneExternal() failed: %v", err)
+	}
+
+	if len(result.Pruned) != 1 || result.Pruned[0].ID != "stale" {
+		t.Fatalf("Pruned = %+v, want only stale", result.Pruned)
+	}
+
+	if _, err := os.Stat(staleDir); !os.IsNotExist(err) {
+		t.Error("stale install should have been removed from disk")
+	}
+	if _, err := os.Stat(keptDir); err != nil {
+		t.Error("kept install should still exist")
+	}
+
+	// State should only track the kept install now
+	st, err = state.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if _, ok := st.ExternalDeps["stale"]; ok {
+		t.Error("stale entry should be dropped from state")
+	}
+	if _, ok := st.ExternalDeps["kept"]; !ok {
+		t.Error("kept entry should remain in state")
+	}
+}
+
+func TestPruneCandidatesDryRun(t *testing.T) {
+	t.Setenv("GO4DOT_STATE_DIR", t.TempDir())
+	tmpDir := t.TempDir()
+
+	staleDir := filepath.Join(tmpDir, "stale")
+	os.MkdirAll(staleDir, 0755)
+
+	st := state.New()
+	st.SetExternalDep("stale", staleDir, true)
+	st.SetExternalDep("already-gone", filepath.Join(tmpDir, "no-such-dir"), true)
+	if err := st.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	cfg := &config.Config{}
+
+	// Entries whose path no longer exists are not candidates
+	candidates, err := PruneCandidates(cfg)
+	if err != nil {
+		t.Fatalf("PruneCandidates() failed: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "stale" {
+		t.Fatalf("candidates = %+v, want only stale", candidates)
+	}
+
+	// Dry run reports but doesn't delete
+	p := &platform.Platform{OS: "linux"}
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{
+		Update: true,
+		Prune:  true,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("CloneExternal() failed: %v", err)
+	}
+	if len(result.Pruned) != 1 {
+		t.Fatalf("len(Pruned) = %d, want 1", len(result.Pruned))
+	}
+	if _, err := os.Stat(staleDir); err != nil {
+		t.Error("dry run should not delete the stale install")
+	}
+}
+
+func TestGitUpToDate(t *testing.T) {
+	origin := testGitRepo(t)
+
+	// Clone the origin so HEAD matches the remote
+	clone := filepath.Join(t.TempDir(), "clone")
+	if out, err := exec.Command("git", "clone", "--quiet", origin, clone).CombinedOutput(); err != nil {
+		t.Fatalf("git clone failed: %v\n%s", err, out)
+	}
+
+	if !gitUpToDate(context.Background(), clone, nil) {
+		t.Error("gitUpToDate() = false for a fresh clone, want true")
+	}
+
+	// Advance the origin - the clone is now behind
+	cmd := exec.Command("git", "-C", origin, "-c", "user.email=test@example.com", "-c", "user.name=test",
+		"commit", "--allow-empty", "--quiet", "-m", "second")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	if gitUpToDate(context.Background(), clone, nil) {
+		t.Error("gitUpToDate() = true for a stale clone, want false")
+	}
+}
+
+func TestGitUpToDateNotARepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed, skipping git test")
+	}
+
+	// A plain directory can never be "up to date"
+	if gitUpToDate(context.Background(), t.TempDir(), nil) {
+		t.Error("gitUpToDate() = true for a non-repo directory, want false")
+	}
+}
+
+func TestCloneSingleOffline(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{
+				ID:          "plugin",
+				Name:        "Plugin",
+				URL:         "https://github.com/example/plugin.git",
+				Destination: filepath.Join(tmpDir, "plugin"),
+			},
+		},
+	}
+
+	p := &platform.Platform{OS: "linux"}
+
+	err := CloneSingle(context.Background(), cfg, p, "plugin", ExternalOptions{Offline: true})
+	if err == nil {
+		t.Error("CloneSingle() should fail cleanly in offline mode")
+	}
+}
+
+func TestCloneExternalFailFast(t *testing.T) {
+	// Both deps fail path expansion (@repoRoot with no RepoRoot set)
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{
+				ID:          "bad1",
+				Name:        "Bad Dep 1",
+				URL:         "https://github.com/example/repo1.git",
+				Destination: "@repoRoot/one",
+			},
+			{
+				ID:          "bad2",
+				Name:        "Bad Dep 2",
+				URL:         "https://github.com/example/repo2.git",
+				Destination: "@repoRoot/two",
+			},
+		},
+	}
+
+	p := &platform.Platform{
+		OS:             "linux",
+		Distro:         "fedora",
+		PackageManager: "dnf",
+	}
+
+	// Default behavior continues past failures
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{})
+	if err != nil {
+		t.Fatalf("CloneExternal() error = %v", err)
+	}
+	if len(result.Failed) != 2 {
+		t.Errorf("len(Failed) = %d, want 2 without FailFast", len(result.Failed))
+	}
+
+	// FailFast stops at the first failure
+	result, err = CloneExternal(context.Background(), cfg, p, ExternalOptions{FailFast: true})
+	if err != nil {
+		t.Fatalf("CloneExternal() error = %v", err)
+	}
+	if len(result.Failed) != 1 {
+		t.Errorf("len(Failed) = %d, want 1 with FailFast", len(result.Failed))
+	}
+}
+
 func TestCloneExternalSkipsExisting(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -435,7 +1049,7 @@ func TestCloneExternalSkipsExisting(t *testing.T) {
 		PackageManager: "dnf",
 	}
 
-	result, err := CloneExternal(cfg, p, ExternalOptions{})
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{})
 	if err != nil {
 		t.Fatalf("CloneExternal() error = %v", err)
 	}
@@ -469,7 +1083,7 @@ func TestCloneSingleNotFound(t *testing.T) {
 		OS: "linux",
 	}
 
-	err := CloneSingle(cfg, p, "nonexistent", ExternalOptions{})
+	err := CloneSingle(context.Background(), cfg, p, "nonexistent", ExternalOptions{})
 	if err == nil {
 		t.Error("Expected error for nonexistent ID")
 	}
@@ -670,7 +1284,7 @@ func TestEmptyExternalConfig(t *testing.T) {
 		OS: "linux",
 	}
 
-	result, err := CloneExternal(cfg, p, ExternalOptions{})
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{})
 	if err != nil {
 		t.Fatalf("CloneExternal() error = %v", err)
 	}
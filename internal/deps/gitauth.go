@@ -0,0 +1,144 @@
+package deps
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
+)
+
+// gitTokenEnv is the environment variable holding an access token that
+// is rewritten into HTTPS GitHub/GitLab URLs for private repos
+const gitTokenEnv = "GOPHERDOT_GIT_TOKEN"
+
+// knownGitHosts are the hosts whose URLs we know how to rewrite for
+// SSH and token auth
+var knownGitHosts = []string{"github.com", "gitlab.com"}
+
+// rewriteURL applies the config's git settings to a repo URL: explicit
+// prefix rewrites first (first match wins), then the preferred protocol
+// conversion for known hosts.
+func rewriteURL(url string, settings config.GitSettings) string {
+	for _, rw := range settings.URLRewrites {
+		if strings.HasPrefix(url, rw.From) {
+			url = rw.To + strings.TrimPrefix(url, rw.From)
+			break
+		}
+	}
+
+	switch settings.PreferProtocol {
+	case "ssh":
+		if ssh := sshForm(url); ssh != "" {
+			url = ssh
+		}
+	case "https":
+		if https := httpsForm(url); https != "" {
+			url = https
+		}
+	}
+
+	return url
+}
+
+// httpsForm converts an SSH GitHub/GitLab URL to its HTTPS form. URLs
+// already using HTTP(S) pass through; anything else returns "".
+func httpsForm(url string) string {
+	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+		return url
+	}
+
+	for _, host := range knownGitHosts {
+		prefix := "git@" + host + ":"
+		if strings.HasPrefix(url, prefix) {
+			return "https://" + host + "/" + strings.TrimPrefix(url, prefix)
+		}
+	}
+
+	return ""
+}
+
+// cloneURL returns the URL to actually clone for an external dep,
+// applying its SSH preference and the GOPHERDOT_GIT_TOKEN env var for
+// HTTPS GitHub/GitLab URLs. The result may embed a credential - never
+// log it directly, use redactURL for anything user-visible.
+func cloneURL(ext config.ExternalDep) string {
+	if ext.UseSSH || ext.SSHKey != "" {
+		if ssh := sshForm(ext.URL); ssh != "" {
+			return ssh
+		}
+		return ext.URL
+	}
+
+	if token := os.Getenv(gitTokenEnv); token != "" {
+		return tokenURL(ext.URL, token)
+	}
+
+	return ext.URL
+}
+
+// sshForm converts an HTTPS GitHub/GitLab URL to its SSH form. URLs
+// already in SSH form pass through; anything else returns "".
+func sshForm(url string) string {
+	if strings.HasPrefix(url, "git@") || strings.HasPrefix(url, "ssh://") {
+		return url
+	}
+
+	for _, host := range knownGitHosts {
+		prefix := "https://" + host + "/"
+		if !strings.HasPrefix(url, prefix) {
+			continue
+		}
+		repo := strings.TrimSuffix(strings.TrimPrefix(url, prefix), "/")
+		if !strings.HasSuffix(repo, ".git") {
+			repo += ".git"
+		}
+		return "git@" + host + ":" + repo
+	}
+
+	return ""
+}
+
+// tokenURL embeds an access token into HTTPS GitHub/GitLab URLs. Other
+// URLs are returned unchanged.
+func tokenURL(url, token string) string {
+	for _, host := range knownGitHosts {
+		prefix := "https://" + host + "/"
+		if strings.HasPrefix(url, prefix) {
+			return "https://" + token + "@" + host + "/" + strings.TrimPrefix(url, prefix)
+		}
+	}
+	return url
+}
+
+// redactURL strips any userinfo credential from a URL so it can be
+// logged or shown in errors safely
+func redactURL(url string) string {
+	i := strings.Index(url, "://")
+	if i < 0 {
+		return url
+	}
+	rest := url[i+3:]
+	at := strings.Index(rest, "@")
+	if at < 0 {
+		return url
+	}
+	return url[:i+3] + "***@" + rest[at+1:]
+}
+
+// gitEnv returns the environment for an external dep's git commands,
+// adding GIT_SSH_COMMAND when the dep pins an SSH identity file. Returns
+// nil (inherit as-is) when no key is configured.
+func gitEnv(ext config.ExternalDep) []string {
+	if ext.SSHKey == "" {
+		return nil
+	}
+
+	key, err := pathutil.Expand(ext.SSHKey)
+	if err != nil {
+		key = ext.SSHKey
+	}
+
+	return append(os.Environ(), fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes", key))
+}
@@ -0,0 +1,282 @@
+package deps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+)
+
+func TestSSHForm(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "GitHub HTTPS",
+			url:  "https://github.com/user/repo.git",
+			want: "git@github.com:user/repo.git",
+		},
+		{
+			name: "GitHub without .git suffix",
+			url:  "https://github.com/user/repo",
+			want: "git@github.com:user/repo.git",
+		},
+		{
+			name: "GitLab HTTPS",
+			url:  "https://gitlab.com/group/project.git",
+			want: "git@gitlab.com:group/project.git",
+		},
+		{
+			name: "Already SSH",
+			url:  "git@github.com:user/repo.git",
+			want: "git@github.com:user/repo.git",
+		},
+		{
+			name: "ssh scheme passes through",
+			url:  "ssh://git@myhost.example/repo.git",
+			want: "ssh://git@myhost.example/repo.git",
+		},
+		{
+			name: "Unknown host",
+			url:  "https://example.com/repo.git",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sshForm(tt.url); got != tt.want {
+				t.Errorf("sshForm(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "GitHub",
+			url:  "https://github.com/user/repo.git",
+			want: "https://tok123@github.com/user/repo.git",
+		},
+		{
+			name: "GitLab",
+			url:  "https://gitlab.com/group/project.git",
+			want: "https://tok123@gitlab.com/group/project.git",
+		},
+		{
+			name: "Unknown host unchanged",
+			url:  "https://example.com/repo.git",
+			want: "https://example.com/repo.git",
+		},
+		{
+			name: "SSH unchanged",
+			url:  "git@github.com:user/repo.git",
+			want: "git@github.com:user/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenURL(tt.url, "tok123"); got != tt.want {
+				t.Errorf("tokenURL(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "Token stripped",
+			url:  "https://tok123@github.com/user/repo.git",
+			want: "https://***@github.com/user/repo.git",
+		},
+		{
+			name: "No credential",
+			url:  "https://github.com/user/repo.git",
+			want: "https://github.com/user/repo.git",
+		},
+		{
+			name: "SSH form untouched",
+			url:  "git@github.com:user/repo.git",
+			want: "git@github.com:user/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactURL(tt.url)
+			if got != tt.want {
+				t.Errorf("redactURL(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+			if strings.Contains(got, "tok123") {
+				t.Errorf("redactURL(%s) leaked the credential: %q", tt.url, got)
+			}
+		})
+	}
+}
+
+func TestRewriteURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		settings config.GitSettings
+		want     string
+	}{
+		{
+			name:     "No settings",
+			url:      "https://github.com/user/repo.git",
+			settings: config.GitSettings{},
+			want:     "https://github.com/user/repo.git",
+		},
+		{
+			name: "Prefer SSH",
+			url:  "https://github.com/user/repo.git",
+			settings: config.GitSettings{
+				PreferProtocol: "ssh",
+			},
+			want: "git@github.com:user/repo.git",
+		},
+		{
+			name: "Prefer HTTPS",
+			url:  "git@gitlab.com:group/project.git",
+			settings: config.GitSettings{
+				PreferProtocol: "https",
+			},
+			want: "https://gitlab.com/group/project.git",
+		},
+		{
+			name: "Prefix rewrite",
+			url:  "https://github.com/corp/tool.git",
+			settings: config.GitSettings{
+				URLRewrites: []config.URLRewrite{
+					{From: "https://github.com/corp/", To: "git@git.corp.example:mirror/"},
+				},
+			},
+			want: "git@git.corp.example:mirror/tool.git",
+		},
+		{
+			name: "Rewrite then protocol preference",
+			url:  "https://mirror.example/user/repo.git",
+			settings: config.GitSettings{
+				PreferProtocol: "ssh",
+				URLRewrites: []config.URLRewrite{
+					{From: "https://mirror.example/", To: "https://github.com/"},
+				},
+			},
+			want: "git@github.com:user/repo.git",
+		},
+		{
+			name: "Unknown host keeps protocol",
+			url:  "https://example.com/repo.git",
+			settings: config.GitSettings{
+				PreferProtocol: "ssh",
+			},
+			want: "https://example.com/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewriteURL(tt.url, tt.settings); got != tt.want {
+				t.Errorf("rewriteURL(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSForm(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "GitHub SSH",
+			url:  "git@github.com:user/repo.git",
+			want: "https://github.com/user/repo.git",
+		},
+		{
+			name: "Already HTTPS",
+			url:  "https://github.com/user/repo.git",
+			want: "https://github.com/user/repo.git",
+		},
+		{
+			name: "Unknown host",
+			url:  "git@example.com:repo.git",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := httpsForm(tt.url); got != tt.want {
+				t.Errorf("httpsForm(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneURL(t *testing.T) {
+	httpsURL := "https://github.com/user/repo.git"
+
+	// Plain dep, no token: unchanged
+	got := cloneURL(config.ExternalDep{URL: httpsURL})
+	if got != httpsURL {
+		t.Errorf("cloneURL() = %q, want %q", got, httpsURL)
+	}
+
+	// UseSSH rewrites to SSH form
+	got = cloneURL(config.ExternalDep{URL: httpsURL, UseSSH: true})
+	if got != "git@github.com:user/repo.git" {
+		t.Errorf("cloneURL(UseSSH) = %q, want SSH form", got)
+	}
+
+	// An SSH key implies SSH
+	got = cloneURL(config.ExternalDep{URL: httpsURL, SSHKey: "~/.ssh/id_work"})
+	if got != "git@github.com:user/repo.git" {
+		t.Errorf("cloneURL(SSHKey) = %q, want SSH form", got)
+	}
+
+	// Token from the environment is embedded for HTTPS
+	t.Setenv(gitTokenEnv, "tok123")
+	got = cloneURL(config.ExternalDep{URL: httpsURL})
+	if got != "https://tok123@github.com/user/repo.git" {
+		t.Errorf("cloneURL(token) = %q, want token URL", got)
+	}
+
+	// SSH preference wins over the token
+	got = cloneURL(config.ExternalDep{URL: httpsURL, UseSSH: true})
+	if got != "git@github.com:user/repo.git" {
+		t.Errorf("cloneURL(UseSSH+token) = %q, want SSH form", got)
+	}
+}
+
+func TestGitEnv(t *testing.T) {
+	// No key: inherit the environment as-is
+	if env := gitEnv(config.ExternalDep{}); env != nil {
+		t.Errorf("gitEnv() = %v, want nil without a key", env)
+	}
+
+	env := gitEnv(config.ExternalDep{SSHKey: "/keys/id_work"})
+	found := false
+	for _, entry := range env {
+		if entry == "GIT_SSH_COMMAND=ssh -i /keys/id_work -o IdentitiesOnly=yes" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("gitEnv() missing GIT_SSH_COMMAND entry: %v", env)
+	}
+}
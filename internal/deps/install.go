@@ -1,10 +1,15 @@
 package deps
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // InstallResult represents the result of installing dependencies
@@ -24,27 +29,46 @@ type InstallError struct {
 type InstallOptions struct {
 	SkipPrompts  bool                                 // If true, install without asking
 	OnlyMissing  bool                                 // Only install missing deps
+	SkipOptional bool                                 // Don't install optional-tier deps, report them as skipped
 	DryRun       bool                                 // Don't actually install, just report
+	Group        string                               // Limit to this dependency group ("" = all)
+	FailFast     bool                                 // Stop at the first failure instead of continuing
 	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
 }
 
 // Install installs missing dependencies
-func Install(cfg *config.Config, p *platform.Platform, opts InstallOptions) (*InstallResult, error) {
+func Install(ctx context.Context, cfg *config.Config, p *platform.Platform, opts InstallOptions) (*InstallResult, error) {
 	result := &InstallResult{}
 
 	// Check current status
-	checkResult, err := Check(cfg, p)
+	checkResult, err := CheckGroup(cfg, p, opts.Group)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	// Get missing dependencies
 	missing := checkResult.GetMissing()
+	if opts.SkipOptional {
+		missing = checkResult.GetMissingRequired()
+		for _, check := range checkResult.Optional {
+			if check.Status == StatusMissing || check.Status == StatusVersionMismatch {
+				result.Skipped = append(result.Skipped, check.Item)
+			}
+		}
+	}
 	if len(missing) == 0 {
 		return result, nil // Nothing to do
 	}
 
+	// Install prerequisites before the deps that require them
+	missing, err = sortByRequires(missing)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get package manager
+	p.ApplyManagerPreference(cfg.Metadata.PreferredManagers)
+
 	pkgMgr, err := platform.GetPackageManager(p)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package manager: %w", err)
@@ -69,10 +93,55 @@ func Install(cfg *config.Config, p *platform.Platform, opts InstallOptions) (*In
 		}
 	}
 
-	// Install each missing dependency
+	// Validate sudo once up front so the batched call and any follow-up
+	// installs in this run don't each prompt for a password
+	if pkgMgr.NeedsSudo() && !opts.DryRun {
+		refreshSudo(total, opts)
+	}
+
+	// Install everything batchable in one privileged call
+	installed := 0
+	if !opts.DryRun {
+		batch, rest := splitBatchable(missing)
+		if len(batch) > 1 {
+			pkgNames := make([]string, len(batch))
+			for i, check := range batch {
+				pkgNames[i] = resolvePackageName(check.Item, pkgMgr.Name())
+			}
+
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, total, fmt.Sprintf("Running: %s", strings.Join(pkgMgr.InstallCommand(pkgNames...), " ")))
+			}
+
+			if err := pkgMgr.Install(pkgNames...); err != nil {
+				// Fall back to one-at-a-time so failures point at a
+				// specific package
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(0, total, fmt.Sprintf("⚠ Batch install failed (%v), retrying one at a time", err))
+				}
+			} else {
+				for _, check := range batch {
+					installed++
+					result.Installed = append(result.Installed, check.Item)
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(installed, total, fmt.Sprintf("✓ Installed %s", check.Item.Name))
+					}
+				}
+				missing = rest
+			}
+		}
+	}
+
+	// Install each remaining dependency individually
 	for i, depCheck := range missing {
 		dep := depCheck.Item
-		current := i + 1
+		current := installed + i + 1
+
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, InstallError{Item: dep, Error: err})
+			break
+		}
 
 		if opts.ProgressFunc != nil {
 			opts.ProgressFunc(current, total, fmt.Sprintf("Installing %s...", dep.Name))
@@ -83,12 +152,54 @@ func Install(cfg *config.Config, p *platform.Platform, opts InstallOptions) (*In
 			continue
 		}
 
-		// Get package name for this platform
-		pkgName := getPackageNameForPlatform(dep, pkgMgr.Name())
-		if pkgName == "" {
-			pkgName = dep.Name
+		// AUR packages go through a helper instead of pacman itself
+		if dep.Source == "aur" {
+			if err := installAURPackage(pkgMgr, dep); err != nil {
+				result.Failed = append(result.Failed, InstallError{
+					Item:  dep,
+					Error: err,
+				})
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✗ Failed to install %s: %v", dep.Name, err))
+				}
+				if opts.FailFast {
+					break
+				}
+			} else {
+				result.Installed = append(result.Installed, dep)
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✓ Installed %s (AUR)", dep.Name))
+				}
+			}
+			continue
+		}
+
+		// Alternatives: install the first one the package manager accepts
+		if len(dep.AnyOf) > 0 {
+			alt, err := installFirstAlternative(pkgMgr, dep.AnyOf)
+			if err != nil {
+				result.Failed = append(result.Failed, InstallError{
+					Item:  dep,
+					Error: err,
+				})
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✗ Failed to install %s: %v", dep.Name, err))
+				}
+				if opts.FailFast {
+					break
+				}
+			} else {
+				result.Installed = append(result.Installed, dep)
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(current, total, fmt.Sprintf("✓ Installed %s (via %s)", dep.Name, alt))
+				}
+			}
+			continue
 		}
 
+		// Get package name for this platform
+		pkgName := resolvePackageName(dep, pkgMgr.Name())
+
 		// Try to install
 		err := pkgMgr.Install(pkgName)
 		if err != nil {
@@ -99,6 +210,9 @@ func Install(cfg *config.Config, p *platform.Platform, opts InstallOptions) (*In
 			if opts.ProgressFunc != nil {
 				opts.ProgressFunc(current, total, fmt.Sprintf("✗ Failed to install %s: %v", dep.Name, err))
 			}
+			if opts.FailFast {
+				break
+			}
 		} else {
 			result.Installed = append(result.Installed, dep)
 			if opts.ProgressFunc != nil {
@@ -110,6 +224,129 @@ func Install(cfg *config.Config, p *platform.Platform, opts InstallOptions) (*In
 	return result, nil
 }
 
+// sortByRequires orders dependency checks so each dep's requires come
+// before it, keeping config order otherwise. Requirements that aren't in
+// the missing set are ignored - they're either installed already or a
+// validation problem. Cycles are an error.
+func sortByRequires(checks []DependencyCheck) ([]DependencyCheck, error) {
+	index := make(map[string]int, len(checks))
+	for i, check := range checks {
+		index[check.Item.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(checks))
+	ordered := make([]DependencyCheck, 0, len(checks))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle involving '%s'", checks[i].Item.Name)
+		}
+
+		states[i] = visiting
+		for _, name := range checks[i].Item.Requires {
+			if j, ok := index[name]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		ordered = append(ordered, checks[i])
+		return nil
+	}
+
+	for i := range checks {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// splitBatchable separates the deps that can go into a single batched
+// install call from those that need individual handling: any_of deps try
+// alternatives one at a time, and deps with requires must wait until
+// their prerequisites (which all land in the batch or earlier singles)
+// are installed.
+func splitBatchable(checks []DependencyCheck) (batch, rest []DependencyCheck) {
+	for _, check := range checks {
+		if len(check.Item.AnyOf) == 0 && len(check.Item.Requires) == 0 && check.Item.Source == "" {
+			batch = append(batch, check)
+		} else {
+			rest = append(rest, check)
+		}
+	}
+	return batch, rest
+}
+
+// installAURPackage installs a source: aur dependency via an AUR helper
+// (yay, paru). AUR packages only exist on Arch, so anything else is a
+// config error; a missing helper gets a clear message rather than a
+// confusing pacman failure.
+func installAURPackage(pkgMgr platform.PackageManager, dep config.DependencyItem) error {
+	if pkgMgr.Name() != "pacman" {
+		return fmt.Errorf("%s is marked source: aur but this system uses %s; AUR packages require Arch", dep.Name, pkgMgr.Name())
+	}
+
+	aur, err := platform.GetAURManager()
+	if err != nil {
+		return fmt.Errorf("cannot install AUR package %s: %w", dep.Name, err)
+	}
+
+	return aur.Install(resolvePackageName(dep, "pacman"))
+}
+
+// refreshSudo validates the cached sudo credentials so later privileged
+// calls in the same run reuse the timestamp instead of each prompting.
+// Failure is non-fatal - the install calls will prompt (or fail) themselves.
+func refreshSudo(total int, opts InstallOptions) {
+	if opts.ProgressFunc != nil {
+		opts.ProgressFunc(0, total, "Refreshing sudo credentials (may prompt for your password)...")
+	}
+
+	cmd := exec.Command("sudo", "-v")
+	verbose.Command(cmd.Args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil && opts.ProgressFunc != nil {
+		opts.ProgressFunc(0, total, fmt.Sprintf("⚠ Warning: sudo refresh failed: %v", err))
+	}
+}
+
+// resolvePackageName returns the manager-specific package name for a
+// dependency, falling back to the dependency name itself.
+func resolvePackageName(dep config.DependencyItem, manager string) string {
+	if pkgName := getPackageNameForPlatform(dep, manager); pkgName != "" {
+		return pkgName
+	}
+	return dep.Name
+}
+
+// installFirstAlternative tries each any_of alternative in order and returns
+// the name of the first one that installs successfully.
+func installFirstAlternative(pkgMgr platform.PackageManager, alternatives []string) (string, error) {
+	var lastErr error
+	for _, alt := range alternatives {
+		if err := pkgMgr.Install(alt); err != nil {
+			lastErr = err
+			continue
+		}
+		return alt, nil
+	}
+	return "", fmt.Errorf("no alternative could be installed: %w", lastErr)
+}
+
 // getPackageNameForPlatform returns the platform-specific package name
 func getPackageNameForPlatform(dep config.DependencyItem, manager string) string {
 	if dep.Package != nil {
@@ -121,8 +358,8 @@ func getPackageNameForPlatform(dep config.DependencyItem, manager string) string
 }
 
 // InstallMissing is a convenience function that installs only missing dependencies
-func InstallMissing(cfg *config.Config, p *platform.Platform) (*InstallResult, error) {
-	return Install(cfg, p, InstallOptions{
+func InstallMissing(ctx context.Context, cfg *config.Config, p *platform.Platform) (*InstallResult, error) {
+	return Install(ctx, cfg, p, InstallOptions{
 		OnlyMissing: true,
 	})
 }
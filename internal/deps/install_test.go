@@ -0,0 +1,121 @@
+package deps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+)
+
+func checksFor(specs ...config.DependencyItem) []DependencyCheck {
+	checks := make([]DependencyCheck, len(specs))
+	for i, item := range specs {
+		checks[i] = DependencyCheck{Item: item, Status: StatusMissing}
+	}
+	return checks
+}
+
+func TestSortByRequires(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    []DependencyCheck
+		wantOrder []string
+	}{
+		{
+			name: "No requires keeps config order",
+			checks: checksFor(
+				config.DependencyItem{Name: "git"},
+				config.DependencyItem{Name: "stow"},
+			),
+			wantOrder: []string{"git", "stow"},
+		},
+		{
+			name: "Prerequisite moves before dependent",
+			checks: checksFor(
+				config.DependencyItem{Name: "cargo-tool", Requires: []string{"rustup"}},
+				config.DependencyItem{Name: "rustup"},
+			),
+			wantOrder: []string{"rustup", "cargo-tool"},
+		},
+		{
+			name: "Chain resolves in order",
+			checks: checksFor(
+				config.DependencyItem{Name: "c", Requires: []string{"b"}},
+				config.DependencyItem{Name: "b", Requires: []string{"a"}},
+				config.DependencyItem{Name: "a"},
+			),
+			wantOrder: []string{"a", "b", "c"},
+		},
+		{
+			name: "Requirement outside the missing set is ignored",
+			checks: checksFor(
+				config.DependencyItem{Name: "tool", Requires: []string{"already-installed"}},
+			),
+			wantOrder: []string{"tool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := sortByRequires(tt.checks)
+			if err != nil {
+				t.Fatalf("sortByRequires() failed: %v", err)
+			}
+			if len(ordered) != len(tt.wantOrder) {
+				t.Fatalf("got %d checks, want %d", len(ordered), len(tt.wantOrder))
+			}
+			for i, name := range tt.wantOrder {
+				if ordered[i].Item.Name != name {
+					t.Errorf("ordered[%d] = %q, want %q", i, ordered[i].Item.Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitBatchable(t *testing.T) {
+	checks := checksFor(
+		config.DependencyItem{Name: "git"},
+		config.DependencyItem{Name: "editor", AnyOf: []string{"nvim", "vim"}},
+		config.DependencyItem{Name: "cargo-tool", Requires: []string{"rustup"}},
+		config.DependencyItem{Name: "stow"},
+		config.DependencyItem{Name: "helper", Source: "aur"},
+	)
+
+	batch, rest := splitBatchable(checks)
+
+	wantBatch := []string{"git", "stow"}
+	if len(batch) != len(wantBatch) {
+		t.Fatalf("len(batch) = %d, want %d", len(batch), len(wantBatch))
+	}
+	for i, name := range wantBatch {
+		if batch[i].Item.Name != name {
+			t.Errorf("batch[%d] = %q, want %q", i, batch[i].Item.Name, name)
+		}
+	}
+
+	wantRest := []string{"editor", "cargo-tool", "helper"}
+	if len(rest) != len(wantRest) {
+		t.Fatalf("len(rest) = %d, want %d", len(rest), len(wantRest))
+	}
+	for i, name := range wantRest {
+		if rest[i].Item.Name != name {
+			t.Errorf("rest[%d] = %q, want %q", i, rest[i].Item.Name, name)
+		}
+	}
+}
+
+func TestSortByRequiresCycle(t *testing.T) {
+	checks := checksFor(
+		config.DependencyItem{Name: "a", Requires: []string{"b"}},
+		config.DependencyItem{Name: "b", Requires: []string{"a"}},
+	)
+
+	_, err := sortByRequires(checks)
+	if err == nil {
+		t.Fatal("sortByRequires() should fail on a cycle")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("error = %v, want it to mention a cycle", err)
+	}
+}
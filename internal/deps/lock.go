@@ -0,0 +1,191 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/verbose"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the pin file written next to .go4dot.yaml. One machine
+// pins the exact commits it has installed; other machines replay them
+// with `install --from-lock`.
+const LockFileName = "gopherdot.lock"
+
+// LockEntry pins one external dependency to an exact commit
+type LockEntry struct {
+	ID     string `yaml:"id"`
+	URL    string `yaml:"url"`    // Redacted clone URL, for staleness detection
+	Commit string `yaml:"commit"` // Full SHA of the checked-out HEAD
+}
+
+// LockFile records the pinned commits of all external dependencies.
+// Copy-method deps leave no git repo behind and cannot be pinned.
+type LockFile struct {
+	SchemaVersion string      `yaml:"schema_version"`
+	External      []LockEntry `yaml:"external"`
+}
+
+// Entry returns the lock entry for an external dep ID
+func (l *LockFile) Entry(id string) (LockEntry, bool) {
+	for _, e := range l.External {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return LockEntry{}, false
+}
+
+// Verify reports whether the lock still covers the config: every
+// clone-method external must be pinned, and pinned URLs must match what
+// the config would clone today.
+func (l *LockFile) Verify(cfg *config.Config) error {
+	var problems []string
+	for _, ext := range cfg.External {
+		if ext.Method == "copy" {
+			continue
+		}
+
+		entry, ok := l.Entry(ext.ID)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("'%s' is not pinned", ext.ID))
+			continue
+		}
+		if entry.URL != "" && entry.URL != redactURL(rewriteURL(ext.URL, cfg.Git)) {
+			problems = append(problems, fmt.Sprintf("'%s' URL changed since the lock was written", ext.ID))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s is stale relative to config: %s (re-run 'g4d external lock' on the pinning machine)", LockFileName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// lockedEntry resolves an external dep against the lockfile, enforcing
+// the --from-lock contract: clone-method deps must be pinned with a
+// matching URL, copy-method deps can't be replayed at all.
+func lockedEntry(lock *LockFile, ext config.ExternalDep) (LockEntry, error) {
+	if ext.Method == "copy" {
+		return LockEntry{}, fmt.Errorf("method 'copy' cannot be replayed from %s", LockFileName)
+	}
+
+	entry, ok := lock.Entry(ext.ID)
+	if !ok {
+		return LockEntry{}, fmt.Errorf("not pinned in %s, refusing to clone", LockFileName)
+	}
+	if entry.URL != "" && entry.URL != redactURL(ext.URL) {
+		return LockEntry{}, fmt.Errorf("%s pins a different URL for this dep", LockFileName)
+	}
+
+	return entry, nil
+}
+
+// ReadLock loads the lockfile from the dotfiles repo root
+func ReadLock(repoRoot string) (*LockFile, error) {
+	path := filepath.Join(repoRoot, LockFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no %s found in %s (run 'g4d external lock' on a machine with the deps installed)", LockFileName, repoRoot)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var lock LockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFileName, err)
+	}
+
+	return &lock, nil
+}
+
+// WriteLock records the current HEAD of every installed clone-method
+// external dep into the lockfile. Deps that aren't installed as git repos
+// are an error - pinning a partial install would make --from-lock fail
+// elsewhere for unclear reasons.
+func WriteLock(ctx context.Context, cfg *config.Config, repoRoot string) (*LockFile, error) {
+	lock := &LockFile{SchemaVersion: "1"}
+
+	for _, ext := range cfg.External {
+		if ext.Method == "copy" {
+			continue
+		}
+
+		destPath, err := expandPath(ext.Destination, repoRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand path for '%s': %w", ext.ID, err)
+		}
+
+		exists, isGit := checkDestination(destPath)
+		if !exists || !isGit {
+			return nil, fmt.Errorf("cannot pin '%s': %s is not an installed git repo (clone it first)", ext.ID, destPath)
+		}
+
+		commit, err := gitHead(ctx, destPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot pin '%s': %w", ext.ID, err)
+		}
+
+		lock.External = append(lock.External, LockEntry{
+			ID:     ext.ID,
+			URL:    redactURL(rewriteURL(ext.URL, cfg.Git)),
+			Commit: commit,
+		})
+	}
+
+	sort.Slice(lock.External, func(i, j int) bool {
+		return lock.External[i].ID < lock.External[j].ID
+	})
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, LockFileName), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write lockfile: %w", err)
+	}
+
+	return lock, nil
+}
+
+// gitHead returns the full SHA of HEAD in a repo
+func gitHead(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "HEAD")
+	verbose.Command(cmd.Args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD in %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitCheckoutCommit moves a repo to an exact commit, fetching it first so
+// shallow clones work. No-op when HEAD already matches.
+func gitCheckoutCommit(ctx context.Context, path, commit string, env []string) error {
+	if head, err := gitHead(ctx, path); err == nil && head == commit {
+		return nil
+	}
+
+	fetch := exec.CommandContext(ctx, "git", "-C", path, "fetch", "--depth", "1", "origin", commit)
+	fetch.Env = env
+	verbose.Command(fetch.Args...)
+	if err := fetch.Run(); err != nil {
+		return fmt.Errorf("failed to fetch pinned commit %s: %w", commit, err)
+	}
+
+	checkout := exec.CommandContext(ctx, "git", "-C", path, "checkout", "--detach", commit)
+	verbose.Command(checkout.Args...)
+	if err := checkout.Run(); err != nil {
+		return fmt.Errorf("failed to check out pinned commit %s: %w", commit, err)
+	}
+
+	return nil
+}
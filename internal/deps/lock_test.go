@@ -0,0 +1,205 @@
+package deps
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+func TestLockRoundTrip(t *testing.T) {
+	origin := testGitRepo(t)
+	root := t.TempDir()
+
+	// Install the dep the way CloneExternal would
+	dest := filepath.Join(root, "plugins", "foo")
+	if out, err := exec.Command("git", "clone", "--quiet", origin, dest).CombinedOutput(); err != nil {
+		t.Fatalf("git clone failed: %v\n%s", err, out)
+	}
+
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{ID: "foo", Name: "Foo", URL: origin, Destination: "plugins/foo"},
+		},
+	}
+
+	lock, err := WriteLock(context.Background(), cfg, root)
+	if err != nil {
+		t.Fatalf("WriteLock() failed: %v", err)
+	}
+	if len(lock.External) != 1 {
+		t.Fatalf("lock.External = %+v, want one entry", lock.External)
+	}
+
+	head, err := gitHead(context.Background(), dest)
+	if err != nil {
+		t.Fatalf("gitHead() failed: %v", err)
+	}
+	if lock.External[0].Commit != head {
+		t.Errorf("locked commit = %q, want %q", lock.External[0].Commit, head)
+	}
+
+	// Reading it back yields the same pins
+	read, err := ReadLock(root)
+	if err != nil {
+		t.Fatalf("ReadLock() failed: %v", err)
+	}
+	entry, ok := read.Entry("foo")
+	if !ok {
+		t.Fatal("Entry(\"foo\") not found after round trip")
+	}
+	if entry.Commit != head {
+		t.Errorf("round-tripped commit = %q, want %q", entry.Commit, head)
+	}
+
+	if err := read.Verify(cfg); err != nil {
+		t.Errorf("Verify() failed for a fresh lock: %v", err)
+	}
+
+	// Adding an unpinned dep to the config makes the lock stale
+	cfg.External = append(cfg.External, config.ExternalDep{ID: "bar", URL: origin, Destination: "plugins/bar"})
+	if err := read.Verify(cfg); err == nil {
+		t.Error("Verify() should fail when config has an unpinned dep")
+	} else if !strings.Contains(err.Error(), "bar") {
+		t.Errorf("Verify() error = %v, want it to name 'bar'", err)
+	}
+}
+
+func TestReadLockMissing(t *testing.T) {
+	_, err := ReadLock(t.TempDir())
+	if err == nil {
+		t.Fatal("ReadLock() should fail when no lockfile exists")
+	}
+	if !strings.Contains(err.Error(), LockFileName) {
+		t.Errorf("error = %v, want it to mention %s", err, LockFileName)
+	}
+}
+
+func TestLockedEntry(t *testing.T) {
+	lock := &LockFile{External: []LockEntry{
+		{ID: "foo", URL: "https://example.com/foo.git", Commit: "abc123"},
+	}}
+
+	tests := []struct {
+		name    string
+		ext     config.ExternalDep
+		wantErr string
+	}{
+		{
+			name: "Pinned dep resolves",
+			ext:  config.ExternalDep{ID: "foo", URL: "https://example.com/foo.git"},
+		},
+		{
+			name:    "Unpinned dep is refused",
+			ext:     config.ExternalDep{ID: "bar", URL: "https://example.com/bar.git"},
+			wantErr: "not pinned",
+		},
+		{
+			name:    "URL mismatch is refused",
+			ext:     config.ExternalDep{ID: "foo", URL: "https://example.com/other.git"},
+			wantErr: "different URL",
+		},
+		{
+			name:    "Copy method cannot be replayed",
+			ext:     config.ExternalDep{ID: "foo", URL: "https://example.com/foo.git", Method: "copy"},
+			wantErr: "copy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := lockedEntry(lock, tt.ext)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("lockedEntry() failed: %v", err)
+				}
+				if entry.Commit != "abc123" {
+					t.Errorf("Commit = %q, want abc123", entry.Commit)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("lockedEntry() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCloneExternalFromLock(t *testing.T) {
+	origin := testGitRepo(t)
+
+	// Let the local origin serve arbitrary SHA fetches like a hosted
+	// remote would, so pinned checkouts of non-HEAD commits work
+	if out, err := exec.Command("git", "-C", origin, "config", "uploadpack.allowAnySHA1InWant", "true").CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, out)
+	}
+
+	pinned, err := gitHead(context.Background(), origin)
+	if err != nil {
+		t.Fatalf("gitHead() failed: %v", err)
+	}
+
+	// Advance the origin past the pin
+	cmd := exec.Command("git", "-C", origin, "-c", "user.email=test@example.com", "-c", "user.name=test",
+		"commit", "--allow-empty", "--quiet", "-m", "second")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	root := t.TempDir()
+	cfg := &config.Config{
+		External: []config.ExternalDep{
+			{ID: "foo", Name: "Foo", URL: origin, Destination: "plugins/foo"},
+			{ID: "bar", Name: "Bar", URL: origin, Destination: "plugins/bar"},
+		},
+	}
+	lock := &LockFile{External: []LockEntry{
+		{ID: "foo", Commit: pinned},
+	}}
+
+	p := &platform.Platform{OS: "linux"}
+	result, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{
+		Lock:     lock,
+		RepoRoot: root,
+	})
+	if err != nil {
+		t.Fatalf("CloneExternal() failed: %v", err)
+	}
+
+	// The pinned dep was cloned and checked out at the exact commit,
+	// not the origin's newer HEAD
+	if len(result.Cloned) != 1 || result.Cloned[0].ID != "foo" {
+		t.Fatalf("Cloned = %+v, want just foo", result.Cloned)
+	}
+	head, err := gitHead(context.Background(), filepath.Join(root, "plugins", "foo"))
+	if err != nil {
+		t.Fatalf("gitHead() failed: %v", err)
+	}
+	if head != pinned {
+		t.Errorf("cloned HEAD = %q, want pinned %q", head, pinned)
+	}
+
+	// The unpinned dep was refused
+	if len(result.Failed) != 1 || result.Failed[0].Dep.ID != "bar" {
+		t.Fatalf("Failed = %+v, want just bar", result.Failed)
+	}
+	if !strings.Contains(result.Failed[0].Error.Error(), "not pinned") {
+		t.Errorf("error = %v, want 'not pinned'", result.Failed[0].Error)
+	}
+
+	// A second run converges without touching anything
+	again, err := CloneExternal(context.Background(), cfg, p, ExternalOptions{
+		Lock:     lock,
+		RepoRoot: root,
+	})
+	if err != nil {
+		t.Fatalf("CloneExternal() second run failed: %v", err)
+	}
+	if len(again.UpToDate) != 1 || again.UpToDate[0].ID != "foo" {
+		t.Errorf("UpToDate = %+v, want just foo", again.UpToDate)
+	}
+}
@@ -0,0 +1,111 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+// UninstallResult represents the result of uninstalling dependencies
+type UninstallResult struct {
+	Removed []string
+	Failed  []UninstallError
+}
+
+// UninstallError represents a failed uninstallation
+type UninstallError struct {
+	Name  string
+	Error error
+}
+
+// UninstallOptions configures the uninstall behavior
+type UninstallOptions struct {
+	DryRun       bool                                 // Don't actually uninstall, just report
+	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
+}
+
+// Uninstall removes the named dependencies using the platform's package
+// manager. Names are resolved against the config so platform-specific
+// package mappings are honored.
+func Uninstall(ctx context.Context, cfg *config.Config, p *platform.Platform, names []string, opts UninstallOptions) (*UninstallResult, error) {
+	result := &UninstallResult{}
+
+	if len(names) == 0 {
+		return result, nil // Nothing to do
+	}
+
+	// Get package manager
+	p.ApplyManagerPreference(cfg.Metadata.PreferredManagers)
+
+	pkgMgr, err := platform.GetPackageManager(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get package manager: %w", err)
+	}
+
+	if !pkgMgr.IsAvailable() {
+		return nil, fmt.Errorf("package manager %s is not available", pkgMgr.Name())
+	}
+
+	total := len(names)
+	for i, name := range names {
+		current := i + 1
+
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, UninstallError{Name: name, Error: err})
+			break
+		}
+
+		if opts.ProgressFunc != nil {
+			opts.ProgressFunc(current, total, fmt.Sprintf("Removing %s...", name))
+		}
+
+		if opts.DryRun {
+			result.Removed = append(result.Removed, name)
+			continue
+		}
+
+		// Get package name for this platform
+		pkgName := name
+		if dep := findDependency(cfg, name); dep != nil {
+			if mapped := getPackageNameForPlatform(*dep, pkgMgr.Name()); mapped != "" {
+				pkgName = mapped
+			}
+		}
+
+		if err := pkgMgr.Uninstall(pkgName); err != nil {
+			result.Failed = append(result.Failed, UninstallError{
+				Name:  name,
+				Error: err,
+			})
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("✗ Failed to remove %s: %v", name, err))
+			}
+		} else {
+			result.Removed = append(result.Removed, name)
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("✓ Removed %s", name))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// findDependency looks up a dependency by name across all tiers
+func findDependency(cfg *config.Config, name string) *config.DependencyItem {
+	for _, tier := range [][]config.DependencyItem{
+		cfg.Dependencies.Critical,
+		cfg.Dependencies.Core,
+		cfg.Dependencies.Optional,
+	} {
+		for i := range tier {
+			if tier[i].Name == name {
+				return &tier[i]
+			}
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package doctor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,7 +11,9 @@ import (
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
 	"github.com/nvandessel/go4dot/internal/machine"
+	"github.com/nvandessel/go4dot/internal/pathutil"
 	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/setup"
 	"github.com/nvandessel/go4dot/internal/state"
 	"github.com/nvandessel/go4dot/internal/stow"
 )
@@ -92,6 +95,9 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 		Message:     fmt.Sprintf("%s (%s)", p.OS, p.PackageManager),
 	})
 
+	// Step 1.5: Report resolved storage locations
+	result.Checks = append(result.Checks, checkStoragePaths())
+
 	// Step 2: Check stow is installed
 	progress(opts, "Checking GNU stow...")
 	stowCheck := checkStow()
@@ -102,6 +108,12 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 	gitCheck := checkGit()
 	result.Checks = append(result.Checks, gitCheck)
 
+	// Step 3.5: Check the dotfiles repo itself is in a healthy git state
+	if opts.DotfilesPath != "" {
+		progress(opts, "Checking dotfiles repo...")
+		result.Checks = append(result.Checks, checkDotfilesRepo(opts.DotfilesPath))
+	}
+
 	// Step 4: Check dependencies
 	progress(opts, "Checking dependencies...")
 	depsResult, err := deps.Check(cfg, p)
@@ -118,7 +130,26 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 		result.Checks = append(result.Checks, depCheck)
 	}
 
-	// Step 5: Check symlinks
+	// Step 5: Check PATH resolution for installed tools
+	progress(opts, "Checking PATH...")
+	if result.DepsResult != nil {
+		pathCheck := checkPathIssues(result.DepsResult)
+		result.Checks = append(result.Checks, pathCheck)
+	}
+
+	// Step 5.5: Check stowed configs have their declared dependencies
+	progress(opts, "Checking config dependencies...")
+	if result.DepsResult != nil {
+		result.Checks = append(result.Checks, checkConfigDepLinkage(cfg, result.DepsResult))
+	}
+
+	// Step 6: Config-aware sanity checks
+	progress(opts, "Checking config sanity...")
+	if opts.DotfilesPath != "" {
+		result.Checks = append(result.Checks, checkConfigSanity(cfg, p, opts.DotfilesPath)...)
+	}
+
+	// Step 7: Check symlinks
 	progress(opts, "Checking symlinks...")
 	if opts.DotfilesPath != "" && !stowCheck.Status.isError() {
 		symlinkStatus := checkSymlinks(cfg, opts.DotfilesPath)
@@ -134,7 +165,7 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 		})
 	}
 
-	// Step 6: Check external dependencies
+	// Step 8: Check external dependencies
 	progress(opts, "Checking external dependencies...")
 	if len(cfg.External) > 0 {
 		extStatus := deps.CheckExternalStatus(cfg, p, opts.DotfilesPath)
@@ -143,16 +174,23 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 		result.Checks = append(result.Checks, extCheck)
 	}
 
-	// Step 7: Check machine configs
+	// Step 9: Check machine configs
 	progress(opts, "Checking machine configurations...")
 	if len(cfg.MachineConfig) > 0 {
-		machineStatus := machine.CheckMachineConfigStatus(cfg)
+		machineStatus := machine.CheckMachineConfigStatus(cfg, p)
 		result.MachineStatus = machineStatus
 		machineCheck := summarizeMachineCheck(machineStatus)
 		result.Checks = append(result.Checks, machineCheck)
 	}
 
-	// Step 8: Check for unmanaged symlinks
+	// Step 10: Check machine config templates render cleanly
+	progress(opts, "Checking machine config templates...")
+	if len(cfg.MachineConfig) > 0 {
+		templateCheck := checkTemplateRenders(cfg)
+		result.Checks = append(result.Checks, templateCheck)
+	}
+
+	// Step 11: Check for unmanaged symlinks
 	progress(opts, "Checking for unmanaged symlinks...")
 	if opts.DotfilesPath != "" {
 		unmanaged := checkUnmanagedSymlinks(cfg, opts.DotfilesPath)
@@ -175,7 +213,7 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 		}
 	}
 
-	// Step 9: Check for adoption opportunities
+	// Step 12: Check for adoption opportunities
 	progress(opts, "Checking for adoption opportunities...")
 	if opts.DotfilesPath != "" {
 		opportunities := checkAdoptionOpportunities(cfg, opts.DotfilesPath)
@@ -202,6 +240,32 @@ func RunChecks(cfg *config.Config, opts CheckOptions) (*CheckResult, error) {
 	return result, nil
 }
 
+// checkStoragePaths reports where state and caches resolve to under the
+// XDG rules, so users can find (and clean) them
+func checkStoragePaths() Check {
+	check := Check{
+		Name:        "Storage Paths",
+		Description: "Resolved state and cache locations",
+	}
+
+	statePath, err := state.GetStatePath()
+	if err != nil {
+		check.Status = StatusWarning
+		check.Message = fmt.Sprintf("could not resolve state path: %v", err)
+		return check
+	}
+	cacheDir, err := state.GetCacheDir()
+	if err != nil {
+		check.Status = StatusWarning
+		check.Message = fmt.Sprintf("could not resolve cache dir: %v", err)
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("state %s, cache %s", statePath, cacheDir)
+	return check
+}
+
 // checkStow verifies GNU stow is installed
 func checkStow() Check {
 	check := Check{
@@ -216,17 +280,92 @@ func checkStow() Check {
 		return check
 	}
 
-	if err := stow.ValidateStow(); err != nil {
+	version, err := stow.ValidateStow()
+	if err != nil {
 		check.Status = StatusWarning
 		check.Message = fmt.Sprintf("stow validation failed: %v", err)
 		return check
 	}
 
+	if version == "" {
+		check.Status = StatusWarning
+		check.Message = "Installed, but the version could not be determined"
+		return check
+	}
+
+	if !stow.StowVersionAtLeast(version, stow.MinStowVersion) {
+		check.Status = StatusWarning
+		check.Message = fmt.Sprintf("stow %s is older than %s; features like --adopt and --dotfiles may not work", version, stow.MinStowVersion)
+		check.Fix = "Upgrade stow with your package manager"
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("Installed and working (version %s)", version)
+	return check
+}
+
+// checkDotfilesRepo verifies the dotfiles directory is a git repo with a
+// remote and hasn't drifted locally (dirty tree, detached HEAD, behind
+// upstream) - the situations where a later 'g4d update' would conflict
+func checkDotfilesRepo(dotfilesPath string) Check {
+	check := Check{
+		Name:        "Dotfiles Repo",
+		Description: "Git state of the dotfiles directory",
+	}
+
+	// No fetch: doctor stays offline-friendly and uses the last-fetched refs
+	status, err := setup.GitRepoStatus(context.Background(), dotfilesPath, false)
+	if err != nil {
+		check.Status = StatusWarning
+		check.Message = err.Error()
+		check.Fix = "Initialize the dotfiles directory as a git repo and add a remote"
+		return check
+	}
+
+	problems, fixes := repoProblems(status)
+	if len(problems) > 0 {
+		check.Status = StatusWarning
+		check.Message = strings.Join(problems, "; ")
+		check.Fix = strings.Join(fixes, "; ")
+		return check
+	}
+
 	check.Status = StatusOK
-	check.Message = "Installed and working"
+	if status.Upstream != "" {
+		check.Message = fmt.Sprintf("On %s, clean and in sync with %s", status.Branch, status.Upstream)
+	} else {
+		check.Message = fmt.Sprintf("On %s, clean", status.Branch)
+	}
 	return check
 }
 
+// repoProblems maps a repo status to warning messages and matching fixes
+func repoProblems(status *setup.RepoStatus) (problems, fixes []string) {
+	if status.Dirty {
+		problems = append(problems, "uncommitted local changes")
+		fixes = append(fixes, "commit or stash changes")
+	}
+	if status.Branch == "HEAD" {
+		problems = append(problems, "detached HEAD")
+		fixes = append(fixes, "check out a branch")
+	}
+	if status.Upstream == "" {
+		problems = append(problems, "no remote set")
+		fixes = append(fixes, "add one with 'git remote add origin <url>' and 'git push -u origin'")
+		return problems, fixes
+	}
+	if status.Behind > 0 {
+		problems = append(problems, fmt.Sprintf("%d commit(s) behind %s", status.Behind, status.Upstream))
+		fixes = append(fixes, "run 'g4d update' to pull and restow")
+	}
+	if status.Ahead > 0 {
+		problems = append(problems, fmt.Sprintf("%d unpushed commit(s)", status.Ahead))
+		fixes = append(fixes, "push with 'git push'")
+	}
+	return problems, fixes
+}
+
 // checkGit verifies git is installed
 func checkGit() Check {
 	check := Check{
@@ -247,6 +386,314 @@ func checkGit() Check {
 	return check
 }
 
+// checkConfigDepLinkage flags configs that are stowed but whose declared
+// depends_on tools are missing - the symlinks exist but the tool they
+// configure doesn't, so the config is effectively broken.
+func checkConfigDepLinkage(cfg *config.Config, depsResult *deps.CheckResult) Check {
+	check := Check{
+		Name:        "Config Dependencies",
+		Description: "Stowed configs have their declared dependencies",
+	}
+
+	st, err := state.Load()
+	if err != nil || st == nil {
+		check.Status = StatusSkipped
+		check.Message = "No state file; nothing is stowed yet"
+		return check
+	}
+
+	broken := brokenConfigDeps(cfg, depsResult, st.GetInstalledConfigNames())
+	if len(broken) > 0 {
+		check.Status = StatusWarning
+		check.Message = fmt.Sprintf("%d stowed config(s) missing their dependencies: %s", len(broken), strings.Join(broken, "; "))
+		check.Fix = "Run 'g4d deps install' to install the missing tools"
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = "All stowed configs have their dependencies"
+	return check
+}
+
+// brokenConfigDeps returns a description per installed config whose
+// depends_on names are declared in the config but not installed. Names
+// that don't match any declared dependency are left to config validation.
+func brokenConfigDeps(cfg *config.Config, depsResult *deps.CheckResult, installed map[string]bool) []string {
+	depStatus := make(map[string]deps.DepStatus)
+	for _, checks := range [][]deps.DependencyCheck{depsResult.Critical, depsResult.Core, depsResult.Optional} {
+		for _, c := range checks {
+			depStatus[c.Item.Name] = c.Status
+		}
+	}
+
+	var broken []string
+	for _, item := range cfg.GetAllConfigs() {
+		if !installed[item.Name] || len(item.DependsOn) == 0 {
+			continue
+		}
+
+		var missing []string
+		for _, name := range item.DependsOn {
+			status, ok := depStatus[name]
+			if ok && status != deps.StatusInstalled && status != deps.StatusAssumed {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			broken = append(broken, fmt.Sprintf("%s (needs %s)", item.Name, strings.Join(missing, ", ")))
+		}
+	}
+
+	return broken
+}
+
+// checkConfigSanity validates the config against the filesystem and
+// platform: config paths exist, external destinations are writable,
+// dependency package names resolve on this platform's manager, and the
+// dotfiles dir is a git repo so 'g4d update' can work.
+func checkConfigSanity(cfg *config.Config, p *platform.Platform, dotfilesPath string) []Check {
+	var checks []Check
+
+	// Every config path should exist in the dotfiles repo
+	var missingPaths []string
+	for _, item := range cfg.GetAllConfigs() {
+		if _, err := os.Stat(filepath.Join(dotfilesPath, item.Path)); os.IsNotExist(err) {
+			missingPaths = append(missingPaths, fmt.Sprintf("%s (%s)", item.Name, item.Path))
+		}
+	}
+	pathCheck := Check{
+		Name:        "Config Paths",
+		Description: "Config directories exist in dotfiles repo",
+	}
+	if len(missingPaths) > 0 {
+		pathCheck.Status = StatusError
+		pathCheck.Message = fmt.Sprintf("%d config path(s) missing: %s", len(missingPaths), strings.Join(missingPaths, ", "))
+		pathCheck.Fix = "Create the directories or remove the entries from .go4dot.yaml"
+	} else {
+		pathCheck.Status = StatusOK
+		pathCheck.Message = "All config paths exist"
+	}
+	checks = append(checks, pathCheck)
+
+	// External destinations should land somewhere writable
+	if len(cfg.External) > 0 {
+		var unwritable []string
+		for _, ext := range cfg.External {
+			destPath, err := deps.ExpandPath(ext.Destination, dotfilesPath)
+			if err != nil {
+				unwritable = append(unwritable, fmt.Sprintf("%s (%v)", ext.Name, err))
+				continue
+			}
+			if !pathutil.IsWritable(pathutil.NearestExistingDir(filepath.Dir(destPath))) {
+				unwritable = append(unwritable, fmt.Sprintf("%s (%s)", ext.Name, destPath))
+			}
+		}
+		extCheck := Check{
+			Name:        "External Destinations",
+			Description: "External dependency destinations are writable",
+		}
+		if len(unwritable) > 0 {
+			extCheck.Status = StatusWarning
+			extCheck.Message = fmt.Sprintf("%d destination(s) not writable: %s", len(unwritable), strings.Join(unwritable, ", "))
+			extCheck.Fix = "Fix the destination paths or directory permissions"
+		} else {
+			extCheck.Status = StatusOK
+			extCheck.Message = "All destinations writable"
+		}
+		checks = append(checks, extCheck)
+	}
+
+	// Dependencies with explicit package mappings should cover this manager
+	var unmapped []string
+	for _, dep := range cfg.GetAllDependencies() {
+		if len(dep.Package) > 0 {
+			if _, ok := dep.Package[p.PackageManager]; !ok {
+				unmapped = append(unmapped, dep.Name)
+			}
+		}
+	}
+	mapCheck := Check{
+		Name:        "Package Mappings",
+		Description: fmt.Sprintf("Dependencies map to %s package names", p.PackageManager),
+	}
+	if len(unmapped) > 0 {
+		mapCheck.Status = StatusWarning
+		mapCheck.Message = fmt.Sprintf("no %s mapping for: %s (generic name will be used)", p.PackageManager, strings.Join(unmapped, ", "))
+		mapCheck.Fix = fmt.Sprintf("Add a '%s:' entry under each dependency's package map", p.PackageManager)
+	} else {
+		mapCheck.Status = StatusOK
+		mapCheck.Message = "All package mappings resolve"
+	}
+	checks = append(checks, mapCheck)
+
+	// 'g4d update' needs the dotfiles dir to be a git repo
+	gitCheck := Check{
+		Name:        "Dotfiles Repository",
+		Description: "Dotfiles directory is a git repository",
+	}
+	if _, err := os.Stat(filepath.Join(dotfilesPath, ".git")); os.IsNotExist(err) {
+		gitCheck.Status = StatusWarning
+		gitCheck.Message = fmt.Sprintf("%s is not a git repository; 'g4d update' will not work", dotfilesPath)
+		gitCheck.Fix = "Run 'git init' in the dotfiles directory or clone your repo"
+	} else {
+		gitCheck.Status = StatusOK
+		gitCheck.Message = "Dotfiles directory is a git repository"
+	}
+	checks = append(checks, gitCheck)
+
+	return checks
+}
+
+// commonBinDirs lists install locations that are frequently missing
+// from PATH (Homebrew on Apple Silicon, Linuxbrew, per-user bin dirs).
+func commonBinDirs() []string {
+	home, _ := os.UserHomeDir()
+	return []string{
+		"/opt/homebrew/bin",
+		"/usr/local/bin",
+		"/home/linuxbrew/.linuxbrew/bin",
+		"/snap/bin",
+		filepath.Join(home, ".local", "bin"),
+		filepath.Join(home, "bin"),
+		filepath.Join(home, ".cargo", "bin"),
+		filepath.Join(home, "go", "bin"),
+	}
+}
+
+// findOffPathBinary looks for an executable with the given name in the
+// provided directories. Returns the full path if found, "" otherwise.
+func findOffPathBinary(binary string, dirs []string) string {
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, binary)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.Mode()&0111 == 0 {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+// checkPathIssues catches the "I installed it but it still says missing"
+// class of problems: a critical/core dependency whose binary exists in a
+// common install location that isn't on PATH for the current shell.
+func checkPathIssues(result *deps.CheckResult) Check {
+	check := Check{
+		Name:        "PATH Resolution",
+		Description: "Installed tools resolvable from PATH",
+	}
+
+	var issues []string
+	var missingDirs []string
+
+	checks := append(append([]deps.DependencyCheck{}, result.Critical...), result.Core...)
+	for _, dep := range checks {
+		binaries := dep.Item.AnyOf
+		if len(binaries) == 0 {
+			binary := dep.Item.Binary
+			if binary == "" {
+				binary = dep.Item.Name
+			}
+			binaries = []string{binary}
+		}
+
+		switch dep.Status {
+		case deps.StatusInstalled:
+			// Sanity check: the recorded binary should still resolve
+			resolvable := false
+			for _, binary := range binaries {
+				if _, err := exec.LookPath(binary); err == nil {
+					resolvable = true
+					break
+				}
+			}
+			if !resolvable {
+				issues = append(issues, fmt.Sprintf("%s was found at %s but no longer resolves via PATH", dep.Item.Name, dep.InstalledPath))
+			}
+		case deps.StatusMissing:
+			for _, binary := range binaries {
+				if loc := findOffPathBinary(binary, commonBinDirs()); loc != "" {
+					issues = append(issues, fmt.Sprintf("%s is installed at %s but not on PATH", dep.Item.Name, loc))
+					missingDirs = append(missingDirs, filepath.Dir(loc))
+					break
+				}
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		check.Status = StatusWarning
+		check.Message = strings.Join(issues, "; ")
+		if len(missingDirs) > 0 {
+			check.Fix = fmt.Sprintf("Add %s to PATH in your shell profile", strings.Join(missingDirs, ", "))
+		} else {
+			check.Fix = "Check your shell's PATH configuration"
+		}
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = "All installed tools resolve via PATH"
+	return check
+}
+
+// checkTemplateRenders attempts to render every machine config template
+// with its prompts' default values, surfacing templates that fail to
+// parse or execute before 'g4d machine configure' trips over them.
+// Templates with prompts that have no default can't be rendered ahead
+// of time and are reported as needing input rather than as errors.
+func checkTemplateRenders(cfg *config.Config) Check {
+	check := Check{
+		Name:        "Machine Config Templates",
+		Description: "Render templates with default values",
+	}
+
+	var broken []string
+	var brokenIDs []string
+	var needsInput []string
+
+	for i := range cfg.MachineConfig {
+		mc := &cfg.MachineConfig[i]
+
+		values := make(map[string]string)
+		missingDefault := false
+		for _, prompt := range mc.Prompts {
+			if prompt.Default == "" {
+				missingDefault = true
+				break
+			}
+			values[prompt.ID] = prompt.Default
+		}
+		if missingDefault {
+			needsInput = append(needsInput, mc.ID)
+			continue
+		}
+
+		if _, err := machine.PreviewRender(mc, values); err != nil {
+			broken = append(broken, fmt.Sprintf("%s: %v", mc.ID, err))
+			brokenIDs = append(brokenIDs, mc.ID)
+		}
+	}
+
+	if len(broken) > 0 {
+		check.Status = StatusError
+		check.Message = fmt.Sprintf("%d template(s) failed to render: %s", len(broken), strings.Join(broken, "; "))
+		check.Fix = fmt.Sprintf("Run 'g4d machine show %s' to inspect the template", brokenIDs[0])
+		return check
+	}
+
+	check.Status = StatusOK
+	if len(needsInput) > 0 {
+		check.Message = fmt.Sprintf("Templates render cleanly (%d need input to verify: %s)", len(needsInput), strings.Join(needsInput, ", "))
+	} else {
+		check.Message = "All templates render cleanly"
+	}
+	return check
+}
+
 // summarizeDepsCheck creates a check summary from deps check result
 func summarizeDepsCheck(result *deps.CheckResult) Check {
 	check := Check{
@@ -456,13 +903,15 @@ func summarizeMachineCheck(statuses []machine.MachineConfigStatus) Check {
 		Description: "Machine-specific config files",
 	}
 
-	var configured, missing, errors int
+	var configured, missing, skipped, errors int
 	for _, s := range statuses {
 		switch s.Status {
 		case "configured":
 			configured++
 		case "missing":
 			missing++
+		case "skipped":
+			skipped++
 		case "error":
 			errors++
 		}
@@ -483,6 +932,9 @@ func summarizeMachineCheck(statuses []machine.MachineConfigStatus) Check {
 
 	check.Status = StatusOK
 	check.Message = fmt.Sprintf("%d configured", configured)
+	if skipped > 0 {
+		check.Message = fmt.Sprintf("%d configured, %d skipped", configured, skipped)
+	}
 	return check
 }
 
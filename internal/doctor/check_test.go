@@ -3,11 +3,14 @@ package doctor
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
 	"github.com/nvandessel/go4dot/internal/machine"
+	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/setup"
 )
 
 func TestCheckStatusIsError(t *testing.T) {
@@ -219,6 +222,77 @@ func TestSummarizeDepsCheck(t *testing.T) {
 	}
 }
 
+func TestBrokenConfigDeps(t *testing.T) {
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core: []config.ConfigItem{
+				{Name: "nvim", DependsOn: []string{"neovim"}},
+				{Name: "git", DependsOn: []string{"git"}},
+				{Name: "misc"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		depsResult *deps.CheckResult
+		installed  map[string]bool
+		want       []string
+	}{
+		{
+			name: "Stowed config with missing dep",
+			depsResult: &deps.CheckResult{
+				Core: []deps.DependencyCheck{
+					{Item: config.DependencyItem{Name: "neovim"}, Status: deps.StatusMissing},
+					{Item: config.DependencyItem{Name: "git"}, Status: deps.StatusInstalled},
+				},
+			},
+			installed: map[string]bool{"nvim": true, "git": true},
+			want:      []string{"nvim (needs neovim)"},
+		},
+		{
+			name: "Missing dep but config not stowed",
+			depsResult: &deps.CheckResult{
+				Core: []deps.DependencyCheck{
+					{Item: config.DependencyItem{Name: "neovim"}, Status: deps.StatusMissing},
+				},
+			},
+			installed: map[string]bool{"git": true},
+			want:      nil,
+		},
+		{
+			name: "Assumed-installed dep counts as present",
+			depsResult: &deps.CheckResult{
+				Core: []deps.DependencyCheck{
+					{Item: config.DependencyItem{Name: "neovim"}, Status: deps.StatusAssumed},
+				},
+			},
+			installed: map[string]bool{"nvim": true},
+			want:      nil,
+		},
+		{
+			name:       "Depends-on name not a declared dependency",
+			depsResult: &deps.CheckResult{},
+			installed:  map[string]bool{"nvim": true},
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := brokenConfigDeps(cfg, tt.depsResult, tt.installed)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("broken[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
 func TestSummarizeExternalCheck(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -441,6 +515,190 @@ func TestRunChecks(t *testing.T) {
 	}
 }
 
+func TestCheckConfigSanity(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755)
+
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core: []config.ConfigItem{
+				{Name: "git", Path: "git"},
+				{Name: "nvim", Path: "nvim"}, // Missing on disk
+			},
+		},
+		Dependencies: config.Dependencies{
+			Core: []config.DependencyItem{
+				{Name: "bat", Package: map[string]string{"apt": "bat"}}, // No dnf mapping
+				{Name: "git"}, // No explicit mapping, always fine
+			},
+		},
+		External: []config.ExternalDep{
+			{Name: "theme", Destination: "@repoRoot/themes/pure"},
+		},
+	}
+	p := &platform.Platform{OS: "linux", PackageManager: "dnf"}
+
+	checks := checkConfigSanity(cfg, p, tmpDir)
+
+	byName := make(map[string]Check)
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+
+	if c := byName["Config Paths"]; c.Status != StatusError || !strings.Contains(c.Message, "nvim") {
+		t.Errorf("Config Paths check = %+v, want error mentioning nvim", c)
+	}
+	if c := byName["External Destinations"]; c.Status != StatusOK {
+		t.Errorf("External Destinations check = %+v, want OK", c)
+	}
+	if c := byName["Package Mappings"]; c.Status != StatusWarning || !strings.Contains(c.Message, "bat") {
+		t.Errorf("Package Mappings check = %+v, want warning mentioning bat", c)
+	}
+	if c := byName["Dotfiles Repository"]; c.Status != StatusOK {
+		t.Errorf("Dotfiles Repository check = %+v, want OK", c)
+	}
+}
+
+func TestCheckConfigSanityNotGitRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{}
+	p := &platform.Platform{OS: "linux", PackageManager: "dnf"}
+
+	checks := checkConfigSanity(cfg, p, tmpDir)
+
+	for _, c := range checks {
+		if c.Name == "Dotfiles Repository" {
+			if c.Status != StatusWarning {
+				t.Errorf("Status = %v, want %v", c.Status, StatusWarning)
+			}
+			if c.Fix == "" {
+				t.Error("Fix should suggest git init")
+			}
+			return
+		}
+	}
+	t.Error("Dotfiles Repository check not found")
+}
+
+func TestFindOffPathBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// An executable, a non-executable file, and a directory
+	exe := filepath.Join(tmpDir, "mytool")
+	os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "notexec"), []byte("data"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "adir"), 0755)
+
+	tests := []struct {
+		name   string
+		binary string
+		want   string
+	}{
+		{"Executable found", "mytool", exe},
+		{"Non-executable ignored", "notexec", ""},
+		{"Directory ignored", "adir", ""},
+		{"Not present", "ghost", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findOffPathBinary(tt.binary, []string{tmpDir}); got != tt.want {
+				t.Errorf("findOffPathBinary(%s) = %q, want %q", tt.binary, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPathIssues(t *testing.T) {
+	// Installed and resolvable: OK
+	result := &deps.CheckResult{
+		Critical: []deps.DependencyCheck{
+			{
+				Item:          config.DependencyItem{Name: "sh", Binary: "sh"},
+				Status:        deps.StatusInstalled,
+				InstalledPath: "/bin/sh",
+			},
+		},
+		Core: []deps.DependencyCheck{
+			{
+				Item:   config.DependencyItem{Name: "truly-absent-tool"},
+				Status: deps.StatusMissing,
+			},
+		},
+	}
+
+	check := checkPathIssues(result)
+	if check.Status != StatusOK {
+		t.Errorf("Status = %v, want %v (message: %s)", check.Status, StatusOK, check.Message)
+	}
+}
+
+func TestCheckTemplateRenders(t *testing.T) {
+	tests := []struct {
+		name       string
+		machines   []config.MachinePrompt
+		wantStatus CheckStatus
+		wantInMsg  string
+	}{
+		{
+			name: "Valid template",
+			machines: []config.MachinePrompt{
+				{
+					ID:       "git",
+					Prompts:  []config.PromptField{{ID: "name", Default: "Test User"}},
+					Template: "name = {{.name}}",
+				},
+			},
+			wantStatus: StatusOK,
+			wantInMsg:  "render cleanly",
+		},
+		{
+			name: "Broken template",
+			machines: []config.MachinePrompt{
+				{
+					ID:       "git",
+					Prompts:  []config.PromptField{{ID: "name", Default: "Test User"}},
+					Template: "name = {{.name", // Unclosed action
+				},
+			},
+			wantStatus: StatusError,
+			wantInMsg:  "git:",
+		},
+		{
+			name: "Prompt without default",
+			machines: []config.MachinePrompt{
+				{
+					ID:       "ssh",
+					Prompts:  []config.PromptField{{ID: "key", Required: true}},
+					Template: "key = {{.key}}",
+				},
+			},
+			wantStatus: StatusOK,
+			wantInMsg:  "need input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MachineConfig: tt.machines}
+
+			check := checkTemplateRenders(cfg)
+
+			if check.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", check.Status, tt.wantStatus)
+			}
+			if !strings.Contains(check.Message, tt.wantInMsg) {
+				t.Errorf("Message = %q, want it to contain %q", check.Message, tt.wantInMsg)
+			}
+			if tt.wantStatus == StatusError && !strings.Contains(check.Fix, "g4d machine show") {
+				t.Errorf("Fix = %q, want a 'g4d machine show' suggestion", check.Fix)
+			}
+		})
+	}
+}
+
 func TestProgress(t *testing.T) {
 	var received string
 	opts := CheckOptions{
@@ -462,3 +720,57 @@ func TestProgressNoCallback(t *testing.T) {
 	// Should not panic with nil callback
 	progress(opts, "test message")
 }
+
+func TestRepoProblems(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       *setup.RepoStatus
+		wantProblems int
+		wantMention  string
+	}{
+		{
+			name:         "clean and in sync",
+			status:       &setup.RepoStatus{Branch: "main", Upstream: "origin/main"},
+			wantProblems: 0,
+		},
+		{
+			name:         "dirty tree",
+			status:       &setup.RepoStatus{Branch: "main", Upstream: "origin/main", Dirty: true},
+			wantProblems: 1,
+			wantMention:  "uncommitted",
+		},
+		{
+			name:         "no remote",
+			status:       &setup.RepoStatus{Branch: "main"},
+			wantProblems: 1,
+			wantMention:  "no remote",
+		},
+		{
+			name:         "detached and behind",
+			status:       &setup.RepoStatus{Branch: "HEAD", Upstream: "origin/main", Behind: 3},
+			wantProblems: 2,
+			wantMention:  "behind",
+		},
+		{
+			name:         "unpushed commits",
+			status:       &setup.RepoStatus{Branch: "main", Upstream: "origin/main", Ahead: 2},
+			wantProblems: 1,
+			wantMention:  "unpushed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, fixes := repoProblems(tt.status)
+			if len(problems) != tt.wantProblems {
+				t.Fatalf("got %d problems %v, want %d", len(problems), problems, tt.wantProblems)
+			}
+			if len(fixes) != len(problems) {
+				t.Errorf("got %d fixes for %d problems", len(fixes), len(problems))
+			}
+			if tt.wantMention != "" && !strings.Contains(strings.Join(problems, "; "), tt.wantMention) {
+				t.Errorf("problems %v should mention %q", problems, tt.wantMention)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
 	"github.com/nvandessel/go4dot/internal/ui"
 )
 
@@ -16,7 +17,8 @@ func RunInteractiveConfig(cfg *config.Config) {
 	}
 
 	// Show current status
-	statuses := CheckMachineConfigStatus(cfg)
+	p, _ := platform.Detect()
+	statuses := CheckMachineConfigStatus(cfg, p)
 	fmt.Println("\nMachine Configuration Status")
 	fmt.Println("----------------------------")
 
@@ -24,6 +26,10 @@ func RunInteractiveConfig(cfg *config.Config) {
 	options = append(options, huh.NewOption("Configure All", "all"))
 
 	for _, s := range statuses {
+		if s.Status == "skipped" {
+			continue
+		}
+
 		statusIcon := " "
 		if s.Status == "configured" {
 			statusIcon = "+"
@@ -93,7 +99,7 @@ func RunInteractiveConfig(cfg *config.Config) {
 		}
 
 		mc := GetMachineConfigByID(cfg, selected)
-		_, err = RenderAndWrite(mc, result.Values, renderOpts)
+		_, err = RenderAndWrite(cfg, mc, result.Values, renderOpts)
 		if err != nil {
 			ui.Error("Error: %v", err)
 			return
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // PromptResult holds the collected values from prompts
@@ -22,6 +26,13 @@ type PromptOptions struct {
 	Out          io.Writer                            // Output destination (defaults to os.Stdout)
 	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
 	SkipPrompts  bool                                 // Use defaults without prompting
+	Platform     *platform.Platform                   // Used to skip configs whose condition doesn't match (nil = no filtering)
+	Only         []string                             // Limit prompting to these field IDs; others reuse Existing values
+	Existing     map[string]string                    // Previously collected values for fields not re-prompted
+	// scriptedIn records whether In was supplied by the caller before the
+	// os.Stdin default was applied, so interactive-only flows (like the
+	// $EDITOR multiline path) can tell a real terminal from scripted input
+	scriptedIn bool
 }
 
 // CollectMachineConfig prompts the user for all machine-specific values
@@ -29,28 +40,75 @@ func CollectMachineConfig(cfg *config.Config, opts PromptOptions) ([]PromptResul
 	// Set defaults if nil
 	if opts.In == nil {
 		opts.In = os.Stdin
+	} else {
+		opts.scriptedIn = true
 	}
 	if opts.Out == nil {
 		opts.Out = os.Stdout
 	}
 
+	shared, err := collectSharedPrompts(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	var results []PromptResult
 
 	for _, mc := range cfg.MachineConfig {
+		if opts.Platform != nil && !platform.CheckCondition(mc.Condition, opts.Platform) {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, fmt.Sprintf("⊘ Skipping %s (condition not met)", mc.ID))
+			}
+			continue
+		}
+
 		result, err := collectPrompts(mc, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to collect prompts for %s: %w", mc.ID, err)
 		}
+		mergeSharedValues(result.Values, shared)
 		results = append(results, result)
 	}
 
 	return results, nil
 }
 
+// collectSharedPrompts collects the top-level shared_prompts section once
+// so values needed by several configs (git name/email, etc.) are only
+// asked for a single time. Returns nil when no shared prompts are defined.
+func collectSharedPrompts(cfg *config.Config, opts PromptOptions) (map[string]string, error) {
+	if len(cfg.SharedPrompts) == 0 {
+		return nil, nil
+	}
+
+	result, err := collectPrompts(config.MachinePrompt{
+		ID:          "shared",
+		Description: "shared values",
+		Prompts:     cfg.SharedPrompts,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect shared prompts: %w", err)
+	}
+
+	return result.Values, nil
+}
+
+// mergeSharedValues fills in shared values the config didn't collect
+// itself; per-config values win on collision
+func mergeSharedValues(values, shared map[string]string) {
+	for k, v := range shared {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+}
+
 // CollectSingleConfig prompts for a single machine config by ID
 func CollectSingleConfig(cfg *config.Config, id string, opts PromptOptions) (*PromptResult, error) {
 	if opts.In == nil {
 		opts.In = os.Stdin
+	} else {
+		opts.scriptedIn = true
 	}
 	if opts.Out == nil {
 		opts.Out = os.Stdout
@@ -68,10 +126,16 @@ func CollectSingleConfig(cfg *config.Config, id string, opts PromptOptions) (*Pr
 		return nil, fmt.Errorf("machine config '%s' not found", id)
 	}
 
+	shared, err := collectSharedPrompts(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := collectPrompts(*found, opts)
 	if err != nil {
 		return nil, err
 	}
+	mergeSharedValues(result.Values, shared)
 
 	return &result, nil
 }
@@ -92,13 +156,59 @@ func collectPrompts(mc config.MachinePrompt, opts PromptOptions) (PromptResult,
 	var fields []huh.Field
 	valuePointers := make(map[string]interface{})
 
+	// System info is only gathered if a computed prompt needs it
+	var sysInfo *SystemInfo
+
 	for _, prompt := range mc.Prompts {
+		// Partial reconfiguration: fields not named in Only keep their
+		// previously collected value. Fields without one fall through
+		// and are prompted again.
+		if len(opts.Only) > 0 && !fieldListed(opts.Only, prompt.ID) {
+			if v, ok := opts.Existing[prompt.ID]; ok {
+				result.Values[prompt.ID] = v
+				continue
+			}
+		}
+
+		// Computed prompts are pre-filled from detected system info; the
+		// detected value becomes the default and can still be overridden
+		// interactively
+		def := prompt.Default
+		if prompt.Type == "computed" || prompt.Type == "auto" {
+			if sysInfo == nil {
+				sysInfo, _ = GetSystemInfo()
+			}
+			if detected := computedValue(sysInfo, prompt.Source); detected != "" {
+				def = detected
+			}
+		}
+
+		// Password prompts can pull from a secret manager or env var
+		// instead of being typed. Required fields fail hard when the
+		// source can't be resolved; optional ones fall back to the
+		// interactive prompt.
+		if prompt.Type == "password" && prompt.Source != "" {
+			secret, err := secretValue(prompt.Source)
+			if err == nil {
+				result.Values[prompt.ID] = secret
+				continue
+			}
+			if prompt.Required {
+				return result, fmt.Errorf("failed to fetch secret for required field '%s': %w", prompt.ID, err)
+			}
+		}
+
 		// If skipping prompts, just use default/validate
 		if opts.SkipPrompts {
-			if prompt.Required && prompt.Default == "" {
+			if prompt.Required && def == "" {
 				return result, fmt.Errorf("required field '%s' has no default value", prompt.ID)
 			}
-			result.Values[prompt.ID] = prompt.Default
+			if prompt.Type == "number" {
+				if err := numberValidator(prompt)(def); err != nil {
+					return result, fmt.Errorf("default for field '%s': %w", prompt.ID, err)
+				}
+			}
+			result.Values[prompt.ID] = def
 			continue
 		}
 
@@ -139,13 +249,69 @@ func collectPrompts(mc config.MachinePrompt, opts PromptOptions) (PromptResult,
 				fields = append(fields, f)
 			}
 
-		default: // text
-			var val string = prompt.Default
+		case "number":
+			var val string = def
+			valuePointers[prompt.ID] = &val
+
+			// The value stays a string for template compatibility; the
+			// validator guarantees it parses and is in range, and huh
+			// re-prompts until it does
+			fields = append(fields, huh.NewInput().
+				Title(prompt.Prompt).
+				Validate(numberValidator(prompt)).
+				Value(&val))
+
+		case "multiline":
+			// With an editor configured, collect the block there so the
+			// user gets their usual multi-line editing environment; any
+			// editor failure falls back to the inline text area. Scripted
+			// input always uses the text area.
+			if !opts.scriptedIn && editorCommand() != "" {
+				if val, err := editorInput(def); err == nil {
+					if prompt.Required && strings.TrimSpace(val) == "" {
+						return result, fmt.Errorf("required field '%s' is empty", prompt.ID)
+					}
+					result.Values[prompt.ID] = val
+					continue
+				}
+			}
+
+			var val string = def
+			valuePointers[prompt.ID] = &val
+
+			// Multi-line text area; ctrl+e opens $EDITOR for longer
+			// blocks (known_hosts entries, multi-line aliases)
+			f := huh.NewText().
+				Title(prompt.Prompt).
+				Value(&val)
+			if prompt.Required {
+				f.Validate(requiredValidator)
+			}
+			fields = append(fields, f)
+
+		case "password":
+			var val string = def
+			valuePointers[prompt.ID] = &val
+
+			f := huh.NewInput().
+				Title(prompt.Prompt).
+				EchoMode(huh.EchoModePassword).
+				Value(&val)
+			if prompt.Required {
+				f.Validate(requiredValidator)
+			}
+			fields = append(fields, f)
+
+		default: // text, computed
+			var val string = def
 			valuePointers[prompt.ID] = &val
 
 			f := huh.NewInput().
 				Title(prompt.Prompt).
 				Value(&val)
+			if prompt.Secret {
+				f.EchoMode(huh.EchoModePassword)
+			}
 			if prompt.Required {
 				f.Validate(requiredValidator)
 			}
@@ -184,6 +350,126 @@ func collectPrompts(mc config.MachinePrompt, opts PromptOptions) (PromptResult,
 	return result, nil
 }
 
+// computedValue resolves a computed prompt's source from detected system
+// info. Unknown sources and detection failures yield an empty string so
+// the prompt's own default still applies.
+func computedValue(info *SystemInfo, source string) string {
+	if info == nil {
+		return ""
+	}
+
+	switch source {
+	case "hostname":
+		return info.Hostname
+	case "username":
+		return info.Username
+	case "home":
+		return info.HomeDir
+	case "git.name":
+		return info.GitUserName
+	case "git.email":
+		return info.GitEmail
+	case "gpg.key":
+		if keys, _ := DetectGPGKeys(); len(keys) > 0 {
+			return keys[0].KeyID
+		}
+	}
+
+	return ""
+}
+
+// fieldListed reports whether the field ID is in the list
+func fieldListed(fields []string, id string) bool {
+	for _, f := range fields {
+		if f == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretField reports whether a field's value is sensitive: password
+// prompts always are, and any field can opt in via secret: true
+func isSecretField(pf config.PromptField) bool {
+	return pf.Secret || pf.Type == "password"
+}
+
+// NonSecretValues returns the subset of collected values that is safe to
+// persist in the state file: everything except secret fields.
+func NonSecretValues(cfg *config.Config, mc *config.MachinePrompt, values map[string]string) map[string]string {
+	secret := make(map[string]bool)
+	for _, field := range mc.Prompts {
+		if isSecretField(field) {
+			secret[field.ID] = true
+		}
+	}
+	for _, field := range cfg.SharedPrompts {
+		if isSecretField(field) {
+			secret[field.ID] = true
+		}
+	}
+
+	safe := make(map[string]string, len(values))
+	for k, v := range values {
+		if !secret[k] {
+			safe[k] = v
+		}
+	}
+	return safe
+}
+
+// MaskSecretValues returns a copy of values with secret fields replaced
+// by a mask string, for previews and other user-facing output
+func MaskSecretValues(cfg *config.Config, mc *config.MachinePrompt, values map[string]string) map[string]string {
+	secret := make(map[string]bool)
+	for _, field := range mc.Prompts {
+		if isSecretField(field) {
+			secret[field.ID] = true
+		}
+	}
+	for _, field := range cfg.SharedPrompts {
+		if isSecretField(field) {
+			secret[field.ID] = true
+		}
+	}
+
+	masked := make(map[string]string, len(values))
+	for k, v := range values {
+		if secret[k] {
+			masked[k] = "********"
+		} else {
+			masked[k] = v
+		}
+	}
+	return masked
+}
+
+// numberValidator builds a validator for a number-type prompt that
+// checks the input is a whole number within the field's optional
+// min/max bounds. Empty input is only rejected for required fields.
+func numberValidator(field config.PromptField) func(string) error {
+	return func(s string) error {
+		if s == "" {
+			if field.Required {
+				return fmt.Errorf("this field is required")
+			}
+			return nil
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		if field.Min != nil && n < *field.Min {
+			return fmt.Errorf("must be at least %d", *field.Min)
+		}
+		if field.Max != nil && n > *field.Max {
+			return fmt.Errorf("must be at most %d", *field.Max)
+		}
+		return nil
+	}
+}
+
 func requiredValidator(s string) error {
 	if s == "" {
 		return fmt.Errorf("this field is required")
@@ -191,6 +477,56 @@ func requiredValidator(s string) error {
 	return nil
 }
 
+// editorCommand returns the user's configured editor ($VISUAL, then
+// $EDITOR), or "" when none is set
+func editorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	return os.Getenv("EDITOR")
+}
+
+// editorInput opens the configured editor on a temp file seeded with the
+// default content and returns whatever was saved, newlines intact
+func editorInput(def string) (string, error) {
+	editor := editorCommand()
+	if editor == "" {
+		return "", fmt.Errorf("no editor configured (set $EDITOR)")
+	}
+
+	tmp, err := os.CreateTemp("", "gopherdot-prompt-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(def); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to seed temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// $EDITOR may carry flags (e.g. "code --wait")
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], tmp.Name())...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited content: %w", err)
+	}
+
+	return string(data), nil
+}
+
 // GetMachineConfigByID returns a machine config by its ID
 func GetMachineConfigByID(cfg *config.Config, id string) *config.MachinePrompt {
 	for i := range cfg.MachineConfig {
@@ -1,9 +1,13 @@
 package machine
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
 )
 
 func TestCollectMachineConfig(t *testing.T) {
@@ -59,6 +63,54 @@ func TestCollectMachineConfig(t *testing.T) {
 	}
 }
 
+func TestCollectMachineConfigSharedPrompts(t *testing.T) {
+	cfg := &config.Config{
+		SharedPrompts: []config.PromptField{
+			{ID: "git_name", Prompt: "Full name", Type: "text", Default: "Alice"},
+			{ID: "git_email", Prompt: "Email", Type: "text", Default: "alice@example.com"},
+		},
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID:          "gitconfig",
+				Description: "Git configuration",
+				Prompts: []config.PromptField{
+					// Overrides the shared value for this config only
+					{ID: "git_name", Prompt: "Name for git", Type: "text", Default: "Alice (work)"},
+				},
+			},
+			{
+				ID:          "commit-template",
+				Description: "Commit template",
+				Prompts:     []config.PromptField{},
+			},
+		},
+	}
+
+	results, err := CollectMachineConfig(cfg, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("CollectMachineConfig failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	// Per-config value wins over the shared one
+	if got := results[0].Values["git_name"]; got != "Alice (work)" {
+		t.Errorf("Expected per-config git_name to win, got %q", got)
+	}
+	if got := results[0].Values["git_email"]; got != "alice@example.com" {
+		t.Errorf("Expected shared git_email injected, got %q", got)
+	}
+
+	// Configs with no prompts of their own still get shared values
+	if got := results[1].Values["git_name"]; got != "Alice" {
+		t.Errorf("Expected shared git_name injected, got %q", got)
+	}
+	if got := results[1].Values["git_email"]; got != "alice@example.com" {
+		t.Errorf("Expected shared git_email injected, got %q", got)
+	}
+}
+
 func TestCollectSingleConfig(t *testing.T) {
 	cfg := &config.Config{
 		MachineConfig: []config.MachinePrompt{
@@ -99,6 +151,62 @@ func TestCollectSingleConfig(t *testing.T) {
 	}
 }
 
+func TestComputedValue(t *testing.T) {
+	info := &SystemInfo{
+		Username:    "alice",
+		HomeDir:     "/home/alice",
+		Hostname:    "workstation",
+		GitUserName: "Alice Example",
+		GitEmail:    "alice@example.com",
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"Hostname", "hostname", "workstation"},
+		{"Username", "username", "alice"},
+		{"Home", "home", "/home/alice"},
+		{"Git name", "git.name", "Alice Example"},
+		{"Git email", "git.email", "alice@example.com"},
+		{"Unknown source", "bogus", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computedValue(info, tt.source); got != tt.want {
+				t.Errorf("computedValue(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+
+	if got := computedValue(nil, "hostname"); got != "" {
+		t.Errorf("computedValue(nil) = %q, want empty", got)
+	}
+}
+
+func TestCollectPromptsComputed(t *testing.T) {
+	mc := config.MachinePrompt{
+		ID:          "host",
+		Description: "Host config",
+		Prompts: []config.PromptField{
+			{ID: "host_name", Prompt: "Hostname", Type: "computed", Source: "hostname"},
+		},
+	}
+
+	// With SkipPrompts the detected value is used without prompting
+	result, err := collectPrompts(mc, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("collectPrompts failed: %v", err)
+	}
+
+	info, _ := GetSystemInfo()
+	if info != nil && info.Hostname != "" && result.Values["host_name"] != info.Hostname {
+		t.Errorf("host_name = %q, want detected hostname %q", result.Values["host_name"], info.Hostname)
+	}
+}
+
 func TestGetMachineConfigByID(t *testing.T) {
 	cfg := &config.Config{
 		MachineConfig: []config.MachinePrompt{
@@ -145,3 +253,320 @@ func TestListMachineConfigs(t *testing.T) {
 		t.Errorf("Unexpected second item: %+v", list[1])
 	}
 }
+
+func TestCollectMachineConfigCondition(t *testing.T) {
+	cfg := &config.Config{
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID: "everywhere",
+				Prompts: []config.PromptField{
+					{ID: "name", Type: "text", Default: "x"},
+				},
+			},
+			{
+				ID:        "mac-only",
+				Condition: map[string]string{"os": "darwin"},
+				Prompts: []config.PromptField{
+					{ID: "name", Type: "text", Default: "y"},
+				},
+			},
+		},
+	}
+
+	results, err := CollectMachineConfig(cfg, PromptOptions{
+		SkipPrompts: true,
+		Platform:    &platform.Platform{OS: "linux"},
+	})
+	if err != nil {
+		t.Fatalf("CollectMachineConfig() failed: %v", err)
+	}
+
+	if len(results) != 1 || results[0].ID != "everywhere" {
+		t.Errorf("results = %+v, want only everywhere", results)
+	}
+
+	// Without a platform there is no condition filtering
+	results, err = CollectMachineConfig(cfg, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("CollectMachineConfig() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("len(results) = %d, want 2 without platform filtering", len(results))
+	}
+}
+
+func TestCollectSingleConfigOnly(t *testing.T) {
+	cfg := &config.Config{
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID:          "git",
+				Description: "Git configuration",
+				Prompts: []config.PromptField{
+					{ID: "name", Prompt: "Name", Default: "Fresh Name"},
+					{ID: "email", Prompt: "Email", Default: "fresh@example.com"},
+					{ID: "signing", Prompt: "Signing key", Default: "fresh-key"},
+				},
+			},
+		},
+	}
+
+	opts := PromptOptions{
+		SkipPrompts: true,
+		Only:        []string{"email"},
+		Existing: map[string]string{
+			"name": "Stored Name",
+			// no stored value for "signing" - should be collected again
+		},
+	}
+
+	result, err := CollectSingleConfig(cfg, "git", opts)
+	if err != nil {
+		t.Fatalf("CollectSingleConfig failed: %v", err)
+	}
+
+	if result.Values["email"] != "fresh@example.com" {
+		t.Errorf("email = %q, want the re-collected value", result.Values["email"])
+	}
+	if result.Values["name"] != "Stored Name" {
+		t.Errorf("name = %q, want the stored value to carry over", result.Values["name"])
+	}
+	if result.Values["signing"] != "fresh-key" {
+		t.Errorf("signing = %q, want re-collection when no stored value exists", result.Values["signing"])
+	}
+}
+
+func TestNonSecretValues(t *testing.T) {
+	cfg := &config.Config{
+		SharedPrompts: []config.PromptField{
+			{ID: "api_token", Type: "password"},
+		},
+	}
+	mc := &config.MachinePrompt{
+		ID: "git",
+		Prompts: []config.PromptField{
+			{ID: "name", Type: "text"},
+			{ID: "passphrase", Type: "password"},
+		},
+	}
+
+	values := map[string]string{
+		"name":       "Alice",
+		"passphrase": "hunter2",
+		"api_token":  "secret",
+	}
+
+	safe := NonSecretValues(cfg, mc, values)
+
+	if safe["name"] != "Alice" {
+		t.Errorf("name = %q, want it kept", safe["name"])
+	}
+	if _, ok := safe["passphrase"]; ok {
+		t.Error("passphrase should not be persisted")
+	}
+	if _, ok := safe["api_token"]; ok {
+		t.Error("shared password field should not be persisted")
+	}
+}
+
+func TestCollectPromptsMultiline(t *testing.T) {
+	mc := config.MachinePrompt{
+		ID:          "ssh",
+		Description: "SSH config",
+		Prompts: []config.PromptField{
+			{ID: "known_hosts", Prompt: "Known hosts", Type: "multiline",
+				Default: "host1 ssh-ed25519 AAAA\nhost2 ssh-rsa BBBB"},
+		},
+	}
+
+	// With SkipPrompts the default is used verbatim, newlines included
+	result, err := collectPrompts(mc, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("collectPrompts() failed: %v", err)
+	}
+
+	expected := "host1 ssh-ed25519 AAAA\nhost2 ssh-rsa BBBB"
+	if result.Values["known_hosts"] != expected {
+		t.Errorf("known_hosts = %q, want %q", result.Values["known_hosts"], expected)
+	}
+}
+
+func TestCollectMachineConfigMultilineEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script editor not available on Windows")
+	}
+
+	// "Editor" that appends a line, so the collected value proves the
+	// editor really ran instead of the inline text area
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf 'from editor\\n' >> \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	cfg := &config.Config{
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID: "ssh",
+				Prompts: []config.PromptField{
+					{ID: "hosts", Prompt: "Hosts", Type: "multiline", Default: "host1\n"},
+				},
+			},
+		},
+	}
+
+	// No In supplied: the real interactive path, where $EDITOR must win
+	results, err := CollectMachineConfig(cfg, PromptOptions{})
+	if err != nil {
+		t.Fatalf("CollectMachineConfig failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	expected := "host1\nfrom editor\n"
+	if got := results[0].Values["hosts"]; got != expected {
+		t.Errorf("hosts = %q, want %q", got, expected)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorCommand(); got != "" {
+		t.Errorf("editorCommand() = %q, want empty with no editor set", got)
+	}
+
+	t.Setenv("EDITOR", "vim")
+	if got := editorCommand(); got != "vim" {
+		t.Errorf("editorCommand() = %q, want %q", got, "vim")
+	}
+
+	// $VISUAL takes precedence over $EDITOR
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorCommand(); got != "code --wait" {
+		t.Errorf("editorCommand() = %q, want %q", got, "code --wait")
+	}
+}
+
+func TestEditorInput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script editor not available on Windows")
+	}
+
+	// "Editor" that appends a line to the file it's given
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf 'host2 ssh-rsa BBBB\\n' >> \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	got, err := editorInput("host1 ssh-ed25519 AAAA\n")
+	if err != nil {
+		t.Fatalf("editorInput() failed: %v", err)
+	}
+
+	expected := "host1 ssh-ed25519 AAAA\nhost2 ssh-rsa BBBB\n"
+	if got != expected {
+		t.Errorf("editorInput() = %q, want %q", got, expected)
+	}
+}
+
+func TestNumberValidator(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		field   config.PromptField
+		input   string
+		wantErr bool
+	}{
+		{"valid number", config.PromptField{Type: "number"}, "42", false},
+		{"not a number", config.PromptField{Type: "number"}, "abc", true},
+		{"empty optional", config.PromptField{Type: "number"}, "", false},
+		{"empty required", config.PromptField{Type: "number", Required: true}, "", true},
+		{"in range", config.PromptField{Type: "number", Min: intPtr(1), Max: intPtr(8)}, "4", false},
+		{"below min", config.PromptField{Type: "number", Min: intPtr(1)}, "0", true},
+		{"above max", config.PromptField{Type: "number", Max: intPtr(8)}, "9", true},
+		{"negative allowed without min", config.PromptField{Type: "number"}, "-5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := numberValidator(tt.field)(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("numberValidator(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCollectPromptsNumberSkipPrompts(t *testing.T) {
+	four := 4
+	eight := 8
+	mc := config.MachinePrompt{
+		ID: "editor",
+		Prompts: []config.PromptField{
+			{ID: "tab_width", Prompt: "Tab width", Type: "number",
+				Default: "4", Min: &four, Max: &eight},
+		},
+	}
+
+	result, err := collectPrompts(mc, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("collectPrompts() failed: %v", err)
+	}
+	if result.Values["tab_width"] != "4" {
+		t.Errorf("tab_width = %q, want %q", result.Values["tab_width"], "4")
+	}
+
+	// An out-of-range default is rejected instead of silently stored
+	mc.Prompts[0].Default = "12"
+	if _, err := collectPrompts(mc, PromptOptions{SkipPrompts: true}); err == nil {
+		t.Error("expected error for out-of-range default, got nil")
+	}
+}
+
+func TestMaskSecretValues(t *testing.T) {
+	cfg := &config.Config{
+		SharedPrompts: []config.PromptField{
+			{ID: "api_token", Type: "password"},
+		},
+	}
+	mc := &config.MachinePrompt{
+		ID: "git",
+		Prompts: []config.PromptField{
+			{ID: "name", Type: "text"},
+			{ID: "signing_key", Type: "text", Secret: true},
+		},
+	}
+	values := map[string]string{
+		"name":        "Jane Doe",
+		"signing_key": "ABCD1234",
+		"api_token":   "hunter2",
+	}
+
+	masked := MaskSecretValues(cfg, mc, values)
+
+	if masked["name"] != "Jane Doe" {
+		t.Errorf("name = %q, want unmasked", masked["name"])
+	}
+	if masked["signing_key"] != "********" {
+		t.Errorf("signing_key = %q, want masked", masked["signing_key"])
+	}
+	if masked["api_token"] != "********" {
+		t.Errorf("api_token = %q, want masked", masked["api_token"])
+	}
+
+	// NonSecretValues drops the same fields entirely
+	safe := NonSecretValues(cfg, mc, values)
+	if _, ok := safe["signing_key"]; ok {
+		t.Error("NonSecretValues kept secret-flagged field signing_key")
+	}
+	if _, ok := safe["api_token"]; ok {
+		t.Error("NonSecretValues kept password field api_token")
+	}
+}
@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretValue fetches a password prompt's value from its configured
+// source instead of asking the user to type it. Supported schemes:
+//
+//	pass:path/to/secret   - password-store ('pass show', first line)
+//	op://vault/item/field - 1Password CLI ('op read')
+//	env:VARNAME           - environment variable
+//
+// Returns an error when the source can't be resolved so callers can
+// decide whether to fall back to an interactive prompt.
+func secretValue(source string) (string, error) {
+	switch {
+	case strings.HasPrefix(source, "env:"):
+		name := strings.TrimPrefix(source, "env:")
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return val, nil
+
+	case strings.HasPrefix(source, "pass:"):
+		path := strings.TrimPrefix(source, "pass:")
+		out, err := runSecretTool("pass", "show", path)
+		if err != nil {
+			return "", err
+		}
+		// pass may store metadata after the first line
+		if i := strings.IndexByte(out, '\n'); i >= 0 {
+			out = out[:i]
+		}
+		return out, nil
+
+	case strings.HasPrefix(source, "op://"):
+		return runSecretTool("op", "read", source)
+	}
+
+	return "", fmt.Errorf("unknown secret source %q", source)
+}
+
+// runSecretTool shells out to a secret manager CLI. The command and its
+// output are deliberately never logged - the output is a secret.
+func runSecretTool(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,75 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+)
+
+func TestSecretValue(t *testing.T) {
+	t.Setenv("G4D_TEST_SECRET", "hunter2")
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{"Env var", "env:G4D_TEST_SECRET", "hunter2", false},
+		{"Unset env var", "env:G4D_UNSET_SECRET_XYZ", "", true},
+		{"Unknown scheme", "vault:some/path", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretValue(tt.source)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("secretValue(%s) = %q, want error", tt.source, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("secretValue(%s) failed: %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Errorf("secretValue(%s) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectPromptsSecretSource(t *testing.T) {
+	t.Setenv("G4D_TEST_TOKEN", "tok-123")
+
+	mc := config.MachinePrompt{
+		ID: "api",
+		Prompts: []config.PromptField{
+			{ID: "token", Type: "password", Source: "env:G4D_TEST_TOKEN", Required: true},
+		},
+	}
+
+	result, err := collectPrompts(mc, PromptOptions{SkipPrompts: true})
+	if err != nil {
+		t.Fatalf("collectPrompts failed: %v", err)
+	}
+
+	if result.Values["token"] != "tok-123" {
+		t.Errorf("token = %q, want tok-123", result.Values["token"])
+	}
+}
+
+func TestCollectPromptsSecretSourceRequiredFails(t *testing.T) {
+	mc := config.MachinePrompt{
+		ID: "api",
+		Prompts: []config.PromptField{
+			{ID: "token", Type: "password", Source: "env:G4D_MISSING_TOKEN_XYZ", Required: true},
+		},
+	}
+
+	_, err := collectPrompts(mc, PromptOptions{SkipPrompts: true})
+	if err == nil {
+		t.Error("collectPrompts should fail when a required secret source is unresolvable")
+	}
+}
@@ -2,13 +2,20 @@ package machine
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
+	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // RenderResult holds the result of rendering a template
@@ -16,27 +23,37 @@ type RenderResult struct {
 	ID          string
 	Destination string
 	Content     string
+	// Skipped means the destination exists with manual edits and was
+	// left untouched (overwrite-if-managed mode only)
+	Skipped bool
 }
 
 // RenderOptions configures template rendering
 type RenderOptions struct {
-	DryRun       bool                                 // Don't write files, just return content
-	Overwrite    bool                                 // Overwrite existing files
-	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
+	DryRun    bool // Don't write files, just return content
+	Overwrite bool // Overwrite existing files
+	Merge     bool // Merge between sentinel markers instead of overwriting (configs can also opt in via merge: true)
+	// OverwriteIfManaged overwrites an existing file only when its content
+	// matches the hash recorded for the last generated version (i.e. the
+	// user hasn't edited it); hand-edited files are warned about and skipped
+	OverwriteIfManaged bool
+	ManagedHashes      map[string]string                    // Machine config ID -> hash of the last generated content (from state)
+	KeepGoing          bool                                 // Attempt every config, aggregate failures
+	ProgressFunc       func(current, total int, msg string) // Called for progress updates with item counts
 }
 
 // RenderMachineConfig renders a machine config template with the given values
 func RenderMachineConfig(mc *config.MachinePrompt, values map[string]string) (*RenderResult, error) {
-	// Parse the template
-	tmpl, err := template.New(mc.ID).Parse(mc.Template)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
-	}
+	var content string
+	var err error
 
-	// Execute the template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, values); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
+	if mc.TemplateCommand != "" {
+		content, err = renderWithCommand(mc, values)
+	} else {
+		content, err = renderGoTemplate(mc, values)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Expand destination path
@@ -48,12 +65,59 @@ func RenderMachineConfig(mc *config.MachinePrompt, values map[string]string) (*R
 	return &RenderResult{
 		ID:          mc.ID,
 		Destination: dest,
-		Content:     buf.String(),
+		Content:     content,
 	}, nil
 }
 
-// RenderAndWrite renders a template and writes it to the destination
-func RenderAndWrite(mc *config.MachinePrompt, values map[string]string, opts RenderOptions) (*RenderResult, error) {
+// renderGoTemplate renders the template with Go text/template, the default
+func renderGoTemplate(mc *config.MachinePrompt, values map[string]string) (string, error) {
+	tmpl, err := template.New(mc.ID).Parse(mc.Template)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateValues(values)); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderWithCommand pipes the raw template through the config's external
+// command (envsubst, jinja, a custom script) with the collected values
+// exported as environment variables, capturing stdout as the rendered
+// content. Platform facts are exported under platform_* like in Go
+// templating.
+func renderWithCommand(mc *config.MachinePrompt, values map[string]string) (string, error) {
+	parts := strings.Fields(mc.TemplateCommand)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("template_command is empty")
+	}
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdin = strings.NewReader(mc.Template)
+	cmd.Env = os.Environ()
+	for k, v := range templateValues(values) {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("template_command '%s' failed: %w\nOutput: %s", mc.TemplateCommand, err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// RenderAndWrite renders a template and writes it to the destination.
+// The config is consulted for shared prompt definitions so secret shared
+// values tighten the rendered file's permissions; nil is accepted when no
+// shared prompts are in play.
+func RenderAndWrite(cfg *config.Config, mc *config.MachinePrompt, values map[string]string, opts RenderOptions) (*RenderResult, error) {
 	result, err := RenderMachineConfig(mc, values)
 	if err != nil {
 		return nil, err
@@ -71,9 +135,35 @@ func RenderAndWrite(mc *config.MachinePrompt, values map[string]string, opts Ren
 		return result, nil
 	}
 
+	merge := opts.Merge || mc.Merge
+
 	// Check if file exists
-	if _, err := os.Stat(result.Destination); err == nil && !opts.Overwrite {
-		return nil, fmt.Errorf("file already exists: %s (use --overwrite to replace)", result.Destination)
+	if _, err := os.Stat(result.Destination); err == nil && !opts.Overwrite && !merge {
+		if !opts.OverwriteIfManaged {
+			return nil, fmt.Errorf("file already exists: %s (use --overwrite to replace)", result.Destination)
+		}
+		if !destinationUnedited(result.Destination, opts.ManagedHashes[mc.ID]) {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, fmt.Sprintf("⚠ %s has local edits, leaving it untouched (use --overwrite to replace)", result.Destination))
+			}
+			result.Skipped = true
+			return result, nil
+		}
+	}
+
+	// In merge mode only the marker region is ours; everything else in
+	// the file is preserved
+	if merge {
+		merged, err := mergeIntoExisting(mc, result.Destination, result.Content)
+		if err != nil {
+			return nil, err
+		}
+		result.Content = merged
+	}
+
+	// Fail with a clear message before mkdir trips over permissions
+	if err := pathutil.CheckWritable(result.Destination); err != nil {
+		return nil, err
 	}
 
 	// Create parent directory if needed
@@ -82,8 +172,14 @@ func RenderAndWrite(mc *config.MachinePrompt, values map[string]string, opts Ren
 		return nil, fmt.Errorf("failed to create directory %s: %w", parentDir, err)
 	}
 
-	// Write the file
-	if err := os.WriteFile(result.Destination, []byte(result.Content), 0644); err != nil {
+	// Write via a temp file and rename so an interrupt mid-write never
+	// leaves a half-written config at the destination
+	tmp := result.Destination + ".tmp"
+	if err := os.WriteFile(tmp, []byte(result.Content), renderFileMode(cfg, mc)); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp, result.Destination); err != nil {
+		os.Remove(tmp)
 		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -94,28 +190,162 @@ func RenderAndWrite(mc *config.MachinePrompt, values map[string]string, opts Ren
 	return result, nil
 }
 
-// RenderAll renders all machine configs with collected values
+// ContentHash returns the hash used to record generated file content in
+// state, so later runs can tell untouched files from hand-edited ones
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// destinationUnedited reports whether the file at dest still matches the
+// recorded generated-content hash. No recorded hash, a missing file, or
+// any mismatch all count as edited, erring on the side of not clobbering.
+func destinationUnedited(dest, recordedHash string) bool {
+	if recordedHash == "" {
+		return false
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		return false
+	}
+	return ContentHash(string(data)) == recordedHash
+}
+
+// mergeMarkers returns the begin/end sentinel lines for a machine
+// config's managed region, using its comment prefix (default "#")
+func mergeMarkers(mc *config.MachinePrompt) (string, string) {
+	prefix := mc.CommentPrefix
+	if prefix == "" {
+		prefix = "#"
+	}
+	begin := fmt.Sprintf("%s >>> gopherdot:%s >>>", prefix, mc.ID)
+	end := fmt.Sprintf("%s <<< gopherdot:%s <<<", prefix, mc.ID)
+	return begin, end
+}
+
+// mergeIntoExisting reads the destination (if present) and replaces just
+// the managed marker region with the freshly rendered content
+func mergeIntoExisting(mc *config.MachinePrompt, dest, rendered string) (string, error) {
+	var existing string
+	data, err := os.ReadFile(dest)
+	if err == nil {
+		existing = string(data)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read existing file: %w", err)
+	}
+
+	begin, end := mergeMarkers(mc)
+	merged, err := mergeWithMarkers(existing, begin, end, rendered)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge into %s: %w", dest, err)
+	}
+	return merged, nil
+}
+
+// mergeWithMarkers replaces the region between the begin and end marker
+// lines with block, or appends a new marker region when the file has
+// none yet. Content outside the markers is returned untouched.
+func mergeWithMarkers(existing, begin, end, block string) (string, error) {
+	if block != "" && !strings.HasSuffix(block, "\n") {
+		block += "\n"
+	}
+	section := begin + "\n" + block + end + "\n"
+
+	bi := strings.Index(existing, begin)
+	if bi < 0 {
+		if existing != "" && !strings.HasSuffix(existing, "\n") {
+			existing += "\n"
+		}
+		return existing + section, nil
+	}
+
+	ei := strings.Index(existing[bi:], end)
+	if ei < 0 {
+		return "", fmt.Errorf("begin marker found without matching end marker %q", end)
+	}
+
+	tail := existing[bi+ei+len(end):]
+	tail = strings.TrimPrefix(tail, "\n")
+	return existing[:bi] + section + tail, nil
+}
+
+// renderFileMode picks the permissions for a rendered config: owner-only
+// when any of the config's prompts is secret, world-readable otherwise.
+// Shared prompts count too, since their values are merged into every
+// render and any template can reference them.
+func renderFileMode(cfg *config.Config, mc *config.MachinePrompt) os.FileMode {
+	for _, pf := range mc.Prompts {
+		if isSecretField(pf) {
+			return 0600
+		}
+	}
+	if cfg != nil {
+		for _, pf := range cfg.SharedPrompts {
+			if isSecretField(pf) {
+				return 0600
+			}
+		}
+	}
+	return 0644
+}
+
+// RenderAll renders all machine configs with collected values.
+// By default it stops at the first failure; with KeepGoing every config
+// is attempted and the failures are aggregated into one error.
 func RenderAll(cfg *config.Config, results []PromptResult, opts RenderOptions) ([]RenderResult, error) {
 	var rendered []RenderResult
+	var errs []error
 
 	for _, pr := range results {
 		mc := GetMachineConfigByID(cfg, pr.ID)
 		if mc == nil {
-			return nil, fmt.Errorf("machine config '%s' not found", pr.ID)
+			err := fmt.Errorf("machine config '%s' not found", pr.ID)
+			if opts.KeepGoing {
+				errs = append(errs, err)
+				continue
+			}
+			return rendered, err
 		}
 
-		result, err := RenderAndWrite(mc, pr.Values, opts)
+		result, err := RenderAndWrite(cfg, mc, pr.Values, opts)
 		if err != nil {
-			return rendered, fmt.Errorf("failed to render %s: %w", pr.ID, err)
+			err = fmt.Errorf("failed to render %s: %w", pr.ID, err)
+			if opts.KeepGoing {
+				errs = append(errs, err)
+				continue
+			}
+			return rendered, err
 		}
 		rendered = append(rendered, *result)
 	}
 
-	return rendered, nil
+	return rendered, errors.Join(errs...)
+}
+
+// templateValues returns a copy of values with platform facts added under
+// platform_* keys so templates can reference e.g. {{ .platform_shell }}
+// without prompting for them. User-provided values win on collision.
+func templateValues(values map[string]string) map[string]string {
+	merged := make(map[string]string, len(values)+4)
+
+	if p, err := platform.Detect(); err == nil {
+		merged["platform_os"] = p.OS
+		merged["platform_distro"] = p.Distro
+		merged["platform_arch"] = p.Architecture
+		merged["platform_shell"] = p.Shell
+	}
+
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	return merged
 }
 
-// CheckMachineConfigStatus checks if machine config files exist
-func CheckMachineConfigStatus(cfg *config.Config) []MachineConfigStatus {
+// CheckMachineConfigStatus checks if machine config files exist.
+// Configs whose condition doesn't match the platform get status
+// "skipped"; pass a nil platform to skip condition filtering.
+func CheckMachineConfigStatus(cfg *config.Config, p *platform.Platform) []MachineConfigStatus {
 	var statuses []MachineConfigStatus
 
 	for _, mc := range cfg.MachineConfig {
@@ -124,6 +354,12 @@ func CheckMachineConfigStatus(cfg *config.Config) []MachineConfigStatus {
 			Description: mc.Description,
 		}
 
+		if p != nil && !platform.CheckCondition(mc.Condition, p) {
+			status.Status = "skipped"
+			statuses = append(statuses, status)
+			continue
+		}
+
 		dest, err := expandPath(mc.Destination)
 		if err != nil {
 			status.Status = "error"
@@ -154,7 +390,7 @@ type MachineConfigStatus struct {
 	ID          string
 	Description string
 	Destination string
-	Status      string // "configured", "missing", "error"
+	Status      string // "configured", "missing", "skipped", "error"
 	Error       string
 }
 
@@ -192,16 +428,45 @@ func RemoveMachineConfig(mc *config.MachinePrompt, opts RenderOptions) error {
 	return nil
 }
 
+// MigrateDestination removes the file generated at a machine config's
+// previous destination after the configured destination changed, so
+// refactored configs don't leave orphaned files behind. It reports
+// whether the old file was (or, in dry-run mode, would be) removed.
+func MigrateDestination(mc *config.MachinePrompt, oldPath string, opts RenderOptions) (bool, error) {
+	if oldPath == "" {
+		return false, nil
+	}
+
+	oldDest, err := expandPath(oldPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to expand old destination path: %w", err)
+	}
+	newDest, err := expandPath(mc.Destination)
+	if err != nil {
+		return false, fmt.Errorf("failed to expand destination path: %w", err)
+	}
+
+	if oldDest == newDest {
+		return false, nil
+	}
+	if _, err := os.Stat(oldDest); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if opts.DryRun {
+		return true, nil
+	}
+
+	if err := os.Remove(oldDest); err != nil {
+		return false, fmt.Errorf("failed to remove old machine config %s: %w", oldDest, err)
+	}
+
+	return true, nil
+}
+
 // expandPath expands ~ to home directory
 func expandPath(path string) (string, error) {
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		path = filepath.Join(home, path[2:])
-	}
-	return filepath.Clean(path), nil
+	return pathutil.Expand(path)
 }
 
 // ValidateTemplate checks if a template is valid
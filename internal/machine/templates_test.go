@@ -3,9 +3,12 @@ package machine
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
 )
 
 func TestRenderMachineConfig(t *testing.T) {
@@ -43,6 +46,44 @@ func TestRenderMachineConfig(t *testing.T) {
 	}
 }
 
+func TestRenderMachineConfigPlatformValues(t *testing.T) {
+	mc := &config.MachinePrompt{
+		ID:          "shellrc",
+		Destination: "~/.shellrc.local",
+		Template:    "os={{ .platform_os }} shell={{ .platform_shell }}",
+	}
+
+	result, err := RenderMachineConfig(mc, nil)
+	if err != nil {
+		t.Fatalf("RenderMachineConfig failed: %v", err)
+	}
+
+	expected := "os=" + runtime.GOOS + " shell=" + platform.DetectShell()
+	if result.Content != expected {
+		t.Errorf("Content = %q, want %q", result.Content, expected)
+	}
+}
+
+func TestRenderMachineConfigPlatformOverride(t *testing.T) {
+	mc := &config.MachinePrompt{
+		ID:          "shellrc",
+		Destination: "~/.shellrc.local",
+		Template:    "shell={{ .platform_shell }}",
+	}
+
+	// An explicit value wins over the detected one
+	values := map[string]string{"platform_shell": "fish"}
+
+	result, err := RenderMachineConfig(mc, values)
+	if err != nil {
+		t.Fatalf("RenderMachineConfig failed: %v", err)
+	}
+
+	if result.Content != "shell=fish" {
+		t.Errorf("Content = %q, want %q", result.Content, "shell=fish")
+	}
+}
+
 func TestRenderMachineConfigInvalidTemplate(t *testing.T) {
 	mc := &config.MachinePrompt{
 		ID:          "invalid",
@@ -78,7 +119,7 @@ func TestRenderAndWrite(t *testing.T) {
 		},
 	}
 
-	result, err := RenderAndWrite(mc, values, opts)
+	result, err := RenderAndWrite(nil, mc, values, opts)
 	if err != nil {
 		t.Fatalf("RenderAndWrite failed: %v", err)
 	}
@@ -116,7 +157,7 @@ func TestRenderAndWriteDryRun(t *testing.T) {
 		DryRun: true,
 	}
 
-	result, err := RenderAndWrite(mc, nil, opts)
+	result, err := RenderAndWrite(nil, mc, nil, opts)
 	if err != nil {
 		t.Fatalf("RenderAndWrite failed: %v", err)
 	}
@@ -150,7 +191,7 @@ func TestRenderAndWriteExistingFileNoOverwrite(t *testing.T) {
 		Overwrite: false,
 	}
 
-	_, err := RenderAndWrite(mc, nil, opts)
+	_, err := RenderAndWrite(nil, mc, nil, opts)
 	if err == nil {
 		t.Error("Expected error when file exists and overwrite is false")
 	}
@@ -175,7 +216,7 @@ func TestRenderAndWriteExistingFileWithOverwrite(t *testing.T) {
 		Overwrite: true,
 	}
 
-	result, err := RenderAndWrite(mc, nil, opts)
+	result, err := RenderAndWrite(nil, mc, nil, opts)
 	if err != nil {
 		t.Fatalf("RenderAndWrite failed: %v", err)
 	}
@@ -194,6 +235,81 @@ func TestRenderAndWriteExistingFileWithOverwrite(t *testing.T) {
 	}
 }
 
+func TestRenderAndWriteOverwriteIfManaged(t *testing.T) {
+	newConfig := func(dest string) *config.MachinePrompt {
+		return &config.MachinePrompt{
+			ID:          "test",
+			Destination: dest,
+			Template:    "generated v{{ .version }}",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		existing    string // Content already at the destination
+		recordHash  string // What the hash ledger says was generated last ("old" = hash of existing)
+		wantSkipped bool
+		wantContent string
+	}{
+		{
+			name:        "Untouched file is updated",
+			existing:    "generated v1",
+			recordHash:  "old",
+			wantSkipped: false,
+			wantContent: "generated v2",
+		},
+		{
+			name:        "Hand-edited file is skipped",
+			existing:    "generated v1 plus my edits",
+			recordHash:  "old",
+			wantSkipped: true,
+			wantContent: "generated v1 plus my edits",
+		},
+		{
+			name:        "No recorded hash is treated as edited",
+			existing:    "generated v1",
+			recordHash:  "",
+			wantSkipped: true,
+			wantContent: "generated v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destPath := filepath.Join(t.TempDir(), "config.txt")
+			if err := os.WriteFile(destPath, []byte(tt.existing), 0644); err != nil {
+				t.Fatalf("Failed to seed destination: %v", err)
+			}
+
+			hash := tt.recordHash
+			if hash == "old" {
+				hash = ContentHash("generated v1")
+			}
+
+			opts := RenderOptions{
+				OverwriteIfManaged: true,
+				ManagedHashes:      map[string]string{"test": hash},
+			}
+
+			result, err := RenderAndWrite(nil, newConfig(destPath), map[string]string{"version": "2"}, opts)
+			if err != nil {
+				t.Fatalf("RenderAndWrite failed: %v", err)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Errorf("Skipped = %v, want %v", result.Skipped, tt.wantSkipped)
+			}
+
+			content, err := os.ReadFile(destPath)
+			if err != nil {
+				t.Fatalf("Failed to read destination: %v", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("File content = %q, want %q", string(content), tt.wantContent)
+			}
+		})
+	}
+}
+
 func TestCheckMachineConfigStatus(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -218,7 +334,7 @@ func TestCheckMachineConfigStatus(t *testing.T) {
 		},
 	}
 
-	statuses := CheckMachineConfigStatus(cfg)
+	statuses := CheckMachineConfigStatus(cfg, nil)
 
 	if len(statuses) != 2 {
 		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
@@ -360,6 +476,46 @@ func TestValidateTemplate(t *testing.T) {
 	}
 }
 
+func TestRenderAllKeepGoing(t *testing.T) {
+	cfg := &config.Config{
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID:          "broken",
+				Destination: "~/broken.conf",
+				Template:    "{{.value", // Unclosed action
+			},
+			{
+				ID:          "good",
+				Destination: "~/good.conf",
+				Template:    "value = {{.value}}",
+			},
+		},
+	}
+
+	results := []PromptResult{
+		{ID: "broken", Values: map[string]string{"value": "x"}},
+		{ID: "good", Values: map[string]string{"value": "y"}},
+	}
+
+	// Default: stop at the first failure
+	rendered, err := RenderAll(cfg, results, RenderOptions{DryRun: true})
+	if err == nil {
+		t.Error("RenderAll() should fail on broken template")
+	}
+	if len(rendered) != 0 {
+		t.Errorf("len(rendered) = %d, want 0 without KeepGoing", len(rendered))
+	}
+
+	// KeepGoing: remaining configs are still attempted
+	rendered, err = RenderAll(cfg, results, RenderOptions{DryRun: true, KeepGoing: true})
+	if err == nil {
+		t.Error("RenderAll() should still report the broken template")
+	}
+	if len(rendered) != 1 || rendered[0].ID != "good" {
+		t.Errorf("rendered = %+v, want only 'good'", rendered)
+	}
+}
+
 func TestPreviewRender(t *testing.T) {
 	mc := &config.MachinePrompt{
 		ID:          "test",
@@ -465,3 +621,321 @@ func TestRenderAll(t *testing.T) {
 		t.Errorf("Config2 content mismatch: got %q", string(content2))
 	}
 }
+
+func TestCheckMachineConfigStatusCondition(t *testing.T) {
+	cfg := &config.Config{
+		MachineConfig: []config.MachinePrompt{
+			{
+				ID:          "everywhere",
+				Description: "No condition",
+				Destination: "/tmp/does-not-exist-g4d",
+			},
+			{
+				ID:          "mac-only",
+				Description: "macOS only",
+				Destination: "/tmp/does-not-exist-g4d",
+				Condition:   map[string]string{"os": "darwin"},
+			},
+		},
+	}
+
+	p := &platform.Platform{OS: "linux"}
+	statuses := CheckMachineConfigStatus(cfg, p)
+
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+
+	byID := make(map[string]MachineConfigStatus)
+	for _, s := range statuses {
+		byID[s.ID] = s
+	}
+
+	if byID["everywhere"].Status != "missing" {
+		t.Errorf("everywhere status = %s, want missing", byID["everywhere"].Status)
+	}
+	if byID["mac-only"].Status != "skipped" {
+		t.Errorf("mac-only status = %s, want skipped", byID["mac-only"].Status)
+	}
+}
+
+func TestRenderMachineConfigTemplateCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	// A stand-in for envsubst/jinja: reads the template from stdin and
+	// substitutes one env var
+	script := filepath.Join(t.TempDir(), "render.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsed \"s/__NAME__/$user_name/\"\n"), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	mc := &config.MachinePrompt{
+		ID:              "git",
+		Destination:     "~/.gitconfig.local",
+		Template:        "name = __NAME__",
+		TemplateCommand: "sh " + script,
+	}
+
+	result, err := RenderMachineConfig(mc, map[string]string{"user_name": "Jane Doe"})
+	if err != nil {
+		t.Fatalf("RenderMachineConfig failed: %v", err)
+	}
+
+	if result.Content != "name = Jane Doe" {
+		t.Errorf("Content = %q, want the command's stdout", result.Content)
+	}
+}
+
+func TestRenderMachineConfigTemplateCommandFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	mc := &config.MachinePrompt{
+		ID:              "broken",
+		Destination:     "~/.broken",
+		Template:        "content",
+		TemplateCommand: "false",
+	}
+
+	if _, err := RenderMachineConfig(mc, nil); err == nil {
+		t.Error("expected error when template_command fails")
+	}
+}
+
+func TestRenderMachineConfigMultilineValue(t *testing.T) {
+	mc := &config.MachinePrompt{
+		ID:          "ssh",
+		Destination: "~/.ssh/known_hosts",
+		Template:    "{{.known_hosts}}\n",
+	}
+	values := map[string]string{
+		"known_hosts": "host1 ssh-ed25519 AAAA\nhost2 ssh-rsa BBBB",
+	}
+
+	result, err := RenderMachineConfig(mc, values)
+	if err != nil {
+		t.Fatalf("RenderMachineConfig() failed: %v", err)
+	}
+
+	expected := "host1 ssh-ed25519 AAAA\nhost2 ssh-rsa BBBB\n"
+	if result.Content != expected {
+		t.Errorf("Content = %q, want %q", result.Content, expected)
+	}
+}
+
+func TestRenderAndWriteSecretPerms(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not meaningful on Windows")
+	}
+
+	dest := filepath.Join(t.TempDir(), "credentials")
+	mc := &config.MachinePrompt{
+		ID:          "creds",
+		Destination: dest,
+		Template:    "token = {{.token}}\n",
+		Prompts: []config.PromptField{
+			{ID: "token", Type: "text", Secret: true},
+		},
+	}
+
+	_, err := RenderAndWrite(nil, mc, map[string]string{"token": "hunter2"}, RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderAndWrite() failed: %v", err)
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("rendered file mode = %o, want 0600", perm)
+	}
+}
+
+func TestRenderAndWriteSharedSecretPerms(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not meaningful on Windows")
+	}
+
+	dest := filepath.Join(t.TempDir(), "netrc")
+	cfg := &config.Config{
+		SharedPrompts: []config.PromptField{
+			{ID: "api_token", Type: "password"},
+		},
+	}
+	mc := &config.MachinePrompt{
+		ID:          "netrc",
+		Destination: dest,
+		Template:    "password {{.api_token}}\n",
+	}
+
+	_, err := RenderAndWrite(cfg, mc, map[string]string{"api_token": "hunter2"}, RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderAndWrite() failed: %v", err)
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("rendered file mode = %o, want 0600 for shared secret prompt", perm)
+	}
+}
+
+func TestMergeWithMarkers(t *testing.T) {
+	begin := "# >>> gopherdot:git >>>"
+	end := "# <<< gopherdot:git <<<"
+
+	tests := []struct {
+		name     string
+		existing string
+		block    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "empty file gets a fresh region",
+			existing: "",
+			block:    "email = a@b.c\n",
+			expected: begin + "\nemail = a@b.c\n" + end + "\n",
+		},
+		{
+			name:     "appended after manual content",
+			existing: "[alias]\n  st = status\n",
+			block:    "email = a@b.c\n",
+			expected: "[alias]\n  st = status\n" + begin + "\nemail = a@b.c\n" + end + "\n",
+		},
+		{
+			name:     "existing region replaced, surroundings kept",
+			existing: "before\n" + begin + "\nold = value\n" + end + "\nafter\n",
+			block:    "new = value\n",
+			expected: "before\n" + begin + "\nnew = value\n" + end + "\nafter\n",
+		},
+		{
+			name:     "missing end marker errors",
+			existing: begin + "\nold = value\n",
+			block:    "new = value\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeWithMarkers(tt.existing, begin, end, tt.block)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeWithMarkers() failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("mergeWithMarkers() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderAndWriteMerge(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "gitconfig.local")
+	manual := "[alias]\n  st = status\n"
+	if err := os.WriteFile(dest, []byte(manual), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mc := &config.MachinePrompt{
+		ID:          "git",
+		Destination: dest,
+		Template:    "[user]\n  email = {{.email}}\n",
+		Merge:       true,
+	}
+
+	// First render appends the managed region after the manual content
+	if _, err := RenderAndWrite(nil, mc, map[string]string{"email": "a@b.c"}, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAndWrite() failed: %v", err)
+	}
+
+	// Second render replaces only the managed region
+	if _, err := RenderAndWrite(nil, mc, map[string]string{"email": "new@b.c"}, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAndWrite() second run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	if !strings.HasPrefix(content, manual) {
+		t.Errorf("manual content was not preserved: %q", content)
+	}
+	if !strings.Contains(content, "email = new@b.c") {
+		t.Errorf("managed region was not updated: %q", content)
+	}
+	if strings.Contains(content, "email = a@b.c") {
+		t.Errorf("old managed content still present: %q", content)
+	}
+	if strings.Count(content, ">>> gopherdot:git >>>") != 1 {
+		t.Errorf("expected exactly one begin marker: %q", content)
+	}
+}
+
+func TestMergeMarkersCommentPrefix(t *testing.T) {
+	mc := &config.MachinePrompt{ID: "zed", CommentPrefix: "//"}
+	begin, end := mergeMarkers(mc)
+	if begin != "// >>> gopherdot:zed >>>" {
+		t.Errorf("begin = %q", begin)
+	}
+	if end != "// <<< gopherdot:zed <<<" {
+		t.Errorf("end = %q", end)
+	}
+}
+
+func TestMigrateDestination(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(tmpDir, "old.conf")
+	newPath := filepath.Join(tmpDir, "new.conf")
+	if err := os.WriteFile(oldPath, []byte("old content"), 0644); err != nil {
+		t.Fatalf("failed to write old file: %v", err)
+	}
+
+	mc := &config.MachinePrompt{ID: "test", Destination: newPath}
+
+	// Dry run reports the removal without touching the file
+	removed, err := MigrateDestination(mc, oldPath, RenderOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MigrateDestination dry run failed: %v", err)
+	}
+	if !removed {
+		t.Error("expected dry run to report the old file would be removed")
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Error("dry run should not remove the old file")
+	}
+
+	// Real run removes it
+	removed, err = MigrateDestination(mc, oldPath, RenderOptions{})
+	if err != nil {
+		t.Fatalf("MigrateDestination failed: %v", err)
+	}
+	if !removed {
+		t.Error("expected the old file to be removed")
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("expected old file to be gone")
+	}
+
+	// Unchanged destination and empty old path are no-ops
+	if removed, _ := MigrateDestination(mc, newPath, RenderOptions{}); removed {
+		t.Error("expected no removal when destination is unchanged")
+	}
+	if removed, _ := MigrateDestination(mc, "", RenderOptions{}); removed {
+		t.Error("expected no removal for empty old path")
+	}
+}
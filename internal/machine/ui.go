@@ -19,6 +19,8 @@ func PrintStatus(statuses []MachineConfigStatus) {
 		case "missing":
 			fmt.Printf("  • %s (not configured)\n", s.Description)
 			missing++
+		case "skipped":
+			fmt.Printf("  - %s (skipped: condition not met)\n", s.Description)
 		case "error":
 			ui.Error("%s: %s", s.Description, s.Error)
 		}
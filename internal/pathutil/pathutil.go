@@ -0,0 +1,112 @@
+// Package pathutil provides filesystem path helpers shared by packages
+// that handle user-supplied destinations from the config.
+package pathutil
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// NearestExistingDir walks up from dir until it finds a directory that
+// exists, so permission checks work for destinations that don't exist
+// yet.
+func NearestExistingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// IsWritable reports whether the current user can create files in dir
+func IsWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".g4d-write-check-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	f.Close()
+	os.Remove(name)
+	return true
+}
+
+// CheckWritable verifies that the destination's nearest existing parent
+// directory is writable by the current user. Callers use it to fail
+// with a clear message before a clone or write dies on a raw mkdir
+// error deep inside the operation.
+func CheckWritable(destPath string) error {
+	parent := NearestExistingDir(filepath.Dir(destPath))
+	if !IsWritable(parent) {
+		return fmt.Errorf("destination %s is not writable (cannot create files under %s); choose a different path or fix permissions", destPath, parent)
+	}
+	return nil
+}
+
+// HomeDir returns the current user's home directory, preferring the
+// standard library's lookup and falling back to $HOME.
+func HomeDir() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home, nil
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return home, nil
+	}
+	return "", fmt.Errorf("could not determine home directory")
+}
+
+// Expand resolves ~, ~user, and $VAR references in a config-supplied
+// path. Undefined environment variables and unknown users are errors
+// rather than silently producing a wrong path.
+func Expand(path string) (string, error) {
+	// Environment variables first, so $HOME/foo and ~/$SUBDIR both work
+	var expandErr error
+	path = os.Expand(path, func(key string) string {
+		val, ok := os.LookupEnv(key)
+		if !ok && expandErr == nil {
+			expandErr = fmt.Errorf("undefined environment variable $%s in path", key)
+		}
+		return val
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	switch {
+	case path == "~":
+		home, err := HomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = home
+	case strings.HasPrefix(path, "~/"):
+		home, err := HomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	case strings.HasPrefix(path, "~"):
+		// ~user or ~user/rest
+		rest := path[1:]
+		username := rest
+		var tail string
+		if i := strings.Index(rest, "/"); i >= 0 {
+			username = rest[:i]
+			tail = rest[i+1:]
+		}
+		u, err := user.Lookup(username)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up user %q: %w", username, err)
+		}
+		path = filepath.Join(u.HomeDir, tail)
+	}
+
+	return filepath.Clean(path), nil
+}
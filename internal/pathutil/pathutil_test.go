@@ -0,0 +1,106 @@
+package pathutil
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestNearestExistingDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"Existing dir", tmpDir, tmpDir},
+		{"Missing subdirs", filepath.Join(tmpDir, "a", "b", "c"), tmpDir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NearestExistingDir(tt.dir); got != tt.want {
+				t.Errorf("NearestExistingDir(%s) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Writable destination (parent doesn't exist yet)
+	if err := CheckWritable(filepath.Join(tmpDir, "new", "dest")); err != nil {
+		t.Errorf("CheckWritable() = %v, want nil for writable dir", err)
+	}
+
+	// Read-only parent
+	if os.Getuid() == 0 {
+		t.Skip("running as root, permission checks don't apply")
+	}
+	readOnly := filepath.Join(tmpDir, "readonly")
+	os.MkdirAll(readOnly, 0555)
+	if err := CheckWritable(filepath.Join(readOnly, "sub", "dest")); err == nil {
+		t.Error("CheckWritable() should fail for a read-only parent")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("UserHomeDir() failed: %v", err)
+	}
+	t.Setenv("G4D_TEST_DIR", "/opt/testdir")
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"Tilde alone", "~", home, false},
+		{"Tilde slash", "~/dotfiles", filepath.Join(home, "dotfiles"), false},
+		{"Nonexistent user", "~no-such-user-xyz/x", "", true},
+		{"Env var", "$G4D_TEST_DIR/sub", "/opt/testdir/sub", false},
+		{"Env var in tilde path", "~/$G4D_TEST_DIR", "", false}, // Joined under home
+		{"Undefined env var", "$G4D_UNDEFINED_VAR/x", "", true},
+		{"Plain absolute path", "/etc/hosts", "/etc/hosts", false},
+		{"Relative path cleaned", "a/./b", "a/b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.path)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expand(%s) = %q, want error", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand(%s) failed: %v", tt.path, err)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Expand(%s) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCurrentUser(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("user.Current() failed: %v", err)
+	}
+
+	got, err := Expand("~" + u.Username + "/sub")
+	if err != nil {
+		t.Fatalf("Expand() failed: %v", err)
+	}
+	if got != filepath.Join(u.HomeDir, "sub") {
+		t.Errorf("Expand(~%s/sub) = %q, want %q", u.Username, got, filepath.Join(u.HomeDir, "sub"))
+	}
+}
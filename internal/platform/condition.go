@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -10,7 +11,12 @@ import (
 // - distro: fedora, ubuntu, debian, arch, etc.
 // - package_manager: dnf, apt, brew, pacman, etc.
 // - wsl: true, false
+// - wsl_version: 1, 2
 // - arch, architecture: amd64, arm64, etc.
+// - shell: zsh, bash, fish, etc.
+// - container: true, false
+// - ci: true, false
+// - init_system: systemd, init, launchd, etc.
 func CheckCondition(condition map[string]string, p *Platform) bool {
 	if condition == nil || len(condition) == 0 {
 		return true // No condition means always true
@@ -37,15 +43,50 @@ func CheckCondition(condition map[string]string, p *Platform) bool {
 			if value == "false" && p.IsWSL {
 				return false
 			}
+		case "wsl_version":
+			if !matchesValue(strconv.Itoa(p.WSLVersion), value) {
+				return false
+			}
 		case "arch", "architecture":
 			if !matchesValue(p.Architecture, value) {
 				return false
 			}
+		case "shell":
+			if !matchesValue(p.Shell, value) {
+				return false
+			}
+		case "container":
+			if value == "true" && !p.IsContainer {
+				return false
+			}
+			if value == "false" && p.IsContainer {
+				return false
+			}
+		case "ci":
+			if value == "true" && !p.IsCI {
+				return false
+			}
+			if value == "false" && p.IsCI {
+				return false
+			}
+		case "init_system":
+			if !matchesValue(p.InitSystem, value) {
+				return false
+			}
 		}
 	}
 	return true
 }
 
+// MatchesArch reports whether the platform's architecture is in the given
+// list. An empty list matches every architecture.
+func MatchesArch(archs []string, p *Platform) bool {
+	if len(archs) == 0 {
+		return true
+	}
+	return matchesValue(p.Architecture, strings.Join(archs, ","))
+}
+
 // matchesValue checks if actual matches expected (supports comma-separated list)
 func matchesValue(actual, expected string) bool {
 	// Support comma-separated values (e.g., "linux,darwin")
@@ -5,18 +5,30 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
 
-// Platform represents the detected platform information
+// Platform represents the detected platform information.
+// The JSON field names are a stable schema consumed by external tooling
+// (g4d detect --json) - don't rename them.
 type Platform struct {
-	OS             string // linux, darwin, windows
-	Distro         string // fedora, ubuntu, debian, arch, etc. (Linux only)
-	DistroVersion  string // version number
-	IsWSL          bool   // true if running under WSL
-	PackageManager string // dnf, apt, brew, pacman, etc.
-	Architecture   string // amd64, arm64, etc.
+	OS             string `json:"os"`              // linux, darwin, windows
+	Distro         string `json:"distro"`          // fedora, ubuntu, debian, arch, etc. (Linux only)
+	DistroVersion  string `json:"distro_version"`  // version number
+	IsWSL          bool   `json:"is_wsl"`          // true if running under WSL
+	WSLVersion     int    `json:"wsl_version"`     // 1 or 2 when under WSL, 0 if unknown/not WSL
+	PackageManager string `json:"package_manager"` // dnf, apt, brew, pacman, etc.
+	Architecture   string `json:"architecture"`    // amd64, arm64, etc.
+	Shell          string `json:"shell"`           // zsh, bash, fish, etc.
+	IsContainer    bool   `json:"is_container"`    // true inside Docker/Podman/LXC
+	InitSystem     string `json:"init_system"`     // systemd, init, launchd, etc. ("" if unknown)
+	IsCI           bool   `json:"is_ci"`           // true when the CI environment variable is set
+	// AvailableManagers lists every package manager found on the
+	// system, in detection order. PackageManager is the primary pick.
+	AvailableManagers []string `json:"available_managers,omitempty"`
 }
 
 // Detect returns the current platform information
@@ -27,6 +39,13 @@ func Detect() (*Platform, error) {
 	}
 
 	p.IsWSL = detectWSL()
+	if p.IsWSL {
+		p.WSLVersion = detectWSLVersion()
+	}
+	p.Shell = DetectShell()
+	p.IsContainer = detectContainer()
+	p.InitSystem = detectInitSystem(p.OS)
+	p.IsCI = detectCI()
 	switch p.OS {
 	case "linux":
 		if err := detectLinuxDistro(p); err != nil {
@@ -42,14 +61,53 @@ func Detect() (*Platform, error) {
 	return p, nil
 }
 
+// DetectShell returns the name of the user's login shell (e.g. "zsh",
+// "bash", "fish"). It checks $SHELL first and falls back to the passwd
+// entry for the current user. Returns "unknown" if neither is available.
+func DetectShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+
+	if u, err := user.Current(); err == nil {
+		if shell := shellFromPasswd(u.Username); shell != "" {
+			return filepath.Base(shell)
+		}
+	}
+
+	return "unknown"
+}
+
+// shellFromPasswd looks up the login shell for a user in /etc/passwd
+func shellFromPasswd(username string) string {
+	file, err := os.Open("/etc/passwd")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Format: name:passwd:uid:gid:gecos:home:shell
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) >= 7 && fields[0] == username {
+			return strings.TrimSpace(fields[6])
+		}
+	}
+
+	return ""
+}
+
 // detectWindowsPackageManager checks for winget, choco, or scoop
 func detectWindowsPackageManager(p *Platform) {
-	if _, err := exec.LookPath("winget"); err == nil {
-		p.PackageManager = "winget"
-	} else if _, err := exec.LookPath("choco"); err == nil {
-		p.PackageManager = "choco"
-	} else if _, err := exec.LookPath("scoop"); err == nil {
-		p.PackageManager = "scoop"
+	for _, name := range []string{"winget", "choco", "scoop"} {
+		if _, err := exec.LookPath(name); err == nil {
+			p.AvailableManagers = append(p.AvailableManagers, name)
+		}
+	}
+
+	if len(p.AvailableManagers) > 0 {
+		p.PackageManager = p.AvailableManagers[0]
 	} else {
 		p.PackageManager = "none"
 	}
@@ -67,6 +125,95 @@ func detectWSL() bool {
 	return strings.Contains(content, "microsoft") || strings.Contains(content, "wsl")
 }
 
+// detectWSLVersion determines the WSL major version from the kernel
+// signature in /proc/version. Returns 0 when detection is ambiguous.
+func detectWSLVersion() int {
+	data, err := os.ReadFile("/proc/version")
+	if err != nil {
+		return 0
+	}
+	return wslVersionFromKernel(string(data))
+}
+
+// detectContainer checks whether we're running inside a container
+// (Docker, Podman, LXC, Kubernetes). It looks for the marker files the
+// runtimes drop, the "container" env var systemd-nspawn and Podman set,
+// and container runtime names in pid 1's cgroup.
+func detectContainer() bool {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
+	if os.Getenv("container") != "" {
+		return true
+	}
+
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	return containerFromCgroup(string(data))
+}
+
+// containerFromCgroup reports whether a /proc/1/cgroup dump names a
+// container runtime
+func containerFromCgroup(content string) bool {
+	for _, marker := range []string{"docker", "containerd", "lxc", "podman", "kubepods"} {
+		if strings.Contains(content, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectInitSystem returns the name of pid 1 (systemd, init, etc.) on
+// Linux and "launchd" on macOS. Returns "" when it can't tell.
+func detectInitSystem(goos string) string {
+	switch goos {
+	case "darwin":
+		return "launchd"
+	case "linux":
+		data, err := os.ReadFile("/proc/1/comm")
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	return ""
+}
+
+// detectCI checks the de facto standard CI environment variable set by
+// GitHub Actions, GitLab CI, Travis, and most other CI providers
+func detectCI() bool {
+	return ciFromEnv(os.Getenv("CI"))
+}
+
+// ciFromEnv interprets a CI env var value; "false" and "0" count as
+// explicitly not CI
+func ciFromEnv(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0":
+		return false
+	}
+	return true
+}
+
+// wslVersionFromKernel parses a kernel version string for the WSL version.
+// WSL2 runs a real Microsoft kernel that reports "WSL2"; WSL1 reports an
+// emulated kernel containing "Microsoft" without the WSL2 marker.
+func wslVersionFromKernel(content string) int {
+	content = strings.ToLower(content)
+	switch {
+	case strings.Contains(content, "wsl2"):
+		return 2
+	case strings.Contains(content, "microsoft"):
+		return 1
+	}
+	return 0
+}
+
 // detectLinuxDistro parses /etc/os-release to determine the distro
 func detectLinuxDistro(p *Platform) error {
 	file, err := os.Open("/etc/os-release")
@@ -114,7 +261,7 @@ func detectLinuxDistro(p *Platform) error {
 	return nil
 }
 
-// detectLinuxPackageManager determines which package manager is available
+// detectLinuxPackageManager determines which package managers are available
 func detectLinuxPackageManager(p *Platform) {
 	// Order matters - check most specific first
 	managers := []struct {
@@ -127,27 +274,63 @@ func detectLinuxPackageManager(p *Platform) {
 		{"pacman", "pacman"}, // Arch, Manjaro
 		{"zypper", "zypper"}, // openSUSE
 		{"apk", "apk"},       // Alpine
+		{"brew", "brew"},     // Linuxbrew
+		{"snap", "snap"},     // Snapcraft
 	}
 
 	for _, mgr := range managers {
 		if _, err := exec.LookPath(mgr.binary); err == nil {
-			p.PackageManager = mgr.name
+			p.AvailableManagers = append(p.AvailableManagers, mgr.name)
+		}
+	}
+
+	// Primary pick: the distro's native manager wins over supplemental
+	// ones (Linuxbrew, snap) unless nothing else is installed
+	for _, name := range p.AvailableManagers {
+		if name != "brew" && name != "snap" {
+			p.PackageManager = name
 			return
 		}
 	}
+	if len(p.AvailableManagers) > 0 {
+		p.PackageManager = p.AvailableManagers[0]
+		return
+	}
 
 	p.PackageManager = "unknown"
 }
 
-// detectMacOSPackageManager checks for Homebrew
+// detectMacOSPackageManager checks for Homebrew and MacPorts
 func detectMacOSPackageManager(p *Platform) {
 	if _, err := exec.LookPath("brew"); err == nil {
-		p.PackageManager = "brew"
+		p.AvailableManagers = append(p.AvailableManagers, "brew")
+	}
+	if _, err := exec.LookPath("port"); err == nil {
+		p.AvailableManagers = append(p.AvailableManagers, "port")
+	}
+
+	if len(p.AvailableManagers) > 0 {
+		p.PackageManager = p.AvailableManagers[0]
 	} else {
 		p.PackageManager = "none"
 	}
 }
 
+// ApplyManagerPreference re-picks the primary package manager using a
+// preference order (typically from the config's metadata). The first
+// preferred manager that is actually available wins; names that aren't
+// available on this machine are ignored.
+func (p *Platform) ApplyManagerPreference(preferred []string) {
+	for _, name := range preferred {
+		for _, avail := range p.AvailableManagers {
+			if name == avail {
+				p.PackageManager = name
+				return
+			}
+		}
+	}
+}
+
 // String returns a human-readable representation of the platform
 func (p *Platform) String() string {
 	var sb strings.Builder
@@ -160,12 +343,32 @@ func (p *Platform) String() string {
 			sb.WriteString(fmt.Sprintf(" %s", p.DistroVersion))
 		}
 		if p.IsWSL {
-			sb.WriteString(" (WSL)")
+			if p.WSLVersion > 0 {
+				sb.WriteString(fmt.Sprintf(" (WSL%d)", p.WSLVersion))
+			} else {
+				sb.WriteString(" (WSL)")
+			}
 		}
 	}
 
 	sb.WriteString(fmt.Sprintf("\nArchitecture: %s", p.Architecture))
 	sb.WriteString(fmt.Sprintf("\nPackage Manager: %s", p.PackageManager))
+	if len(p.AvailableManagers) > 1 {
+		sb.WriteString(fmt.Sprintf("\nAvailable Managers: %s", strings.Join(p.AvailableManagers, ", ")))
+	}
+
+	if p.Shell != "" {
+		sb.WriteString(fmt.Sprintf("\nShell: %s", p.Shell))
+	}
+	if p.InitSystem != "" {
+		sb.WriteString(fmt.Sprintf("\nInit System: %s", p.InitSystem))
+	}
+	if p.IsContainer {
+		sb.WriteString("\nContainer: yes")
+	}
+	if p.IsCI {
+		sb.WriteString("\nCI: yes")
+	}
 
 	return sb.String()
 }
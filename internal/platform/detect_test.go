@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"encoding/json"
 	"os"
 	"runtime"
 	"strings"
@@ -206,6 +207,143 @@ func TestDetectWSL(t *testing.T) {
 	}
 }
 
+func TestApplyManagerPreference(t *testing.T) {
+	tests := []struct {
+		name      string
+		available []string
+		preferred []string
+		current   string
+		want      string
+	}{
+		{
+			name:      "Preferred manager available",
+			available: []string{"apt", "brew"},
+			preferred: []string{"brew"},
+			current:   "apt",
+			want:      "brew",
+		},
+		{
+			name:      "First available preference wins",
+			available: []string{"dnf", "snap"},
+			preferred: []string{"brew", "snap"},
+			current:   "dnf",
+			want:      "snap",
+		},
+		{
+			name:      "Unavailable preference ignored",
+			available: []string{"apt"},
+			preferred: []string{"brew"},
+			current:   "apt",
+			want:      "apt",
+		},
+		{
+			name:      "No preference keeps primary",
+			available: []string{"dnf"},
+			preferred: nil,
+			current:   "dnf",
+			want:      "dnf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Platform{
+				PackageManager:    tt.current,
+				AvailableManagers: tt.available,
+			}
+
+			p.ApplyManagerPreference(tt.preferred)
+
+			if p.PackageManager != tt.want {
+				t.Errorf("PackageManager = %s, want %s", p.PackageManager, tt.want)
+			}
+		})
+	}
+}
+
+func TestWSLVersionFromKernel(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{
+			name:    "WSL2 kernel",
+			content: "Linux version 5.15.90.1-microsoft-standard-WSL2 (oe-user@oe-host)",
+			want:    2,
+		},
+		{
+			name:    "WSL1 kernel",
+			content: "Linux version 4.4.0-19041-Microsoft (Microsoft@Microsoft.com)",
+			want:    1,
+		},
+		{
+			name:    "Regular Linux kernel",
+			content: "Linux version 6.5.6-200.fc38.x86_64 (mockbuild@...)",
+			want:    0,
+		},
+		{
+			name:    "Empty content",
+			content: "",
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wslVersionFromKernel(tt.content); got != tt.want {
+				t.Errorf("wslVersionFromKernel() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		shellEnv string
+		expected string
+	}{
+		{
+			name:     "Full path zsh",
+			shellEnv: "/usr/bin/zsh",
+			expected: "zsh",
+		},
+		{
+			name:     "Full path bash",
+			shellEnv: "/bin/bash",
+			expected: "bash",
+		},
+		{
+			name:     "Fish shell",
+			shellEnv: "/usr/local/bin/fish",
+			expected: "fish",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SHELL", tt.shellEnv)
+			if got := DetectShell(); got != tt.expected {
+				t.Errorf("DetectShell() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectShellFallback(t *testing.T) {
+	// With $SHELL unset, DetectShell should fall back to the passwd
+	// entry (or "unknown") without panicking.
+	t.Setenv("SHELL", "")
+
+	shell := DetectShell()
+	if shell == "" {
+		t.Error("DetectShell() should never return an empty string")
+	}
+
+	t.Logf("Detected shell without $SHELL: %s", shell)
+}
+
 func TestDetectLinuxDistro(t *testing.T) {
 	// Only run on Linux
 	if runtime.GOOS != "linux" {
@@ -240,3 +378,152 @@ func TestDetectLinuxPackageManager(t *testing.T) {
 
 	t.Logf("Detected package manager: %s", p.PackageManager)
 }
+
+func TestPlatformJSONRoundTrip(t *testing.T) {
+	original := &Platform{
+		OS:                "linux",
+		Distro:            "fedora",
+		DistroVersion:     "41",
+		IsWSL:             true,
+		WSLVersion:        2,
+		PackageManager:    "dnf",
+		Architecture:      "amd64",
+		Shell:             "zsh",
+		AvailableManagers: []string{"dnf", "brew"},
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	// Field names are a stable schema for external tooling
+	for _, field := range []string{
+		`"os"`, `"distro"`, `"distro_version"`, `"is_wsl"`, `"wsl_version"`,
+		`"package_manager"`, `"architecture"`, `"shell"`, `"available_managers"`,
+	} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("JSON output missing field %s: %s", field, data)
+		}
+	}
+
+	var decoded Platform
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if decoded.OS != original.OS ||
+		decoded.Distro != original.Distro ||
+		decoded.DistroVersion != original.DistroVersion ||
+		decoded.IsWSL != original.IsWSL ||
+		decoded.WSLVersion != original.WSLVersion ||
+		decoded.PackageManager != original.PackageManager ||
+		decoded.Architecture != original.Architecture ||
+		decoded.Shell != original.Shell {
+		t.Errorf("round-trip mismatch: got %+v, want %+v", decoded, original)
+	}
+	if len(decoded.AvailableManagers) != 2 {
+		t.Errorf("AvailableManagers = %v, want 2 entries", decoded.AvailableManagers)
+	}
+}
+
+func TestContainerFromCgroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{
+			name:     "docker",
+			content:  "0::/system.slice/docker-abc123.scope\n",
+			expected: true,
+		},
+		{
+			name:     "containerd",
+			content:  "0::/system.slice/containerd.service/task\n",
+			expected: true,
+		},
+		{
+			name:     "lxc",
+			content:  "12:pids:/lxc/mycontainer\n",
+			expected: true,
+		},
+		{
+			name:     "kubernetes pod",
+			content:  "0::/kubepods/besteffort/pod123/abc\n",
+			expected: true,
+		},
+		{
+			name:     "bare metal systemd",
+			content:  "0::/init.scope\n",
+			expected: false,
+		},
+		{
+			name:     "empty",
+			content:  "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerFromCgroup(tt.content); got != tt.expected {
+				t.Errorf("containerFromCgroup(%q) = %v, want %v", tt.content, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCIFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"true", true},
+		{"1", true},
+		{"yes", true},
+		{"", false},
+		{"false", false},
+		{"False", false},
+		{"0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run("value="+tt.value, func(t *testing.T) {
+			if got := ciFromEnv(tt.value); got != tt.expected {
+				t.Errorf("ciFromEnv(%q) = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckConditionContainerCI(t *testing.T) {
+	p := &Platform{
+		OS:          "linux",
+		IsContainer: true,
+		InitSystem:  "systemd",
+		IsCI:        false,
+	}
+
+	tests := []struct {
+		name      string
+		condition map[string]string
+		expected  bool
+	}{
+		{"container true matches", map[string]string{"container": "true"}, true},
+		{"container false fails", map[string]string{"container": "false"}, false},
+		{"ci true fails", map[string]string{"ci": "true"}, false},
+		{"ci false matches", map[string]string{"ci": "false"}, true},
+		{"init_system matches", map[string]string{"init_system": "systemd"}, true},
+		{"init_system mismatch", map[string]string{"init_system": "runit"}, false},
+		{"combined", map[string]string{"os": "linux", "container": "true", "ci": "false"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckCondition(tt.condition, p); got != tt.expected {
+				t.Errorf("CheckCondition(%v) = %v, want %v", tt.condition, got, tt.expected)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // PackageManager defines the interface for package management operations
@@ -17,6 +19,14 @@ type PackageManager interface {
 	// Install installs one or more packages
 	Install(packages ...string) error
 
+	// InstallCommand returns the full command line Install would run for
+	// the given packages, so callers can show which (possibly privileged)
+	// command is about to execute
+	InstallCommand(packages ...string) []string
+
+	// Uninstall removes one or more packages
+	Uninstall(packages ...string) error
+
 	// IsInstalled checks if a package is installed
 	IsInstalled(pkg string) bool
 
@@ -51,6 +61,7 @@ func GetPackageManager(p *Platform) (PackageManager, error) {
 // runCommand executes a command and returns the output
 func runCommand(name string, args ...string) (string, error) {
 	cmd := exec.Command(name, args...)
+	verbose.Command(cmd.Args...)
 	output, err := cmd.CombinedOutput()
 	return strings.TrimSpace(string(output)), err
 }
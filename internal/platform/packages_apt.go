@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // APTManager implements PackageManager for APT (Debian, Ubuntu)
@@ -17,28 +19,56 @@ func (a *APTManager) IsAvailable() bool {
 	return commandExists("apt")
 }
 
+func (a *APTManager) InstallCommand(packages ...string) []string {
+	argv := []string{"sudo", "apt-get", "install", "-y"}
+	for _, pkg := range packages {
+		argv = append(argv, MapPackageName(pkg, "apt"))
+	}
+	return argv
+}
+
 func (a *APTManager) Install(packages ...string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
+	argv := a.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	// Set DEBIAN_FRONTEND=noninteractive to avoid prompts
+	cmd.Env = append(cmd.Env, "DEBIAN_FRONTEND=noninteractive")
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (a *APTManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
 	// Map package names
 	mapped := make([]string, len(packages))
 	for i, pkg := range packages {
 		mapped[i] = MapPackageName(pkg, "apt")
 	}
 
-	// Set DEBIAN_FRONTEND=noninteractive to avoid prompts
-	args := []string{"apt-get", "install", "-y"}
+	args := []string{"apt-get", "remove", "-y"}
 	args = append(args, mapped...)
 
 	cmd := exec.Command("sudo", args...)
+	verbose.Command(cmd.Args...)
 	cmd.Env = append(cmd.Env, "DEBIAN_FRONTEND=noninteractive")
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install packages: %w", err)
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
 	return nil
@@ -56,6 +86,7 @@ func (a *APTManager) IsInstalled(pkg string) bool {
 
 func (a *APTManager) Update() error {
 	cmd := exec.Command("sudo", "apt-get", "update")
+	verbose.Command(cmd.Args...)
 	cmd.Env = append(cmd.Env, "DEBIAN_FRONTEND=noninteractive")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to update package cache: %w", err)
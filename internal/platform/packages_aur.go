@@ -0,0 +1,133 @@
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
+)
+
+// aurHelpers lists supported AUR helpers in preference order
+var aurHelpers = []string{"yay", "paru"}
+
+// DetectAURHelper returns the first installed AUR helper, or "" when
+// none is available
+func DetectAURHelper() string {
+	for _, helper := range aurHelpers {
+		if commandExists(helper) {
+			return helper
+		}
+	}
+	return ""
+}
+
+// GetAURManager returns a package manager backed by an installed AUR
+// helper, for dependencies marked source: aur
+func GetAURManager() (*AURManager, error) {
+	helper := DetectAURHelper()
+	if helper == "" {
+		return nil, fmt.Errorf("no AUR helper installed (install %s)", strings.Join(aurHelpers, " or "))
+	}
+	return &AURManager{helper: helper}, nil
+}
+
+// AURManager implements PackageManager via an AUR helper (yay, paru).
+// Helpers run as the regular user and escalate internally, so no sudo.
+type AURManager struct {
+	helper string
+}
+
+func (a *AURManager) Name() string {
+	return a.helper
+}
+
+func (a *AURManager) IsAvailable() bool {
+	return commandExists(a.helper)
+}
+
+func (a *AURManager) InstallCommand(packages ...string) []string {
+	argv := []string{a.helper, "-S", "--noconfirm"}
+	argv = append(argv, packages...)
+	return argv
+}
+
+func (a *AURManager) Install(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
+	argv := a.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AURManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
+	args := []string{"-R", "--noconfirm"}
+	args = append(args, packages...)
+
+	cmd := exec.Command(a.helper, args...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to remove packages: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AURManager) IsInstalled(pkg string) bool {
+	// AUR packages register with pacman's local database
+	_, err := runCommand("pacman", "-Q", pkg)
+	return err == nil
+}
+
+func (a *AURManager) Update() error {
+	cmd := exec.Command(a.helper, "-Sy")
+	verbose.Command(cmd.Args...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update package database: %w", err)
+	}
+	return nil
+}
+
+func (a *AURManager) Search(query string) ([]string, error) {
+	output, err := runCommand(a.helper, "-Ss", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, line := range strings.Split(output, "\n") {
+		// Helper search format matches pacman: "repo/package version"
+		if strings.Contains(line, "/") && !strings.HasPrefix(line, " ") {
+			parts := strings.Fields(line)
+			if len(parts) > 0 {
+				pkgParts := strings.Split(parts[0], "/")
+				if len(pkgParts) > 1 {
+					results = append(results, pkgParts[1])
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (a *AURManager) NeedsSudo() bool {
+	return false
+}
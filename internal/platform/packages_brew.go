@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // BrewManager implements PackageManager for Homebrew (macOS, Linux)
@@ -17,26 +19,53 @@ func (b *BrewManager) IsAvailable() bool {
 	return commandExists("brew")
 }
 
+func (b *BrewManager) InstallCommand(packages ...string) []string {
+	argv := []string{"brew", "install"}
+	for _, pkg := range packages {
+		argv = append(argv, MapPackageName(pkg, "brew"))
+	}
+	return argv
+}
+
 func (b *BrewManager) Install(packages ...string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
+	argv := b.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (b *BrewManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
 	// Map package names
 	mapped := make([]string, len(packages))
 	for i, pkg := range packages {
 		mapped[i] = MapPackageName(pkg, "brew")
 	}
 
-	args := []string{"install"}
+	args := []string{"uninstall"}
 	args = append(args, mapped...)
 
 	cmd := exec.Command("brew", args...)
+	verbose.Command(cmd.Args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install packages: %w", err)
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
 	return nil
@@ -63,6 +92,7 @@ func (b *BrewManager) IsInstalled(pkg string) bool {
 
 func (b *BrewManager) Update() error {
 	cmd := exec.Command("brew", "update")
+	verbose.Command(cmd.Args...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to update brew: %w", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // DNFManager implements PackageManager for DNF (Fedora, RHEL 8+)
@@ -17,26 +19,53 @@ func (d *DNFManager) IsAvailable() bool {
 	return commandExists("dnf")
 }
 
+func (d *DNFManager) InstallCommand(packages ...string) []string {
+	argv := []string{"sudo", "dnf", "install", "-y"}
+	for _, pkg := range packages {
+		argv = append(argv, MapPackageName(pkg, "dnf"))
+	}
+	return argv
+}
+
 func (d *DNFManager) Install(packages ...string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
+	argv := d.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil // Could pipe to UI later
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNFManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
 	// Map package names
 	mapped := make([]string, len(packages))
 	for i, pkg := range packages {
 		mapped[i] = MapPackageName(pkg, "dnf")
 	}
 
-	args := []string{"install", "-y"}
+	args := []string{"remove", "-y"}
 	args = append(args, mapped...)
 
 	cmd := exec.Command("sudo", append([]string{"dnf"}, args...)...)
-	cmd.Stdout = nil // Could pipe to UI later
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install packages: %w", err)
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
 	return nil
@@ -53,6 +82,7 @@ func (d *DNFManager) IsInstalled(pkg string) bool {
 
 func (d *DNFManager) Update() error {
 	cmd := exec.Command("sudo", "dnf", "check-update", "-y")
+	verbose.Command(cmd.Args...)
 	// check-update returns 100 if updates are available, 0 if not
 	// We just want to refresh the cache, so we ignore the exit code
 	_ = cmd.Run()
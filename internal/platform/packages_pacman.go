@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // PacmanManager implements PackageManager for Pacman (Arch Linux, Manjaro)
@@ -17,26 +19,53 @@ func (p *PacmanManager) IsAvailable() bool {
 	return commandExists("pacman")
 }
 
+func (p *PacmanManager) InstallCommand(packages ...string) []string {
+	argv := []string{"sudo", "pacman", "-S", "--noconfirm"}
+	for _, pkg := range packages {
+		argv = append(argv, MapPackageName(pkg, "pacman"))
+	}
+	return argv
+}
+
 func (p *PacmanManager) Install(packages ...string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
+	argv := p.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PacmanManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
 	// Map package names
 	mapped := make([]string, len(packages))
 	for i, pkg := range packages {
 		mapped[i] = MapPackageName(pkg, "pacman")
 	}
 
-	args := []string{"-S", "--noconfirm"}
+	args := []string{"-R", "--noconfirm"}
 	args = append(args, mapped...)
 
 	cmd := exec.Command("sudo", append([]string{"pacman"}, args...)...)
+	verbose.Command(cmd.Args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install packages: %w", err)
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
 	return nil
@@ -51,6 +80,7 @@ func (p *PacmanManager) IsInstalled(pkg string) bool {
 
 func (p *PacmanManager) Update() error {
 	cmd := exec.Command("sudo", "pacman", "-Sy")
+	verbose.Command(cmd.Args...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to update package database: %w", err)
 	}
@@ -162,6 +162,29 @@ func TestPacmanManager(t *testing.T) {
 	}
 }
 
+func TestAURManager(t *testing.T) {
+	mgr := &AURManager{helper: "yay"}
+
+	if mgr.Name() != "yay" {
+		t.Errorf("Name() = %s, want yay", mgr.Name())
+	}
+
+	if mgr.NeedsSudo() {
+		t.Error("NeedsSudo() should return false for AUR helpers")
+	}
+
+	cmd := mgr.InstallCommand("spotify")
+	want := []string{"yay", "-S", "--noconfirm", "spotify"}
+	if len(cmd) != len(want) {
+		t.Fatalf("InstallCommand() = %v, want %v", cmd, want)
+	}
+	for i := range want {
+		if cmd[i] != want[i] {
+			t.Errorf("InstallCommand()[%d] = %q, want %q", i, cmd[i], want[i])
+		}
+	}
+}
+
 func TestCommandExists(t *testing.T) {
 	// Test with a command that should exist on all systems
 	if !commandExists("sh") {
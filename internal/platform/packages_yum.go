@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // YumManager implements PackageManager for YUM (RHEL 7, CentOS 7)
@@ -17,26 +19,53 @@ func (y *YumManager) IsAvailable() bool {
 	return commandExists("yum")
 }
 
+func (y *YumManager) InstallCommand(packages ...string) []string {
+	argv := []string{"sudo", "yum", "install", "-y"}
+	for _, pkg := range packages {
+		argv = append(argv, MapPackageName(pkg, "yum"))
+	}
+	return argv
+}
+
 func (y *YumManager) Install(packages ...string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
+	argv := y.InstallCommand(packages...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	verbose.Command(cmd.Args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	return nil
+}
+
+func (y *YumManager) Uninstall(packages ...string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
 	// Map package names
 	mapped := make([]string, len(packages))
 	for i, pkg := range packages {
 		mapped[i] = MapPackageName(pkg, "yum")
 	}
 
-	args := []string{"install", "-y"}
+	args := []string{"remove", "-y"}
 	args = append(args, mapped...)
 
 	cmd := exec.Command("sudo", append([]string{"yum"}, args...)...)
+	verbose.Command(cmd.Args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install packages: %w", err)
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
 	return nil
@@ -53,6 +82,7 @@ func (y *YumManager) IsInstalled(pkg string) bool {
 
 func (y *YumManager) Update() error {
 	cmd := exec.Command("sudo", "yum", "check-update", "-y")
+	verbose.Command(cmd.Args...)
 	_ = cmd.Run()
 	return nil
 }
@@ -0,0 +1,95 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nvandessel/go4dot/internal/verbose"
+)
+
+// RepoStatus describes the sync state of the dotfiles git repository.
+type RepoStatus struct {
+	Branch      string // Current branch name
+	Upstream    string // Upstream ref (e.g. "origin/main"), empty if none
+	Ahead       int    // Commits ahead of upstream
+	Behind      int    // Commits behind upstream
+	Dirty       bool   // Uncommitted local changes
+	FetchFailed bool   // The incremental fetch failed (e.g. offline)
+}
+
+// GitRepoStatus reports whether the dotfiles repo is ahead/behind its
+// upstream without pulling. When fetch is true it runs an incremental
+// `git fetch --quiet` first so the counts are current; a failed fetch
+// (e.g. offline) is recorded but does not fail the status check.
+func GitRepoStatus(ctx context.Context, dotfilesPath string, fetch bool) (*RepoStatus, error) {
+	gitDir := filepath.Join(dotfilesPath, ".git")
+	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s is not a git repository", dotfilesPath)
+	}
+
+	status := &RepoStatus{}
+
+	if fetch {
+		fetchCmd := exec.CommandContext(ctx, "git", "fetch", "--quiet")
+		fetchCmd.Dir = dotfilesPath
+		verbose.Command(fetchCmd.Args...)
+		if err := fetchCmd.Run(); err != nil {
+			status.FetchFailed = true
+		}
+	}
+
+	branch, err := gitOutput(ctx, dotfilesPath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	status.Branch = branch
+
+	// Uncommitted changes
+	porcelain, err := gitOutput(ctx, dotfilesPath, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working tree status: %w", err)
+	}
+	status.Dirty = porcelain != ""
+
+	// Ahead/behind requires an upstream; a branch without one is not an error
+	upstream, err := gitOutput(ctx, dotfilesPath, "rev-parse", "--abbrev-ref", "@{upstream}")
+	if err != nil {
+		return status, nil
+	}
+	status.Upstream = upstream
+
+	counts, err := gitOutput(ctx, dotfilesPath, "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ahead/behind commits: %w", err)
+	}
+
+	fields := strings.Fields(counts)
+	if len(fields) == 2 {
+		status.Ahead, _ = strconv.Atoi(fields[0])
+		status.Behind, _ = strconv.Atoi(fields[1])
+	}
+
+	return status, nil
+}
+
+// InSync returns true if the repo matches its upstream and has no local changes
+func (s *RepoStatus) InSync() bool {
+	return s.Ahead == 0 && s.Behind == 0 && !s.Dirty
+}
+
+// gitOutput runs a git command in dir and returns its trimmed stdout
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	verbose.Command(cmd.Args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
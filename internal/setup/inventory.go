@@ -0,0 +1,271 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/state"
+	"github.com/nvandessel/go4dot/internal/ui"
+)
+
+// InventoryItem is one entry in the config inventory with its resolved
+// install status on this machine
+type InventoryItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Group       string `json:"group,omitempty"`  // "core" or "optional" for configs
+	Status      string `json:"status"`           // installed, available, unavailable, skipped, configured, pending, archived
+	Detail      string `json:"detail,omitempty"` // Install path or reason for unavailability
+}
+
+// InventoryReport is the full machine-readable dotfiles inventory. Both
+// the text listing and `list --json` render from this struct so the two
+// outputs can't drift.
+type InventoryReport struct {
+	Configs      []InventoryItem `json:"configs"`
+	External     []InventoryItem `json:"external"`
+	Machine      []InventoryItem `json:"machine"`
+	Archived     []InventoryItem `json:"archived"`
+	DotfilesPath string          `json:"dotfiles_path,omitempty"`
+	Installed    int             `json:"installed"`
+	StateFound   bool            `json:"state_found"`
+}
+
+// Inventory resolves every config, external dep, and machine config in
+// cfg against the installation state and platform. st may be nil when no
+// installation exists yet.
+func Inventory(cfg *config.Config, st *state.State, p *platform.Platform) *InventoryReport {
+	report := &InventoryReport{}
+
+	installed := make(map[string]bool)
+	if st != nil {
+		report.StateFound = true
+		report.DotfilesPath = st.DotfilesPath
+		report.Installed = len(st.Configs)
+		for _, c := range st.Configs {
+			installed[c.Name] = true
+		}
+	}
+
+	for _, c := range cfg.Configs.Core {
+		report.Configs = append(report.Configs, configInventoryItem(c, "core", installed, p))
+	}
+	for _, c := range cfg.Configs.Optional {
+		report.Configs = append(report.Configs, configInventoryItem(c, "optional", installed, p))
+	}
+
+	for _, e := range cfg.External {
+		item := InventoryItem{Name: e.Name, Description: e.Destination}
+		switch {
+		case !platform.CheckCondition(e.Condition, p):
+			item.Status = "skipped"
+			item.Detail = "platform mismatch"
+		case st != nil && st.ExternalDeps[e.ID].Installed:
+			item.Status = "installed"
+			item.Detail = st.ExternalDeps[e.ID].Path
+		default:
+			item.Status = "available"
+			item.Detail = "not installed"
+		}
+		report.External = append(report.External, item)
+	}
+
+	for _, mc := range cfg.MachineConfig {
+		item := InventoryItem{Name: mc.ID, Description: mc.Description}
+		if st != nil {
+			if m, ok := st.MachineConfig[mc.ID]; ok {
+				item.Status = "configured"
+				item.Detail = m.ConfigPath
+			}
+		}
+		if item.Status == "" {
+			item.Status = "pending"
+			item.Detail = "not configured"
+		}
+		report.Machine = append(report.Machine, item)
+	}
+
+	for _, c := range cfg.Archived {
+		report.Archived = append(report.Archived, InventoryItem{
+			Name:        c.Name,
+			Description: c.Description,
+			Status:      "archived",
+		})
+	}
+
+	return report
+}
+
+func configInventoryItem(c config.ConfigItem, group string, installed map[string]bool, p *platform.Platform) InventoryItem {
+	item := InventoryItem{Name: c.Name, Description: c.Description, Group: group}
+
+	switch {
+	case len(c.Platforms) > 0 && !platformMatches(c.Platforms, p):
+		item.Status = "unavailable"
+		item.Detail = fmt.Sprintf("not available on %s", p.OS)
+	case !platform.MatchesArch(c.Arch, p):
+		item.Status = "unavailable"
+		item.Detail = fmt.Sprintf("not available on %s", p.Architecture)
+	case !platform.CheckCondition(c.Condition, p):
+		item.Status = "unavailable"
+		item.Detail = "condition not met on this platform"
+	case installed[c.Name]:
+		item.Status = "installed"
+	default:
+		item.Status = "available"
+		item.Detail = "not installed"
+	}
+
+	return item
+}
+
+func platformMatches(platforms []string, p *platform.Platform) bool {
+	for _, plat := range platforms {
+		if plat == p.OS || plat == p.Distro || plat == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// configMatchesPlatform reports whether a config applies to the current
+// platform: Platforms (OS/distro), Arch, and Condition must all match.
+// Shared by the inventory and the install flow so list and install agree
+// on what belongs on this machine.
+func configMatchesPlatform(c config.ConfigItem, p *platform.Platform) bool {
+	if len(c.Platforms) > 0 && !platformMatches(c.Platforms, p) {
+		return false
+	}
+	if !platform.MatchesArch(c.Arch, p) {
+		return false
+	}
+	return platform.CheckCondition(c.Condition, p)
+}
+
+// InventoryFilter narrows an inventory to the entries a caller cares
+// about. The zero value keeps everything; set fields compose (e.g.
+// pending external deps only).
+type InventoryFilter struct {
+	Installed bool   // Keep only installed/configured entries
+	Pending   bool   // Keep only entries not yet installed/configured
+	Type      string // "configs", "external", or "machine" ("" keeps all)
+}
+
+// Filter returns a copy of the report narrowed to the entries matching
+// the filter. Summary fields are preserved as-is.
+func (r *InventoryReport) Filter(f InventoryFilter) *InventoryReport {
+	filtered := *r
+	filtered.Configs = filterItems(r.Configs, f, "configs")
+	filtered.External = filterItems(r.External, f, "external")
+	filtered.Machine = filterItems(r.Machine, f, "machine")
+	filtered.Archived = filterItems(r.Archived, f, "configs")
+	return &filtered
+}
+
+func filterItems(items []InventoryItem, f InventoryFilter, section string) []InventoryItem {
+	if f.Type != "" && f.Type != section {
+		return nil
+	}
+	if !f.Installed && !f.Pending {
+		return items
+	}
+
+	var kept []InventoryItem
+	for _, item := range items {
+		installed := item.Status == "installed" || item.Status == "configured"
+		pending := item.Status == "available" || item.Status == "pending"
+		if (f.Installed && installed) || (f.Pending && pending) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// PrintInventory renders an inventory as the human-readable listing used
+// by `g4d list`. With showAll, unavailable and archived entries are
+// included.
+func PrintInventory(report *InventoryReport, showAll bool) {
+	hasCore := false
+	hasOptional := false
+	for _, item := range report.Configs {
+		switch item.Group {
+		case "core":
+			hasCore = true
+		case "optional":
+			hasOptional = true
+		}
+	}
+
+	if hasCore {
+		ui.Section("Core Configs")
+		printInventoryConfigs(report.Configs, "core", showAll)
+	}
+	if hasOptional {
+		ui.Section("Optional Configs")
+		printInventoryConfigs(report.Configs, "optional", showAll)
+	}
+
+	if len(report.External) > 0 {
+		ui.Section("External Dependencies")
+		table := ui.NewTable("NAME", "STATUS", "DETAIL")
+		for _, item := range report.External {
+			if item.Status == "skipped" && !showAll {
+				continue
+			}
+			table.AddRow(ui.StatusStyle(item.Status), item.Name, item.Status, item.Detail)
+		}
+		table.Print()
+	}
+
+	if len(report.Machine) > 0 {
+		ui.Section("Machine Configurations")
+		table := ui.NewTable("NAME", "STATUS", "DETAIL")
+		for _, item := range report.Machine {
+			label := item.Description
+			if label == "" {
+				label = item.Name
+			}
+			table.AddRow(ui.StatusStyle(item.Status), label, item.Status, item.Detail)
+		}
+		table.Print()
+	}
+
+	if len(report.Archived) > 0 && showAll {
+		ui.Section("Archived Configs (deprecated)")
+		for _, item := range report.Archived {
+			fmt.Printf("  - %s\n", item.Name)
+			if item.Description != "" {
+				fmt.Printf("    %s\n", item.Description)
+			}
+		}
+	}
+
+	ui.Section("Summary")
+	if report.StateFound {
+		fmt.Printf("Installed: %d configs\n", report.Installed)
+		if report.DotfilesPath != "" {
+			fmt.Printf("Dotfiles:  %s\n", report.DotfilesPath)
+		}
+	} else {
+		ui.Warning("No installation state found. Run 'g4d install' to set up.")
+	}
+}
+
+func printInventoryConfigs(items []InventoryItem, group string, showAll bool) {
+	table := ui.NewTable("NAME", "STATUS", "DETAIL")
+	for _, item := range items {
+		if item.Group != group {
+			continue
+		}
+		if item.Status == "unavailable" && !showAll {
+			continue
+		}
+		detail := item.Description
+		if item.Status == "unavailable" {
+			detail = item.Detail
+		}
+		table.AddRow(ui.StatusStyle(item.Status), item.Name, item.Status, detail)
+	}
+	table.Print()
+}
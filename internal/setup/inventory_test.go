@@ -0,0 +1,197 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/platform"
+	"github.com/nvandessel/go4dot/internal/state"
+)
+
+func TestInventory(t *testing.T) {
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core: []config.ConfigItem{
+				{Name: "git", Description: "Git config"},
+				{Name: "mac-only", Platforms: []string{"darwin"}},
+			},
+			Optional: []config.ConfigItem{
+				{Name: "nvim", Description: "Neovim"},
+			},
+		},
+		External: []config.ExternalDep{
+			{ID: "pure", Name: "Pure", Destination: "~/.zsh/pure"},
+			{ID: "linux-only", Name: "Linux Plugin", Condition: map[string]string{"os": "linux"}},
+		},
+		MachineConfig: []config.MachinePrompt{
+			{ID: "gitconfig", Description: "Git user config"},
+			{ID: "work", Description: "Work settings"},
+		},
+		Archived: []config.ConfigItem{
+			{Name: "old-vim"},
+		},
+	}
+
+	st := state.New()
+	st.DotfilesPath = "/home/user/dotfiles"
+	st.AddConfig("git", "git", true)
+	st.SetExternalDep("pure", "/home/user/.zsh/pure", true)
+	st.MachineConfig["gitconfig"] = state.MachineState{ConfigPath: "/home/user/.gitconfig.local"}
+
+	p := &platform.Platform{OS: "darwin", Architecture: "arm64"}
+
+	report := Inventory(cfg, st, p)
+
+	wantConfigs := map[string]string{
+		"git":      "installed",
+		"mac-only": "available",
+		"nvim":     "available",
+	}
+	if len(report.Configs) != len(wantConfigs) {
+		t.Fatalf("len(Configs) = %d, want %d", len(report.Configs), len(wantConfigs))
+	}
+	for _, item := range report.Configs {
+		if item.Status != wantConfigs[item.Name] {
+			t.Errorf("config %s status = %q, want %q", item.Name, item.Status, wantConfigs[item.Name])
+		}
+	}
+
+	wantExternal := map[string]string{
+		"Pure":         "installed",
+		"Linux Plugin": "skipped",
+	}
+	for _, item := range report.External {
+		if item.Status != wantExternal[item.Name] {
+			t.Errorf("external %s status = %q, want %q", item.Name, item.Status, wantExternal[item.Name])
+		}
+	}
+	if report.External[0].Detail != "/home/user/.zsh/pure" {
+		t.Errorf("Pure detail = %q, want install path", report.External[0].Detail)
+	}
+
+	wantMachine := map[string]string{
+		"gitconfig": "configured",
+		"work":      "pending",
+	}
+	for _, item := range report.Machine {
+		if item.Status != wantMachine[item.Name] {
+			t.Errorf("machine %s status = %q, want %q", item.Name, item.Status, wantMachine[item.Name])
+		}
+	}
+
+	if len(report.Archived) != 1 || report.Archived[0].Status != "archived" {
+		t.Errorf("Archived = %+v, want one archived entry", report.Archived)
+	}
+
+	if !report.StateFound || report.Installed != 1 || report.DotfilesPath != "/home/user/dotfiles" {
+		t.Errorf("summary fields = %+v, want state found with 1 installed", report)
+	}
+}
+
+func TestInventoryNoState(t *testing.T) {
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core: []config.ConfigItem{{Name: "git"}},
+		},
+	}
+	p := &platform.Platform{OS: "linux"}
+
+	report := Inventory(cfg, nil, p)
+
+	if report.StateFound {
+		t.Error("StateFound should be false without state")
+	}
+	if len(report.Configs) != 1 || report.Configs[0].Status != "available" {
+		t.Errorf("Configs = %+v, want git available", report.Configs)
+	}
+}
+
+func TestInventoryFilter(t *testing.T) {
+	report := &InventoryReport{
+		Configs: []InventoryItem{
+			{Name: "git", Status: "installed"},
+			{Name: "nvim", Status: "available"},
+			{Name: "mac-only", Status: "unavailable"},
+		},
+		External: []InventoryItem{
+			{Name: "pure", Status: "installed"},
+			{Name: "tpm", Status: "available"},
+		},
+		Machine: []InventoryItem{
+			{Name: "gitconfig", Status: "configured"},
+			{Name: "work", Status: "pending"},
+		},
+		Archived: []InventoryItem{
+			{Name: "old-vim", Status: "archived"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter InventoryFilter
+		counts [4]int // configs, external, machine, archived
+	}{
+		{
+			name:   "Zero filter keeps everything",
+			filter: InventoryFilter{},
+			counts: [4]int{3, 2, 2, 1},
+		},
+		{
+			name:   "Installed only",
+			filter: InventoryFilter{Installed: true},
+			counts: [4]int{1, 1, 1, 0},
+		},
+		{
+			name:   "Pending only",
+			filter: InventoryFilter{Pending: true},
+			counts: [4]int{1, 1, 1, 0},
+		},
+		{
+			name:   "Both status flags keep either",
+			filter: InventoryFilter{Installed: true, Pending: true},
+			counts: [4]int{2, 2, 2, 0},
+		},
+		{
+			name:   "Type narrows to one section",
+			filter: InventoryFilter{Type: "external"},
+			counts: [4]int{0, 2, 0, 0},
+		},
+		{
+			name:   "Filters compose",
+			filter: InventoryFilter{Pending: true, Type: "external"},
+			counts: [4]int{0, 1, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := report.Filter(tt.filter)
+			counts := [4]int{len(got.Configs), len(got.External), len(got.Machine), len(got.Archived)}
+			if counts != tt.counts {
+				t.Errorf("Filter(%+v) counts = %v, want %v", tt.filter, counts, tt.counts)
+			}
+		})
+	}
+}
+
+func TestConfigInventoryItemCondition(t *testing.T) {
+	p := &platform.Platform{OS: "linux", Distro: "fedora", PackageManager: "dnf", Architecture: "amd64"}
+
+	brewOnly := config.ConfigItem{
+		Name:      "brew-tools",
+		Condition: map[string]string{"package_manager": "brew"},
+	}
+	item := configInventoryItem(brewOnly, "optional", map[string]bool{}, p)
+	if item.Status != "unavailable" {
+		t.Errorf("status = %q, want unavailable for unmet condition", item.Status)
+	}
+
+	dnfOnly := config.ConfigItem{
+		Name:      "dnf-tools",
+		Condition: map[string]string{"package_manager": "dnf"},
+	}
+	item = configInventoryItem(dnfOnly, "optional", map[string]bool{}, p)
+	if item.Status != "available" {
+		t.Errorf("status = %q, want available for met condition", item.Status)
+	}
+}
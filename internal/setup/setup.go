@@ -1,7 +1,11 @@
 package setup
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
@@ -9,18 +13,32 @@ import (
 	"github.com/nvandessel/go4dot/internal/platform"
 	"github.com/nvandessel/go4dot/internal/state"
 	"github.com/nvandessel/go4dot/internal/stow"
+	"github.com/nvandessel/go4dot/internal/ui"
 )
 
+// ErrCriticalDepsFailed marks an install abort caused by a critical
+// dependency that could not be installed.
+var ErrCriticalDepsFailed = errors.New("critical dependencies failed to install")
+
 // InstallOptions configures the installation behavior
 type InstallOptions struct {
-	Auto         bool                                 // Non-interactive, use defaults
-	Minimal      bool                                 // Only core configs, skip optional
-	SkipDeps     bool                                 // Skip dependency installation
-	SkipExternal bool                                 // Skip external dependency cloning
-	SkipMachine  bool                                 // Skip machine-specific configuration
-	SkipStow     bool                                 // Skip stowing configs
-	Overwrite    bool                                 // Overwrite existing files
-	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
+	Auto                      bool                                 // Non-interactive, use defaults
+	Minimal                   bool                                 // Only core configs, skip optional
+	IncludeConfigs            []string                             // With Minimal, optional configs to opt back in by name
+	ExcludeConfigs            []string                             // Without Minimal, configs to opt out by name
+	IncludeArchived           bool                                 // Also stow archived (soft-deleted) configs
+	SkipDeps                  bool                                 // Skip dependency installation
+	SkipOptionalDeps          bool                                 // Install critical and core deps only, skip the optional tier
+	SkipExternal              bool                                 // Skip external dependency cloning
+	SkipMachine               bool                                 // Skip machine-specific configuration
+	SkipStow                  bool                                 // Skip stowing configs
+	Overwrite                 bool                                 // Overwrite existing files
+	Force                     bool                                 // Restow configs even when their links are already correct
+	ContinueOnCriticalFailure bool                                 // Keep going even if a critical dep fails to install
+	KeepGoing                 bool                                 // Attempt every item in every step, aggregate all failures
+	Offline                   bool                                 // Skip network operations (external clones/pulls)
+	Lock                      *deps.LockFile                       // Replay external deps at exactly these pinned commits
+	ProgressFunc              func(current, total int, msg string) // Called for progress updates with item counts
 }
 
 // InstallResult tracks the result of the installation
@@ -44,9 +62,16 @@ func (r *InstallResult) HasErrors() bool {
 }
 
 // Install runs the full installation flow
-func Install(cfg *config.Config, dotfilesPath string, opts InstallOptions) (*InstallResult, error) {
+func Install(ctx context.Context, cfg *config.Config, dotfilesPath string, opts InstallOptions) (*InstallResult, error) {
 	result := &InstallResult{}
 
+	// Step 0: Pre-flight - fail with one clear message instead of a
+	// confusing error deep inside a later step
+	progress(opts, "Checking prerequisites...")
+	if err := preflight(cfg, opts); err != nil {
+		return nil, err
+	}
+
 	// Step 1: Detect platform
 	progress(opts, "Detecting platform...")
 	p, err := platform.Detect()
@@ -58,9 +83,14 @@ func Install(cfg *config.Config, dotfilesPath string, opts InstallOptions) (*Ins
 
 	// Step 2: Check and install dependencies
 	if !opts.SkipDeps {
-		if err := installDependencies(cfg, p, opts, result); err != nil {
+		if err := installDependencies(ctx, cfg, p, opts, result); err != nil {
 			result.Errors = append(result.Errors, err)
-			// Don't return - continue with other steps
+			// Stowing without a critical tool (git, stow, ...) is
+			// pointless, so abort unless explicitly told otherwise
+			if errors.Is(err, ErrCriticalDepsFailed) && !opts.ContinueOnCriticalFailure && !opts.KeepGoing {
+				progress(opts, "✗ Aborting install: critical dependencies are missing")
+				return result, nil
+			}
 		}
 	} else {
 		progress(opts, "⊘ Skipping dependency installation")
@@ -68,7 +98,7 @@ func Install(cfg *config.Config, dotfilesPath string, opts InstallOptions) (*Ins
 
 	// Step 3: Stow configs
 	if !opts.SkipStow {
-		if err := stowConfigs(cfg, dotfilesPath, opts, result); err != nil {
+		if err := stowConfigs(ctx, cfg, dotfilesPath, opts, result); err != nil {
 			result.Errors = append(result.Errors, err)
 		}
 	} else {
@@ -77,13 +107,19 @@ func Install(cfg *config.Config, dotfilesPath string, opts InstallOptions) (*Ins
 
 	// Step 4: Clone external dependencies
 	if !opts.SkipExternal {
-		if err := cloneExternal(cfg, dotfilesPath, p, opts, result); err != nil {
+		if err := cloneExternal(ctx, cfg, dotfilesPath, p, opts, result); err != nil {
 			result.Errors = append(result.Errors, err)
 		}
 	} else {
 		progress(opts, "⊘ Skipping external dependencies")
 	}
 
+	// Bail out between steps if the user cancelled
+	if err := ctx.Err(); err != nil {
+		result.Errors = append(result.Errors, err)
+		return result, nil
+	}
+
 	// Step 5: Configure machine-specific settings
 	if !opts.SkipMachine {
 		if err := configureMachine(cfg, opts, result); err != nil {
@@ -96,8 +132,55 @@ func Install(cfg *config.Config, dotfilesPath string, opts InstallOptions) (*Ins
 	return result, nil
 }
 
+// preflight verifies the external tools the install will shell out to are
+// present before any work starts. Tools the dependency step is about to
+// install itself are not required up front.
+func preflight(cfg *config.Config, opts InstallOptions) error {
+	var missing []string
+
+	if !opts.SkipStow && !dependencyListed(cfg, opts, "stow") {
+		if _, err := stow.ValidateStow(); err != nil {
+			missing = append(missing, "GNU stow - install it with your package manager (e.g. 'dnf install stow')")
+		}
+	}
+
+	if !opts.SkipExternal && len(cfg.External) > 0 && !dependencyListed(cfg, opts, "git") {
+		if _, err := exec.LookPath("git"); err != nil {
+			missing = append(missing, "git - needed to clone external dependencies")
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing prerequisites:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+
+	return nil
+}
+
+// dependencyListed reports whether the dependency step will handle the
+// named tool itself, making an up-front check unnecessary.
+func dependencyListed(cfg *config.Config, opts InstallOptions, name string) bool {
+	if opts.SkipDeps {
+		return false
+	}
+
+	for _, tier := range [][]config.DependencyItem{
+		cfg.Dependencies.Critical,
+		cfg.Dependencies.Core,
+		cfg.Dependencies.Optional,
+	} {
+		for _, dep := range tier {
+			if dep.Name == name || dep.Binary == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // installDependencies checks and installs missing dependencies
-func installDependencies(cfg *config.Config, p *platform.Platform, opts InstallOptions, result *InstallResult) error {
+func installDependencies(ctx context.Context, cfg *config.Config, p *platform.Platform, opts InstallOptions, result *InstallResult) error {
 	progress(opts, "\n── Dependencies ──")
 
 	// Check current status
@@ -107,6 +190,13 @@ func installDependencies(cfg *config.Config, p *platform.Platform, opts InstallO
 	}
 
 	missing := checkResult.GetMissing()
+	if opts.SkipOptionalDeps {
+		required := checkResult.GetMissingRequired()
+		if skipped := len(missing) - len(required); skipped > 0 {
+			progress(opts, fmt.Sprintf("⊘ Skipping %d optional dependencies", skipped))
+		}
+		missing = required
+	}
 	if len(missing) == 0 {
 		progress(opts, "✓ All dependencies are installed")
 		return nil
@@ -114,14 +204,23 @@ func installDependencies(cfg *config.Config, p *platform.Platform, opts InstallO
 
 	progress(opts, fmt.Sprintf("Installing %d missing dependencies...", len(missing)))
 
-	installOpts := deps.InstallOptions{
-		OnlyMissing: true,
-		ProgressFunc: func(current, total int, msg string) {
-			progressWithCount(opts, current, total, "  "+msg)
-		},
-	}
+	var installResult *deps.InstallResult
+	err = ui.RunStepWithProgress("Installing dependencies", func(current, total int, msg string) {
+		progressWithCount(opts, current, total, "  "+msg)
+	}, func(report ui.ProgressFunc) error {
+		installOpts := deps.InstallOptions{
+			OnlyMissing:  true,
+			SkipOptional: opts.SkipOptionalDeps,
+			// Default: stop this step at the first hard failure and
+			// move on to the next step
+			FailFast:     !opts.KeepGoing,
+			ProgressFunc: report,
+		}
 
-	installResult, err := deps.Install(cfg, p, installOpts)
+		var installErr error
+		installResult, installErr = deps.Install(ctx, cfg, p, installOpts)
+		return installErr
+	})
 	if err != nil {
 		return fmt.Errorf("failed to install dependencies: %w", err)
 	}
@@ -135,19 +234,105 @@ func installDependencies(cfg *config.Config, p *platform.Platform, opts InstallO
 		progress(opts, fmt.Sprintf("✓ Installed %d dependencies", len(installResult.Installed)))
 	}
 
+	return criticalFailure(cfg, installResult.Failed)
+}
+
+// criticalFailure returns ErrCriticalDepsFailed (wrapped with the first
+// offending name) if any of the failed installs is a critical dependency.
+func criticalFailure(cfg *config.Config, failed []deps.InstallError) error {
+	critical := make(map[string]bool, len(cfg.Dependencies.Critical))
+	for _, dep := range cfg.Dependencies.Critical {
+		critical[dep.Name] = true
+	}
+
+	for _, fail := range failed {
+		if critical[fail.Item.Name] {
+			return fmt.Errorf("%s could not be installed: %w", fail.Item.Name, ErrCriticalDepsFailed)
+		}
+	}
+
 	return nil
 }
 
+// selectOptionalConfigs resolves names against the optional configs,
+// erroring on unknown ones so a typo doesn't silently fall back to
+// core-only
+func selectOptionalConfigs(cfg *config.Config, names []string) ([]config.ConfigItem, error) {
+	byName := make(map[string]config.ConfigItem, len(cfg.Configs.Optional))
+	for _, c := range cfg.Configs.Optional {
+		byName[c.Name] = c
+	}
+
+	var picked []config.ConfigItem
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown optional config '%s' in --include", name)
+		}
+		picked = append(picked, c)
+	}
+	return picked, nil
+}
+
+// excludeConfigs removes the named configs from the list, erroring on
+// names that don't match any declared config
+func excludeConfigs(cfg *config.Config, configs []config.ConfigItem, names []string) ([]config.ConfigItem, error) {
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		if cfg.GetConfigByName(name) == nil {
+			return nil, fmt.Errorf("unknown config '%s' in --exclude", name)
+		}
+		drop[name] = true
+	}
+
+	var kept []config.ConfigItem
+	for _, c := range configs {
+		if !drop[c.Name] {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
+}
+
 // stowConfigs stows all or selected configs, adopting existing symlinks where possible
-func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, result *InstallResult) error {
+func stowConfigs(ctx context.Context, cfg *config.Config, dotfilesPath string, opts InstallOptions, result *InstallResult) error {
 	progress(opts, "\n── Configs ──")
 
+	// Two configs mapping the same target means stow conflicts or one
+	// silently winning - warn before any linking happens
+	if collisions, err := config.DetectTargetCollisions(cfg, dotfilesPath); err == nil && len(collisions) > 0 {
+		progress(opts, fmt.Sprintf("⚠ %d target path(s) are managed by multiple configs:", len(collisions)))
+		for _, col := range collisions {
+			progress(opts, fmt.Sprintf("  %s: %s", col.Target, strings.Join(col.Configs, ", ")))
+		}
+	}
+
 	// Get configs to stow
 	var configs []config.ConfigItem
 	if opts.Minimal {
 		configs = cfg.Configs.Core
+		if len(opts.IncludeConfigs) > 0 {
+			included, err := selectOptionalConfigs(cfg, opts.IncludeConfigs)
+			if err != nil {
+				return err
+			}
+			configs = append(append([]config.ConfigItem{}, configs...), included...)
+		}
 	} else {
 		configs = cfg.GetAllConfigs()
+		if len(opts.ExcludeConfigs) > 0 {
+			kept, err := excludeConfigs(cfg, configs, opts.ExcludeConfigs)
+			if err != nil {
+				return err
+			}
+			configs = kept
+		}
+	}
+
+	// Archived configs are a soft-delete: never stowed unless the user
+	// asked for them explicitly
+	if opts.IncludeArchived {
+		configs = append(configs, cfg.Archived...)
 	}
 
 	if len(configs) == 0 {
@@ -160,7 +345,7 @@ func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, r
 
 	// Build a map of fully-linked configs (can be adopted without re-stowing)
 	fullyLinkedMap := make(map[string]bool)
-	if adoptSummary != nil {
+	if adoptSummary != nil && !opts.Force {
 		for _, ar := range adoptSummary.GetFullyLinked() {
 			fullyLinkedMap[ar.ConfigName] = true
 			result.ConfigsAdopted = append(result.ConfigsAdopted, ar.ConfigName)
@@ -171,14 +356,23 @@ func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, r
 		progress(opts, fmt.Sprintf("✓ Found %d config(s) already symlinked", len(result.ConfigsAdopted)))
 	}
 
-	// Filter out fully-linked configs from those to stow
+	// Filter out fully-linked configs and those for other platforms
 	var configsToStow []config.ConfigItem
+	var platformSkipped []string
 	for _, c := range configs {
+		if result.Platform != nil && !configMatchesPlatform(c, result.Platform) {
+			platformSkipped = append(platformSkipped, c.Name)
+			continue
+		}
 		if !fullyLinkedMap[c.Name] {
 			configsToStow = append(configsToStow, c)
 		}
 	}
 
+	if len(platformSkipped) > 0 {
+		progress(opts, fmt.Sprintf("⊘ Skipped %d configs not for this platform: %s", len(platformSkipped), strings.Join(platformSkipped, ", ")))
+	}
+
 	if len(configsToStow) == 0 {
 		progress(opts, "All configs are already linked")
 		return nil
@@ -186,13 +380,22 @@ func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, r
 
 	progress(opts, fmt.Sprintf("Stowing %d configs...", len(configsToStow)))
 
+	if cfg.Stow.Dotfiles {
+		if err := stow.CheckDotfilesSupport(); err != nil {
+			progress(opts, fmt.Sprintf("⚠ %v", err))
+		}
+	}
+
 	stowOpts := stow.StowOptions{
+		Force:        opts.Force,
+		FailFast:     !opts.KeepGoing,
+		DotfilesMode: cfg.Stow.Dotfiles,
 		ProgressFunc: func(current, total int, msg string) {
 			progressWithCount(opts, current, total, "  "+msg)
 		},
 	}
 
-	stowResult := stow.StowConfigs(dotfilesPath, configsToStow, stowOpts)
+	stowResult := stow.StowConfigs(ctx, dotfilesPath, configsToStow, stowOpts)
 
 	result.ConfigsStowed = stowResult.Success
 	result.ConfigsFailed = stowResult.Failed
@@ -203,6 +406,9 @@ func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, r
 	if len(stowResult.Success) > 0 {
 		progress(opts, fmt.Sprintf("✓ Stowed %d configs", len(stowResult.Success)))
 	}
+	if len(stowResult.AlreadyStowed) > 0 {
+		progress(opts, fmt.Sprintf("✓ %d configs already stowed", len(stowResult.AlreadyStowed)))
+	}
 	if len(stowResult.Skipped) > 0 {
 		progress(opts, fmt.Sprintf("⊘ Skipped %d configs (not found)", len(stowResult.Skipped)))
 	}
@@ -211,7 +417,7 @@ func stowConfigs(cfg *config.Config, dotfilesPath string, opts InstallOptions, r
 }
 
 // cloneExternal clones external dependencies
-func cloneExternal(cfg *config.Config, dotfilesPath string, p *platform.Platform, opts InstallOptions, result *InstallResult) error {
+func cloneExternal(ctx context.Context, cfg *config.Config, dotfilesPath string, p *platform.Platform, opts InstallOptions, result *InstallResult) error {
 	if len(cfg.External) == 0 {
 		return nil
 	}
@@ -219,14 +425,22 @@ func cloneExternal(cfg *config.Config, dotfilesPath string, p *platform.Platform
 	progress(opts, "\n── External Dependencies ──")
 	progress(opts, fmt.Sprintf("Cloning %d external dependencies...", len(cfg.External)))
 
-	extOpts := deps.ExternalOptions{
-		RepoRoot: dotfilesPath,
-		ProgressFunc: func(current, total int, msg string) {
-			progressWithCount(opts, current, total, "  "+msg)
-		},
-	}
+	var extResult *deps.ExternalResult
+	err := ui.RunStepWithProgress("Cloning external dependencies", func(current, total int, msg string) {
+		progressWithCount(opts, current, total, "  "+msg)
+	}, func(report ui.ProgressFunc) error {
+		extOpts := deps.ExternalOptions{
+			Offline:      opts.Offline,
+			FailFast:     !opts.KeepGoing,
+			Lock:         opts.Lock,
+			RepoRoot:     dotfilesPath,
+			ProgressFunc: report,
+		}
 
-	extResult, err := deps.CloneExternal(cfg, p, extOpts)
+		var extErr error
+		extResult, extErr = deps.CloneExternal(ctx, cfg, p, extOpts)
+		return extErr
+	})
 	if err != nil {
 		return fmt.Errorf("failed to clone external dependencies: %w", err)
 	}
@@ -256,7 +470,7 @@ func configureMachine(cfg *config.Config, opts InstallOptions, result *InstallRe
 	progress(opts, "\n── Machine Configuration ──")
 
 	// Check which configs are missing
-	statuses := machine.CheckMachineConfigStatus(cfg)
+	statuses := machine.CheckMachineConfigStatus(cfg, result.Platform)
 	var needsConfig []config.MachinePrompt
 
 	for _, status := range statuses {
@@ -284,6 +498,7 @@ func configureMachine(cfg *config.Config, opts InstallOptions, result *InstallRe
 
 	renderOpts := machine.RenderOptions{
 		Overwrite: opts.Overwrite,
+		KeepGoing: opts.KeepGoing,
 		ProgressFunc: func(current, total int, msg string) {
 			progressWithCount(opts, current, total, "  "+msg)
 		},
@@ -297,7 +512,7 @@ func configureMachine(cfg *config.Config, opts InstallOptions, result *InstallRe
 			continue
 		}
 
-		renderResult, err := machine.RenderAndWrite(&mc, promptResult.Values, renderOpts)
+		renderResult, err := machine.RenderAndWrite(cfg, &mc, promptResult.Values, renderOpts)
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to write %s: %w", mc.ID, err))
 			continue
@@ -327,7 +542,12 @@ func progressWithCount(opts InstallOptions, current, total int, msg string) {
 	}
 }
 
-// Summary returns a human-readable summary of the installation result
+// Summary returns a human-readable summary of the installation result.
+//
+// By default each step stops at its first hard failure but the install
+// still moves on to the remaining steps, so counts here reflect what was
+// attempted before the step bailed out. With InstallOptions.KeepGoing set,
+// every item in every step is attempted and all failures are included.
 func (r *InstallResult) Summary() string {
 	var summary string
 
@@ -396,14 +616,24 @@ func SaveState(cfg *config.Config, dotfilesPath string, result *InstallResult) e
 		st.AddConfig(configName, configName, isCore)
 	}
 
+	// Record packages go4dot installed itself (vs already present) so
+	// they can be cleanly removed later
+	for _, dep := range result.DepsInstalled {
+		st.AddInstalledPackage(dep.Name)
+	}
+
 	// Save external deps
 	for _, ext := range result.ExternalCloned {
 		st.SetExternalDep(ext.ID, ext.Destination, true)
 	}
 
-	// Save machine configs
+	// Save machine configs, recording the generated-content hash so
+	// later overwrite-if-managed runs can spot hand-edited files
 	for _, mc := range result.MachineConfigs {
 		st.SetMachineConfig(mc.ID, mc.Destination, false, false)
+		if !mc.Skipped {
+			st.SetMachineContentHash(mc.ID, machine.ContentHash(mc.Content))
+		}
 	}
 
 	// Update symlink counts so dashboard shows correct sync status
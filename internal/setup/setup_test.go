@@ -1,6 +1,8 @@
 package setup
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -157,7 +159,7 @@ func TestInstallWithSkipAll(t *testing.T) {
 		},
 	}
 
-	result, err := Install(cfg, tmpDir, opts)
+	result, err := Install(context.Background(), cfg, tmpDir, opts)
 	if err != nil {
 		t.Fatalf("Install failed: %v", err)
 	}
@@ -181,6 +183,11 @@ func TestInstallWithSkipAll(t *testing.T) {
 }
 
 func TestInstallMinimalMode(t *testing.T) {
+	// Pre-flight now requires stow up front
+	if !stow.IsStowInstalled() {
+		t.Skip("Stow is not installed, skipping install test")
+	}
+
 	cfg := &config.Config{
 		SchemaVersion: "1.0",
 		Configs: config.ConfigGroups{
@@ -202,7 +209,7 @@ func TestInstallMinimalMode(t *testing.T) {
 		SkipMachine:  true,
 	}
 
-	result, err := Install(cfg, tmpDir, opts)
+	result, err := Install(context.Background(), cfg, tmpDir, opts)
 	if err != nil {
 		t.Fatalf("Install failed: %v", err)
 	}
@@ -214,6 +221,54 @@ func TestInstallMinimalMode(t *testing.T) {
 	}
 }
 
+func TestDependencyListed(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Critical: []config.DependencyItem{{Name: "git"}},
+			Core:     []config.DependencyItem{{Name: "gnu-stow", Binary: "stow"}},
+		},
+	}
+
+	if !dependencyListed(cfg, InstallOptions{}, "git") {
+		t.Error("git should be listed by name")
+	}
+	if !dependencyListed(cfg, InstallOptions{}, "stow") {
+		t.Error("stow should be listed via binary")
+	}
+	if dependencyListed(cfg, InstallOptions{}, "zsh") {
+		t.Error("zsh should not be listed")
+	}
+
+	// With SkipDeps the dependency step never runs, so nothing counts
+	if dependencyListed(cfg, InstallOptions{SkipDeps: true}, "git") {
+		t.Error("nothing should count as listed when deps are skipped")
+	}
+}
+
+func TestCriticalFailure(t *testing.T) {
+	cfg := &config.Config{
+		Dependencies: config.Dependencies{
+			Critical: []config.DependencyItem{{Name: "git"}, {Name: "stow"}},
+			Core:     []config.DependencyItem{{Name: "ripgrep"}},
+		},
+	}
+
+	// Only core failures: not critical
+	failed := []deps.InstallError{
+		{Item: config.DependencyItem{Name: "ripgrep"}},
+	}
+	if err := criticalFailure(cfg, failed); err != nil {
+		t.Errorf("criticalFailure() = %v, want nil for core-only failures", err)
+	}
+
+	// A critical failure yields the sentinel
+	failed = append(failed, deps.InstallError{Item: config.DependencyItem{Name: "stow"}})
+	err := criticalFailure(cfg, failed)
+	if !errors.Is(err, ErrCriticalDepsFailed) {
+		t.Errorf("criticalFailure() = %v, want ErrCriticalDepsFailed", err)
+	}
+}
+
 func TestProgress(t *testing.T) {
 	var received string
 	opts := InstallOptions{
@@ -235,3 +290,148 @@ func TestProgressNoCallback(t *testing.T) {
 	// Should not panic with nil callback
 	progress(opts, "test message")
 }
+
+func TestFilterConfigsByName(t *testing.T) {
+	items := []config.ConfigItem{
+		{Name: "git"},
+		{Name: "nvim"},
+		{Name: "zsh"},
+	}
+
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{
+			name:  "Empty selection keeps all",
+			names: nil,
+			want:  []string{"git", "nvim", "zsh"},
+		},
+		{
+			name:  "Single name",
+			names: []string{"nvim"},
+			want:  []string{"nvim"},
+		},
+		{
+			name:  "Multiple names keep config order",
+			names: []string{"zsh", "git"},
+			want:  []string{"git", "zsh"},
+		},
+		{
+			name:  "Unknown name yields nothing",
+			names: []string{"tmux"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterConfigsByName(items, tt.names)
+			if len(got) != len(tt.want) {
+				t.Fatalf("filterConfigsByName() = %+v, want names %v", got, tt.want)
+			}
+			for i, item := range got {
+				if item.Name != tt.want[i] {
+					t.Errorf("got[%d].Name = %s, want %s", i, item.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectOptionalConfigs(t *testing.T) {
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core:     []config.ConfigItem{{Name: "zsh", Path: "zsh"}},
+			Optional: []config.ConfigItem{{Name: "nvim", Path: "nvim"}, {Name: "tmux", Path: "tmux"}},
+		},
+	}
+
+	picked, err := selectOptionalConfigs(cfg, []string{"nvim"})
+	if err != nil {
+		t.Fatalf("selectOptionalConfigs() failed: %v", err)
+	}
+	if len(picked) != 1 || picked[0].Name != "nvim" {
+		t.Errorf("picked = %v, want [nvim]", picked)
+	}
+
+	if _, err := selectOptionalConfigs(cfg, []string{"nvmi"}); err == nil {
+		t.Error("expected error for unknown config name")
+	}
+	// Core configs are not valid --include targets, only optional ones
+	if _, err := selectOptionalConfigs(cfg, []string{"zsh"}); err == nil {
+		t.Error("expected error for core config in --include")
+	}
+}
+
+func TestExcludeConfigs(t *testing.T) {
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core:     []config.ConfigItem{{Name: "zsh", Path: "zsh"}},
+			Optional: []config.ConfigItem{{Name: "nvim", Path: "nvim"}, {Name: "tmux", Path: "tmux"}},
+		},
+	}
+	all := cfg.GetAllConfigs()
+
+	kept, err := excludeConfigs(cfg, all, []string{"tmux"})
+	if err != nil {
+		t.Fatalf("excludeConfigs() failed: %v", err)
+	}
+	if len(kept) != 2 {
+		t.Errorf("kept %d configs, want 2", len(kept))
+	}
+	for _, c := range kept {
+		if c.Name == "tmux" {
+			t.Error("tmux should have been excluded")
+		}
+	}
+
+	if _, err := excludeConfigs(cfg, all, []string{"nope"}); err == nil {
+		t.Error("expected error for unknown config name")
+	}
+}
+
+func TestConfigMatchesPlatform(t *testing.T) {
+	darwin := &platform.Platform{OS: "darwin", Architecture: "arm64"}
+
+	tests := []struct {
+		name     string
+		item     config.ConfigItem
+		expected bool
+	}{
+		{
+			name:     "linux-only config skipped on darwin",
+			item:     config.ConfigItem{Name: "systemd", Platforms: []string{"linux"}},
+			expected: false,
+		},
+		{
+			name:     "darwin config matches",
+			item:     config.ConfigItem{Name: "aerospace", Platforms: []string{"darwin"}},
+			expected: true,
+		},
+		{
+			name:     "no platform restriction matches everywhere",
+			item:     config.ConfigItem{Name: "git"},
+			expected: true,
+		},
+		{
+			name:     "arch mismatch fails",
+			item:     config.ConfigItem{Name: "x86-tools", Arch: []string{"amd64"}},
+			expected: false,
+		},
+		{
+			name:     "unmet condition fails",
+			item:     config.ConfigItem{Name: "wsl-only", Condition: map[string]string{"wsl": "true"}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configMatchesPlatform(tt.item, darwin); got != tt.expected {
+				t.Errorf("configMatchesPlatform(%s) = %v, want %v", tt.item.Name, got, tt.expected)
+			}
+		})
+	}
+}
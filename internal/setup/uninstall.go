@@ -1,7 +1,9 @@
 package setup
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 
 	"github.com/nvandessel/go4dot/internal/config"
 	"github.com/nvandessel/go4dot/internal/deps"
@@ -12,13 +14,14 @@ import (
 
 // UninstallOptions configures the uninstallation behavior.
 type UninstallOptions struct {
+	DryRun         bool // Only report what would be removed
 	RemoveExternal bool
 	RemoveMachine  bool
 	ProgressFunc   func(current, total int, msg string)
 }
 
 // Uninstall removes the dotfiles installation.
-func Uninstall(cfg *config.Config, dotfilesPath string, st *state.State, opts UninstallOptions) error {
+func Uninstall(ctx context.Context, cfg *config.Config, dotfilesPath string, st *state.State, opts UninstallOptions) error {
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(0, 0, fmt.Sprintf("Uninstalling dotfiles from %s...", dotfilesPath))
 	}
@@ -37,21 +40,37 @@ func Uninstall(cfg *config.Config, dotfilesPath string, st *state.State, opts Un
 		configsToUnstow = cfg.GetAllConfigs()
 	}
 
-	// Unstow configs
+	// Unstow configs. In dry-run mode, list the owned symlinks instead of
+	// invoking stow so the user sees exactly what would be removed.
 	if len(configsToUnstow) > 0 {
-		if opts.ProgressFunc != nil {
-			opts.ProgressFunc(0, 0, fmt.Sprintf("Unstowing %d configs...", len(configsToUnstow)))
-		}
+		if opts.DryRun {
+			for _, item := range configsToUnstow {
+				links, err := stow.OwnedSymlinks(dotfilesPath, item.Path)
+				if err != nil {
+					continue
+				}
+				for _, link := range links {
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(0, 0, fmt.Sprintf("Would remove symlink %s", link))
+					}
+				}
+			}
+		} else {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, fmt.Sprintf("Unstowing %d configs...", len(configsToUnstow)))
+			}
 
-		stowOpts := stow.StowOptions{
-			ProgressFunc: opts.ProgressFunc,
-		}
+			stowOpts := stow.StowOptions{
+				DotfilesMode: cfg.Stow.Dotfiles,
+				ProgressFunc: opts.ProgressFunc,
+			}
 
-		result := stow.UnstowConfigs(dotfilesPath, configsToUnstow, stowOpts)
+			result := stow.UnstowConfigs(ctx, dotfilesPath, configsToUnstow, stowOpts)
 
-		if len(result.Failed) > 0 {
-			if opts.ProgressFunc != nil {
-				opts.ProgressFunc(0, 0, fmt.Sprintf("⚠ %d configs failed to unstow", len(result.Failed)))
+			if len(result.Failed) > 0 {
+				if opts.ProgressFunc != nil {
+					opts.ProgressFunc(0, 0, fmt.Sprintf("⚠ %d configs failed to unstow", len(result.Failed)))
+				}
 			}
 		}
 	}
@@ -64,6 +83,7 @@ func Uninstall(cfg *config.Config, dotfilesPath string, st *state.State, opts Un
 
 		for _, ext := range cfg.External {
 			extOpts := deps.ExternalOptions{
+				DryRun:       opts.DryRun,
 				ProgressFunc: opts.ProgressFunc,
 			}
 
@@ -83,6 +103,7 @@ func Uninstall(cfg *config.Config, dotfilesPath string, st *state.State, opts Un
 
 		for _, mc := range cfg.MachineConfig {
 			renderOpts := machine.RenderOptions{
+				DryRun:       opts.DryRun,
 				ProgressFunc: opts.ProgressFunc,
 			}
 
@@ -95,6 +116,17 @@ func Uninstall(cfg *config.Config, dotfilesPath string, st *state.State, opts Un
 	}
 
 	// Remove state file
+	if opts.DryRun {
+		if opts.ProgressFunc != nil {
+			statePath := "state file"
+			if dir, err := state.GetStateDir(); err == nil {
+				statePath = filepath.Join(dir, state.StateFileName)
+			}
+			opts.ProgressFunc(0, 0, fmt.Sprintf("Would remove %s", statePath))
+		}
+		return nil
+	}
+
 	if err := state.Delete(); err != nil {
 		return fmt.Errorf("failed to remove state file: %w", err)
 	}
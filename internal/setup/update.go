@@ -1,6 +1,7 @@
 package setup
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,17 +13,20 @@ import (
 	"github.com/nvandessel/go4dot/internal/platform"
 	"github.com/nvandessel/go4dot/internal/state"
 	"github.com/nvandessel/go4dot/internal/stow"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // UpdateOptions configures the update behavior.
 type UpdateOptions struct {
 	UpdateExternal bool
 	SkipRestow     bool
+	Offline        bool     // Skip git pull and external updates
+	Configs        []string // Restrict the restow to these config names (empty = all)
 	ProgressFunc   func(current, total int, msg string)
 }
 
 // Update pulls latest changes from git and updates dotfiles.
-func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts UpdateOptions) error {
+func Update(ctx context.Context, cfg *config.Config, dotfilesPath string, st *state.State, opts UpdateOptions) error {
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(0, 0, fmt.Sprintf("Updating dotfiles in %s...", dotfilesPath))
 	}
@@ -33,56 +37,70 @@ func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts Updat
 		return fmt.Errorf("%s is not a git repository", dotfilesPath)
 	}
 
-	// Get current HEAD
-	oldHead, err := gitHead(dotfilesPath)
-	if err != nil {
+	if opts.Offline {
 		if opts.ProgressFunc != nil {
-			opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: could not get current HEAD: %v", err))
+			opts.ProgressFunc(0, 0, "  ⚠ Offline mode: skipping git pull")
+		}
+	} else {
+		// Get current HEAD
+		oldHead, err := gitHead(dotfilesPath)
+		if err != nil {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: could not get current HEAD: %v", err))
+			}
 		}
-	}
-
-	// Run git pull
-	if opts.ProgressFunc != nil {
-		opts.ProgressFunc(0, 0, "Pulling latest changes...")
-	}
-	pullCmd := exec.Command("git", "pull", "--rebase")
-	pullCmd.Dir = dotfilesPath
-	if output, err := pullCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git pull failed: %w\nOutput: %s", err, string(output))
-	}
 
-	// Get new HEAD
-	newHead, err := gitHead(dotfilesPath)
-	if err != nil {
+		// Run git pull
 		if opts.ProgressFunc != nil {
-			opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: could not get new HEAD: %v", err))
+			opts.ProgressFunc(0, 0, "Pulling latest changes...")
+		}
+		pullCmd := exec.CommandContext(ctx, "git", "pull", "--rebase")
+		pullCmd.Dir = dotfilesPath
+		verbose.Command(pullCmd.Args...)
+		if output, err := pullCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git pull failed: %w\nOutput: %s", err, string(output))
 		}
-	}
 
-	// Show what changed
-	if oldHead != "" && newHead != "" && oldHead != newHead {
-		if opts.ProgressFunc != nil {
-			opts.ProgressFunc(0, 0, "Changes detected. Reloading config if needed...")
+		// Get new HEAD
+		newHead, err := gitHead(dotfilesPath)
+		if err != nil {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: could not get new HEAD: %v", err))
+			}
 		}
 
-		// Check if config file changed
-		configChanged, _ := gitFileChanged(dotfilesPath, oldHead, newHead, config.ConfigFileName)
-		if configChanged {
+		// Show what changed
+		if oldHead != "" && newHead != "" && oldHead != newHead {
 			if opts.ProgressFunc != nil {
-				opts.ProgressFunc(0, 0, fmt.Sprintf("  Note: %s was updated. Reloading config...", config.ConfigFileName))
+				opts.ProgressFunc(0, 0, "Changes detected. Reloading config if needed...")
 			}
-			newCfg, err := config.LoadFromPath(dotfilesPath)
-			if err == nil {
-				*cfg = *newCfg
-			} else {
+
+			// Check if the config file changed, under any of the names
+			// the loader recognizes (.gopherdot.yaml, .go4dot.yml, ...)
+			changedConfigFile := ""
+			for _, name := range config.ConfigFileNames {
+				if changed, _ := gitFileChanged(dotfilesPath, oldHead, newHead, name); changed {
+					changedConfigFile = name
+					break
+				}
+			}
+			if changedConfigFile != "" {
 				if opts.ProgressFunc != nil {
-					opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: failed to reload config: %v", err))
+					opts.ProgressFunc(0, 0, fmt.Sprintf("  Note: %s was updated. Reloading config...", changedConfigFile))
+				}
+				newCfg, err := config.LoadFromPath(dotfilesPath)
+				if err == nil {
+					*cfg = *newCfg
+				} else {
+					if opts.ProgressFunc != nil {
+						opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: failed to reload config: %v", err))
+					}
 				}
 			}
-		}
-	} else {
-		if opts.ProgressFunc != nil {
-			opts.ProgressFunc(0, 0, "Already up to date.")
+		} else {
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(0, 0, "Already up to date.")
+			}
 		}
 	}
 
@@ -93,6 +111,7 @@ func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts Updat
 		}
 
 		stowOpts := stow.StowOptions{
+			DotfilesMode: cfg.Stow.Dotfiles,
 			ProgressFunc: opts.ProgressFunc,
 		}
 
@@ -110,8 +129,11 @@ func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts Updat
 			configsToRestow = cfg.Configs.Core
 		}
 
+		// Narrow to an explicit selection when one was given
+		configsToRestow = filterConfigsByName(configsToRestow, opts.Configs)
+
 		if len(configsToRestow) > 0 {
-			result := stow.RestowConfigs(dotfilesPath, configsToRestow, stowOpts)
+			result := stow.RestowConfigs(ctx, dotfilesPath, configsToRestow, stowOpts)
 
 			if len(result.Failed) > 0 {
 				if opts.ProgressFunc != nil {
@@ -139,11 +161,12 @@ func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts Updat
 		} else {
 			extOpts := deps.ExternalOptions{
 				Update:       true,
+				Offline:      opts.Offline,
 				RepoRoot:     dotfilesPath,
 				ProgressFunc: opts.ProgressFunc,
 			}
 
-			result, err := deps.CloneExternal(cfg, p, extOpts)
+			result, err := deps.CloneExternal(ctx, cfg, p, extOpts)
 			if err != nil {
 				if opts.ProgressFunc != nil {
 					opts.ProgressFunc(0, 0, fmt.Sprintf("  ⚠ Warning: failed to update externals: %v", err))
@@ -176,6 +199,27 @@ func Update(cfg *config.Config, dotfilesPath string, st *state.State, opts Updat
 	return nil
 }
 
+// filterConfigsByName keeps only the configs whose names appear in
+// names. An empty names list keeps everything.
+func filterConfigsByName(items []config.ConfigItem, names []string) []config.ConfigItem {
+	if len(names) == 0 {
+		return items
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var filtered []config.ConfigItem
+	for _, item := range items {
+		if wanted[item.Name] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
 // gitHead returns the current HEAD commit hash
 func gitHead(dir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package state
+
+import "os"
+
+// Advisory locking is not supported on this platform; locking degrades to
+// a no-op and saves are still atomic via write-temp-then-rename.
+func flockExclusive(f *os.File) error { return nil }
+
+func flockRelease(f *os.File) error { return nil }
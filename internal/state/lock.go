@@ -0,0 +1,64 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFileName is the advisory lock file next to the state file
+const lockFileName = "state.lock"
+
+// acquireLock takes an exclusive advisory lock on the state lock file so
+// two go4dot processes cannot modify state concurrently. It returns a
+// release function.
+func acquireLock() (func(), error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(stateDir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := flockExclusive(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock state file: %w", err)
+	}
+
+	return func() {
+		flockRelease(f)
+		f.Close()
+	}, nil
+}
+
+// WithLock loads the state (creating a fresh one if none exists), passes
+// it to fn, and saves it afterwards, all under the state lock. Use it for
+// compound read-modify-write operations.
+func WithLock(fn func(*State) error) error {
+	release, err := acquireLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	st, err := load()
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		st = New()
+	}
+
+	if err := fn(st); err != nil {
+		return err
+	}
+
+	return st.save()
+}
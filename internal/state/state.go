@@ -5,29 +5,36 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 )
 
 const (
-	// StateDir is the directory where state files are stored
-	StateDir = ".config/go4dot"
+	// StateDirName is the directory name used under the XDG state home
+	StateDirName = "go4dot"
 	// StateFileName is the name of the state file
 	StateFileName = "state.json"
 	// StateVersion is the current state file format version
 	StateVersion = "1.0"
+	// StateDirEnv overrides the state directory (mainly for testing)
+	StateDirEnv = "GO4DOT_STATE_DIR"
+
+	// legacyStateDir is the pre-XDG location of state files, relative to $HOME
+	legacyStateDir = ".config/go4dot"
 )
 
 // State represents the installation state of go4dot
 type State struct {
-	Version       string                   `json:"version"`
-	InstalledAt   time.Time                `json:"installed_at"`
-	LastUpdate    time.Time                `json:"last_update"`
-	DotfilesPath  string                   `json:"dotfiles_path"`
-	Platform      PlatformState            `json:"platform"`
-	Configs       []ConfigState            `json:"configs"`
-	MachineConfig map[string]MachineState  `json:"machine_config"`
-	ExternalDeps  map[string]ExternalState `json:"external_deps"`
-	SymlinkCounts map[string]int           `json:"symlink_counts,omitempty"` // File count per config for quick drift detection
+	Version           string                   `json:"version"`
+	InstalledAt       time.Time                `json:"installed_at"`
+	LastUpdate        time.Time                `json:"last_update"`
+	DotfilesPath      string                   `json:"dotfiles_path"`
+	Platform          PlatformState            `json:"platform"`
+	Configs           []ConfigState            `json:"configs"`
+	MachineConfig     map[string]MachineState  `json:"machine_config"`
+	ExternalDeps      map[string]ExternalState `json:"external_deps"`
+	SymlinkCounts     map[string]int           `json:"symlink_counts,omitempty"`     // File count per config for quick drift detection
+	InstalledPackages []string                 `json:"installed_packages,omitempty"` // Packages go4dot installed (vs already present)
 }
 
 // PlatformState stores detected platform information
@@ -52,6 +59,12 @@ type MachineState struct {
 	CreatedAt  time.Time `json:"created_at"`
 	HasGPG     bool      `json:"has_gpg,omitempty"`
 	HasSSH     bool      `json:"has_ssh,omitempty"`
+	// Values holds non-secret prompt values from the last configure run,
+	// so partial reconfiguration can carry over unchanged fields
+	Values map[string]string `json:"values,omitempty"`
+	// ContentHash is the hash of the last generated file content, used to
+	// tell untouched generated files apart from hand-edited ones
+	ContentHash string `json:"content_hash,omitempty"`
 }
 
 // ExternalState tracks an external dependency
@@ -74,29 +87,124 @@ func New() *State {
 
 // GetStatePath returns the full path to the state file
 func GetStatePath() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, StateFileName), nil
+}
+
+// GetStateDir returns the state directory path, following the XDG Base
+// Directory spec: $GO4DOT_STATE_DIR override, then $XDG_STATE_HOME/go4dot,
+// then ~/Library/Application Support/go4dot on macOS and
+// ~/.local/state/go4dot everywhere else.
+func GetStateDir() (string, error) {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, StateDirName), nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, StateDir, StateFileName), nil
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Application Support", StateDirName), nil
+	}
+	return filepath.Join(home, ".local", "state", StateDirName), nil
 }
 
-// GetStateDir returns the state directory path
-func GetStateDir() (string, error) {
+// GetCacheDir returns the cache directory path: $XDG_CACHE_HOME/go4dot,
+// falling back to ~/Library/Caches/go4dot on macOS and ~/.cache/go4dot
+// everywhere else
+func GetCacheDir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, StateDirName), nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, StateDir), nil
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Caches", StateDirName), nil
+	}
+	return filepath.Join(home, ".cache", StateDirName), nil
 }
 
-// Load reads the state from disk
+// legacyStatePaths returns earlier state file locations, oldest last:
+// the XDG default (for platforms whose resolved dir has since changed,
+// like macOS) and the original ~/.config location
+func legacyStatePaths() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".local", "state", StateDirName, StateFileName),
+		filepath.Join(home, legacyStateDir, StateFileName),
+	}
+}
+
+// migrateLegacyState moves a state file from an old location to the
+// current state directory. Returns true if a file was migrated.
+func migrateLegacyState(statePath string) bool {
+	for _, legacyPath := range legacyStatePaths() {
+		if legacyPath == statePath {
+			continue
+		}
+		if _, err := os.Stat(legacyPath); err != nil {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+			return false
+		}
+
+		if err := os.Rename(legacyPath, statePath); err != nil {
+			// Fall back to copy if rename fails (e.g. cross-device)
+			data, readErr := os.ReadFile(legacyPath)
+			if readErr != nil {
+				return false
+			}
+			if writeErr := os.WriteFile(statePath, data, 0644); writeErr != nil {
+				return false
+			}
+			_ = os.Remove(legacyPath)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Load reads the state from disk under the state lock
 func Load() (*State, error) {
+	release, err := acquireLock()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return load()
+}
+
+// load reads the state from disk; callers must hold the state lock
+func load() (*State, error) {
 	statePath, err := GetStatePath()
 	if err != nil {
 		return nil, err
 	}
 
+	// Migrate a legacy state file into the XDG location on first load
+	if _, err := os.Stat(statePath); os.IsNotExist(err) {
+		migrateLegacyState(statePath)
+	}
+
 	data, err := os.ReadFile(statePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -113,8 +221,19 @@ func Load() (*State, error) {
 	return &state, nil
 }
 
-// Save writes the state to disk
+// Save writes the state to disk under the state lock
 func (s *State) Save() error {
+	release, err := acquireLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.save()
+}
+
+// save writes the state to disk; callers must hold the state lock
+func (s *State) save() error {
 	stateDir, err := GetStateDir()
 	if err != nil {
 		return err
@@ -138,9 +257,32 @@ func (s *State) Save() error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(statePath, data, 0644); err != nil {
+	// Write to a temp file and rename so a crash mid-write can never
+	// leave a truncated state file behind
+	tmp, err := os.CreateTemp(stateDir, StateFileName+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to set state file mode: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close temp state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), statePath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
 
 	return nil
 }
@@ -248,6 +390,28 @@ func (s *State) SetMachineConfig(id string, configPath string, hasGPG, hasSSH bo
 	}
 }
 
+// SetMachineValues records the prompt values from the last configure run
+// for a machine config, preserving the rest of its state entry
+func (s *State) SetMachineValues(id string, values map[string]string) {
+	if s.MachineConfig == nil {
+		s.MachineConfig = make(map[string]MachineState)
+	}
+	ms := s.MachineConfig[id]
+	ms.Values = values
+	s.MachineConfig[id] = ms
+}
+
+// SetMachineContentHash records the hash of the content last generated
+// for a machine config, preserving the rest of its state entry
+func (s *State) SetMachineContentHash(id, hash string) {
+	if s.MachineConfig == nil {
+		s.MachineConfig = make(map[string]MachineState)
+	}
+	ms := s.MachineConfig[id]
+	ms.ContentHash = hash
+	s.MachineConfig[id] = ms
+}
+
 // RemoveMachineConfig removes a machine config from state
 func (s *State) RemoveMachineConfig(id string) {
 	delete(s.MachineConfig, id)
@@ -277,6 +441,36 @@ func (s *State) RemoveSymlinkCount(configName string) {
 	}
 }
 
+// AddInstalledPackage records a package that go4dot installed itself
+func (s *State) AddInstalledPackage(name string) {
+	for _, pkg := range s.InstalledPackages {
+		if pkg == name {
+			return
+		}
+	}
+	s.InstalledPackages = append(s.InstalledPackages, name)
+}
+
+// RemoveInstalledPackage removes a package from the installed list
+func (s *State) RemoveInstalledPackage(name string) {
+	for i, pkg := range s.InstalledPackages {
+		if pkg == name {
+			s.InstalledPackages = append(s.InstalledPackages[:i], s.InstalledPackages[i+1:]...)
+			return
+		}
+	}
+}
+
+// HasInstalledPackage checks if go4dot installed a package
+func (s *State) HasInstalledPackage(name string) bool {
+	for _, pkg := range s.InstalledPackages {
+		if pkg == name {
+			return true
+		}
+	}
+	return false
+}
+
 // AdoptConfigs adds multiple configs to state at once (for adoption)
 func (s *State) AdoptConfigs(configs []ConfigState) {
 	for _, cfg := range configs {
@@ -1,8 +1,12 @@
 package state
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
 )
 
@@ -152,16 +156,45 @@ func TestStateMachineConfig(t *testing.T) {
 	}
 }
 
+func TestStateInstalledPackages(t *testing.T) {
+	s := New()
+
+	s.AddInstalledPackage("ripgrep")
+	s.AddInstalledPackage("fzf")
+	s.AddInstalledPackage("ripgrep") // Duplicate should be ignored
+
+	if len(s.InstalledPackages) != 2 {
+		t.Errorf("len(InstalledPackages) = %d, want 2", len(s.InstalledPackages))
+	}
+
+	if !s.HasInstalledPackage("ripgrep") {
+		t.Error("HasInstalledPackage(ripgrep) should be true")
+	}
+	if s.HasInstalledPackage("stow") {
+		t.Error("HasInstalledPackage(stow) should be false")
+	}
+
+	s.RemoveInstalledPackage("ripgrep")
+	if s.HasInstalledPackage("ripgrep") {
+		t.Error("ripgrep should be removed")
+	}
+	if len(s.InstalledPackages) != 1 {
+		t.Errorf("len(InstalledPackages) = %d, want 1", len(s.InstalledPackages))
+	}
+}
+
 func TestStateSaveLoad(t *testing.T) {
 	// Create temp directory for state
 	tmpDir := t.TempDir()
-	stateDir := filepath.Join(tmpDir, ".config", "go4dot")
+	stateDir := filepath.Join(tmpDir, ".local", "state", "go4dot")
 	statePath := filepath.Join(stateDir, "state.json")
 
 	// Override home for testing
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", tmpDir)
 	defer os.Setenv("HOME", origHome)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv(StateDirEnv, "")
 
 	// Create and save state
 	s := New()
@@ -225,6 +258,8 @@ func TestLoadNonExistent(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", tmpDir)
 	defer os.Setenv("HOME", origHome)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv(StateDirEnv, "")
 
 	// Load should return nil, nil for non-existent
 	loaded, err := Load()
@@ -243,6 +278,8 @@ func TestExists(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", tmpDir)
 	defer os.Setenv("HOME", origHome)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv(StateDirEnv, "")
 
 	// Initially should not exist
 	if Exists() {
@@ -270,3 +307,146 @@ func TestExists(t *testing.T) {
 		t.Error("Exists() should be false after Delete()")
 	}
 }
+
+func TestWithLockConcurrentSaves(t *testing.T) {
+	t.Setenv(StateDirEnv, t.TempDir())
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- WithLock(func(s *State) error {
+				s.AddInstalledPackage(fmt.Sprintf("pkg-%d", i))
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("WithLock() failed: %v", err)
+		}
+	}
+
+	// Every write must survive: no lost updates, no corrupt file
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded == nil {
+		t.Fatal("Load() returned nil")
+	}
+	if len(loaded.InstalledPackages) != workers {
+		t.Errorf("len(InstalledPackages) = %d, want %d", len(loaded.InstalledPackages), workers)
+	}
+}
+
+func TestGetStateDirOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	// Explicit override wins
+	t.Setenv(StateDirEnv, "/custom/state")
+	dir, err := GetStateDir()
+	if err != nil {
+		t.Fatalf("GetStateDir() failed: %v", err)
+	}
+	if dir != "/custom/state" {
+		t.Errorf("GetStateDir() = %s, want /custom/state", dir)
+	}
+
+	// XDG_STATE_HOME next
+	t.Setenv(StateDirEnv, "")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	dir, err = GetStateDir()
+	if err != nil {
+		t.Fatalf("GetStateDir() failed: %v", err)
+	}
+	if dir != filepath.Join("/xdg/state", "go4dot") {
+		t.Errorf("GetStateDir() = %s, want /xdg/state/go4dot", dir)
+	}
+
+	// Default falls back to ~/.local/state
+	t.Setenv("XDG_STATE_HOME", "")
+	dir, err = GetStateDir()
+	if err != nil {
+		t.Fatalf("GetStateDir() failed: %v", err)
+	}
+	if dir != filepath.Join(tmpDir, ".local", "state", "go4dot") {
+		t.Errorf("GetStateDir() = %s, want default under ~/.local/state", dir)
+	}
+}
+
+func TestLoadMigratesLegacyState(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv(StateDirEnv, "")
+
+	// Write a state file at the legacy ~/.config location
+	legacyDir := filepath.Join(tmpDir, ".config", "go4dot")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatalf("Failed to create legacy dir: %v", err)
+	}
+
+	s := New()
+	s.DotfilesPath = "/home/user/dotfiles"
+	data, _ := json.MarshalIndent(s, "", "  ")
+	legacyPath := filepath.Join(legacyDir, "state.json")
+	if err := os.WriteFile(legacyPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write legacy state: %v", err)
+	}
+
+	// Load should migrate and still return the state
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded == nil || loaded.DotfilesPath != "/home/user/dotfiles" {
+		t.Fatalf("Load() did not return migrated state: %+v", loaded)
+	}
+
+	// The file should now live at the XDG location
+	newPath := filepath.Join(tmpDir, ".local", "state", "go4dot", "state.json")
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("State file should exist at XDG location: %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Error("Legacy state file should have been removed")
+	}
+}
+
+func TestGetCacheDir(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("default cache path differs on this platform")
+	}
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	// XDG_CACHE_HOME wins
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	dir, err := GetCacheDir()
+	if err != nil {
+		t.Fatalf("GetCacheDir() failed: %v", err)
+	}
+	if dir != filepath.Join("/xdg/cache", StateDirName) {
+		t.Errorf("GetCacheDir() = %s, want /xdg/cache/%s", dir, StateDirName)
+	}
+
+	// Default falls back to ~/.cache
+	t.Setenv("XDG_CACHE_HOME", "")
+	dir, err = GetCacheDir()
+	if err != nil {
+		t.Fatalf("GetCacheDir() failed: %v", err)
+	}
+	if dir != filepath.Join(tmpDir, ".cache", StateDirName) {
+		t.Errorf("GetCacheDir() = %s, want %s/.cache/%s", dir, tmpDir, StateDirName)
+	}
+}
@@ -1,10 +1,12 @@
 package stow
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
 	"github.com/nvandessel/go4dot/internal/state"
 )
 
@@ -83,7 +85,10 @@ func (s *AdoptSummary) GetNotLinked() []AdoptResult {
 // This is used to detect pre-existing stow setups that should be adopted into go4dot state.
 func ScanExistingSymlinks(cfg *config.Config, dotfilesPath string) (*AdoptSummary, error) {
 	summary := &AdoptSummary{}
-	home := os.Getenv("HOME")
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
 
 	// Process core configs
 	for _, configItem := range cfg.Configs.Core {
@@ -227,6 +232,9 @@ func AdoptExistingSymlinks(cfg *config.Config, dotfilesPath string, st *state.St
 
 // GetConfigLinkStatus returns the link status for a single config
 func GetConfigLinkStatus(configItem config.ConfigItem, dotfilesPath string) (*AdoptResult, error) {
-	home := os.Getenv("HOME")
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
 	return scanConfigSymlinks(configItem, dotfilesPath, home, false)
 }
@@ -0,0 +1,202 @@
+package stow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
+	"github.com/nvandessel/go4dot/internal/ui"
+)
+
+// ConflictAction is the per-file decision made during conflict resolution
+type ConflictAction int
+
+const (
+	ConflictSkip      ConflictAction = iota // Leave the existing file alone
+	ConflictOverwrite                       // Delete the existing file, stow ours
+	ConflictBackup                          // Rename to .g4d-backup, then stow
+	ConflictAdopt                           // Move the existing file into dotfiles, then stow
+)
+
+func (a ConflictAction) String() string {
+	switch a {
+	case ConflictOverwrite:
+		return "overwrite"
+	case ConflictBackup:
+		return "backup"
+	case ConflictAdopt:
+		return "adopt"
+	}
+	return "skip"
+}
+
+// ConflictPolicy maps a conflict's target path to the chosen action
+type ConflictPolicy map[string]ConflictAction
+
+// ApplyConflictPolicy executes the per-file decisions from a policy.
+// Conflicts without an entry default to skip. Returns the conflicts
+// that were left in place.
+func ApplyConflictPolicy(conflicts []ConflictFile, policy ConflictPolicy) ([]ConflictFile, error) {
+	var skipped []ConflictFile
+
+	for _, c := range conflicts {
+		action := policy[c.TargetPath]
+
+		var err error
+		switch action {
+		case ConflictSkip:
+			skipped = append(skipped, c)
+		case ConflictOverwrite:
+			err = RemoveConflict(c)
+		case ConflictBackup:
+			err = BackupConflict(c)
+		case ConflictAdopt:
+			err = AdoptConflict(c)
+		}
+
+		if err != nil {
+			return skipped, fmt.Errorf("failed to %s %s: %w", action, c.TargetPath, err)
+		}
+	}
+
+	return skipped, nil
+}
+
+// AdoptConflict moves the existing file into the dotfiles package,
+// replacing the repo version. This mirrors 'stow --adopt' for a single
+// file: the user's current version becomes the tracked one.
+func AdoptConflict(conflict ConflictFile) error {
+	if conflict.IsDir {
+		return fmt.Errorf("cannot adopt directory %s", conflict.TargetPath)
+	}
+	return os.Rename(conflict.TargetPath, conflict.SourcePath)
+}
+
+// conflictRelPath returns the conflict's path relative to its config's
+// package directory, for use as a stow exclude pattern.
+func conflictRelPath(cfg *config.Config, dotfilesPath string, c ConflictFile) string {
+	for _, item := range cfg.GetAllConfigs() {
+		if item.Name != c.ConfigName {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Join(dotfilesPath, item.Path), c.SourcePath)
+		if err != nil {
+			return ""
+		}
+		return rel
+	}
+	return ""
+}
+
+type conflictItem struct {
+	conflict ConflictFile
+	action   ConflictAction
+}
+
+func (i conflictItem) Title() string {
+	home, _ := pathutil.HomeDir()
+	rel, err := filepath.Rel(home, i.conflict.TargetPath)
+	if err != nil {
+		return i.conflict.TargetPath
+	}
+	return "~/" + rel
+}
+
+func (i conflictItem) Description() string {
+	return fmt.Sprintf("%s • %s", i.conflict.ConfigName, i.action)
+}
+
+func (i conflictItem) FilterValue() string { return i.conflict.TargetPath }
+
+type conflictModel struct {
+	list      list.Model
+	confirmed *bool
+}
+
+func (m conflictModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m conflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "enter":
+			*m.confirmed = true
+			return m, tea.Quit
+		case "s", "o", "b", "a", " ":
+			if i, ok := m.list.SelectedItem().(conflictItem); ok {
+				switch msg.String() {
+				case "s":
+					i.action = ConflictSkip
+				case "o":
+					i.action = ConflictOverwrite
+				case "b":
+					i.action = ConflictBackup
+				case "a":
+					i.action = ConflictAdopt
+				case " ":
+					i.action = (i.action + 1) % 4
+				}
+				// Directories can't be adopted per-file
+				if i.action == ConflictAdopt && i.conflict.IsDir {
+					i.action = ConflictSkip
+				}
+				return m, m.list.SetItem(m.list.Index(), i)
+			}
+		}
+	case tea.WindowSizeMsg:
+		h, v := ui.BoxStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m conflictModel) View() string {
+	return ui.BoxStyle.Render(m.list.View())
+}
+
+// ResolveConflictsTUI presents each conflict in a list where the user
+// picks a per-file action: [s]kip, [o]verwrite, [b]ackup (default),
+// [a]dopt, or space to cycle. Enter applies the decisions; q cancels.
+// Returns nil (and no error) if the user cancelled.
+func ResolveConflictsTUI(conflicts []ConflictFile) (ConflictPolicy, error) {
+	items := make([]list.Item, len(conflicts))
+	for i, c := range conflicts {
+		items[i] = conflictItem{conflict: c, action: ConflictBackup}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = fmt.Sprintf("%d conflicting file(s) • s skip / o overwrite / b backup / a adopt • enter applies", len(conflicts))
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+
+	confirmed := false
+	m := conflictModel{list: l, confirmed: &confirmed}
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("conflict resolution failed: %w", err)
+	}
+	if !confirmed {
+		return nil, nil
+	}
+
+	policy := make(ConflictPolicy, len(conflicts))
+	for _, it := range final.(conflictModel).list.Items() {
+		if ci, ok := it.(conflictItem); ok {
+			policy[ci.conflict.TargetPath] = ci.action
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,116 @@
+package stow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConflictActionString(t *testing.T) {
+	tests := []struct {
+		action ConflictAction
+		want   string
+	}{
+		{ConflictSkip, "skip"},
+		{ConflictOverwrite, "overwrite"},
+		{ConflictBackup, "backup"},
+		{ConflictAdopt, "adopt"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.action.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestApplyConflictPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	makeConflict := func(name, content string) ConflictFile {
+		source := filepath.Join(tmpDir, "dotfiles", name)
+		target := filepath.Join(tmpDir, "home", name)
+		os.MkdirAll(filepath.Dir(source), 0755)
+		os.MkdirAll(filepath.Dir(target), 0755)
+		os.WriteFile(source, []byte("repo version"), 0644)
+		os.WriteFile(target, []byte(content), 0644)
+		return ConflictFile{ConfigName: "test", SourcePath: source, TargetPath: target}
+	}
+
+	skipC := makeConflict(".skipped", "keep me")
+	overwriteC := makeConflict(".overwritten", "delete me")
+	backupC := makeConflict(".backed-up", "back me up")
+	adoptC := makeConflict(".adopted", "user version")
+
+	conflicts := []ConflictFile{skipC, overwriteC, backupC, adoptC}
+	policy := ConflictPolicy{
+		skipC.TargetPath:      ConflictSkip,
+		overwriteC.TargetPath: ConflictOverwrite,
+		backupC.TargetPath:    ConflictBackup,
+		adoptC.TargetPath:     ConflictAdopt,
+	}
+
+	skipped, err := ApplyConflictPolicy(conflicts, policy)
+	if err != nil {
+		t.Fatalf("ApplyConflictPolicy() failed: %v", err)
+	}
+
+	if len(skipped) != 1 || skipped[0].TargetPath != skipC.TargetPath {
+		t.Errorf("skipped = %+v, want only %s", skipped, skipC.TargetPath)
+	}
+
+	// Skipped file is untouched
+	if _, err := os.Stat(skipC.TargetPath); err != nil {
+		t.Errorf("skipped file should still exist: %v", err)
+	}
+
+	// Overwritten file is gone
+	if _, err := os.Stat(overwriteC.TargetPath); !os.IsNotExist(err) {
+		t.Errorf("overwritten file should be removed")
+	}
+
+	// Backed-up file moved aside
+	if _, err := os.Stat(backupC.TargetPath + ".g4d-backup"); err != nil {
+		t.Errorf("backup file should exist: %v", err)
+	}
+
+	// Adopted file replaced the repo version
+	content, err := os.ReadFile(adoptC.SourcePath)
+	if err != nil {
+		t.Fatalf("reading adopted source: %v", err)
+	}
+	if string(content) != "user version" {
+		t.Errorf("adopted source content = %q, want %q", content, "user version")
+	}
+	if _, err := os.Stat(adoptC.TargetPath); !os.IsNotExist(err) {
+		t.Errorf("adopted target should have been moved")
+	}
+}
+
+func TestApplyConflictPolicyDefaultsToSkip(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, ".zshrc")
+	os.WriteFile(target, []byte("content"), 0644)
+
+	conflicts := []ConflictFile{{ConfigName: "zsh", TargetPath: target}}
+
+	skipped, err := ApplyConflictPolicy(conflicts, ConflictPolicy{})
+	if err != nil {
+		t.Fatalf("ApplyConflictPolicy() failed: %v", err)
+	}
+
+	if len(skipped) != 1 {
+		t.Errorf("len(skipped) = %d, want 1", len(skipped))
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("file should be untouched: %v", err)
+	}
+}
+
+func TestAdoptConflictRejectsDirectory(t *testing.T) {
+	conflict := ConflictFile{TargetPath: "/tmp/some-dir", IsDir: true}
+
+	if err := AdoptConflict(conflict); err == nil {
+		t.Error("AdoptConflict() should fail for directories")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
 	"github.com/nvandessel/go4dot/internal/state"
 )
 
@@ -38,7 +39,10 @@ func (s *DriftSummary) HasDrift() bool {
 // It identifies exactly which files are new, missing, or in conflict.
 func FullDriftCheck(cfg *config.Config, dotfilesPath string) (*DriftSummary, error) {
 	var results []DriftResult
-	home := os.Getenv("HOME")
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
 
 	allConfigs := cfg.GetAllConfigs()
 	for _, configItem := range allConfigs {
@@ -142,6 +146,50 @@ func FullDriftCheck(cfg *config.Config, dotfilesPath string) (*DriftSummary, err
 	return summary, nil
 }
 
+// OwnedSymlinks returns the target paths in $HOME that are symlinks into
+// the given config package directory. Used to preview what an unstow
+// would remove.
+func OwnedSymlinks(dotfilesPath, configPath string) ([]string, error) {
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	pkgDir := filepath.Join(dotfilesPath, configPath)
+
+	var owned []string
+	err = filepath.Walk(pkgDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(pkgDir, path)
+		targetPath := filepath.Join(home, relPath)
+
+		targetInfo, err := os.Lstat(targetPath)
+		if err != nil || targetInfo.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		linkDest, err := os.Readlink(targetPath)
+		if err != nil {
+			return nil
+		}
+		if !filepath.IsAbs(linkDest) {
+			linkDest = filepath.Join(filepath.Dir(targetPath), linkDest)
+		}
+		if filepath.Clean(linkDest) == path {
+			owned = append(owned, targetPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return owned, nil
+}
+
 // UpdateSymlinkCounts updates the stored file counts for all configs
 func UpdateSymlinkCounts(cfg *config.Config, dotfilesPath string, st *state.State) error {
 	allConfigs := cfg.GetAllConfigs()
@@ -205,7 +253,10 @@ type ConflictFile struct {
 // DetectConflicts checks for existing files in home that would block stow
 func DetectConflicts(cfg *config.Config, dotfilesPath string) ([]ConflictFile, error) {
 	var conflicts []ConflictFile
-	home := os.Getenv("HOME")
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
 
 	allConfigs := cfg.GetAllConfigs()
 	for _, configItem := range allConfigs {
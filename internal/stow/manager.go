@@ -1,42 +1,139 @@
 package stow
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
+	"github.com/nvandessel/go4dot/internal/verbose"
 )
 
 // StowResult represents the result of a stow operation
 type StowResult struct {
 	Success []string // Successfully stowed configs
 	Failed  []StowError
-	Skipped []string // Skipped (already stowed, conflicts, etc.)
+	Skipped []string // Skipped (directory not found, conflicts, etc.)
+	// AlreadyStowed configs had every link correct before the run and
+	// were not restowed (override with StowOptions.Force)
+	AlreadyStowed []string
+	Links         map[string][]string // Symlinks created per config, parsed from stow's verbose output, plus rendered template files
+}
+
+// LinkCount returns the total number of symlinks created across configs
+func (r *StowResult) LinkCount() int {
+	var n int
+	for _, links := range r.Links {
+		n += len(links)
+	}
+	return n
 }
 
 // StowError represents a stow operation error
 type StowError struct {
 	ConfigName string
 	Error      error
+	Hint       string // Suggested fix for recognized failure modes ("" when none)
+}
+
+// StowRunError is returned when the stow command fails; it keeps the
+// raw output and, for recognized failure modes (unwritable target,
+// immutable files), a suggested fix
+type StowRunError struct {
+	Op     string // "stow", "unstow", "restow"
+	Err    error
+	Output string
+	Hint   string // Suggested fix ("" when the failure isn't recognized)
+}
+
+func (e *StowRunError) Error() string {
+	msg := fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+	if e.Output != "" {
+		msg += fmt.Sprintf("\nOutput: %s", e.Output)
+	}
+	return msg
+}
+
+func (e *StowRunError) Unwrap() error { return e.Err }
+
+// newStowRunError classifies the raw stow output so permission and
+// immutable-file problems carry a targeted fix instead of just the
+// generic failure text
+func newStowRunError(op string, err error, output string) *StowRunError {
+	return &StowRunError{Op: op, Err: err, Output: output, Hint: stowHint(output)}
+}
+
+// stowHint maps known patterns in stow's output to a suggested fix
+func stowHint(output string) string {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "operation not permitted"):
+		return "A target file may be immutable (chattr +i); check with 'lsattr' and clear it with 'chattr -i <file>'"
+	case strings.Contains(lower, "read-only file system"):
+		return "The stow target is on a read-only filesystem"
+	case strings.Contains(lower, "permission denied"):
+		return "The stow target is not writable; fix permissions on the target directory"
+	}
+	return ""
+}
+
+// CheckDotfilesSupport reports whether the installed stow is new enough
+// for --dotfiles, returning a descriptive error when it isn't (or when
+// stow itself is broken)
+func CheckDotfilesSupport() error {
+	version, err := ValidateStow()
+	if err != nil {
+		return err
+	}
+	if version != "" && !StowVersionAtLeast(version, MinStowVersion) {
+		return fmt.Errorf("stow %s does not support --dotfiles (requires %s or newer)", version, MinStowVersion)
+	}
+	return nil
+}
+
+// checkTargetWritable fails early with a targeted error when the stow
+// target can't be modified (read-only $HOME, locked-down environments),
+// before stow produces a confusing one
+func checkTargetWritable(op, home string) error {
+	if pathutil.IsWritable(home) {
+		return nil
+	}
+	return &StowRunError{
+		Op:   op,
+		Err:  fmt.Errorf("target %s is not writable", home),
+		Hint: "Fix permissions on the target directory (or check for a read-only filesystem)",
+	}
 }
 
 // StowOptions configures stow behavior
 type StowOptions struct {
 	DryRun       bool
-	Force        bool                                 // Overwrite conflicts
-	ProgressFunc func(current, total int, msg string) // Called for progress updates with item counts
+	Force        bool     // Overwrite conflicts
+	Exclude      []string // Per-invocation globs to skip, not persisted
+	FailFast     bool     // Stop at the first failure instead of continuing
+	DotfilesMode bool     // Pass --dotfiles so "dot-foo" in packages maps to ".foo" in the target
+	// TemplateValues are extra values (shared prompt values) added to the
+	// context when rendering .tmpl files in template-enabled configs
+	TemplateValues map[string]string
+	ProgressFunc   func(current, total int, msg string) // Called for progress updates with item counts
 }
 
-// Stow symlinks a config directory using GNU stow
-func Stow(dotfilesPath string, configName string, opts StowOptions) error {
-	return StowWithCount(dotfilesPath, configName, 1, 1, opts)
+// Stow symlinks a config directory using GNU stow, returning the
+// symlinks it created
+func Stow(ctx context.Context, dotfilesPath string, configName string, opts StowOptions) ([]string, error) {
+	return StowWithCount(ctx, dotfilesPath, configName, 1, 1, opts)
 }
 
-// StowWithCount symlinks a config directory using GNU stow with progress tracking
-func StowWithCount(dotfilesPath string, configName string, current, total int, opts StowOptions) error {
+// StowWithCount symlinks a config directory using GNU stow with progress
+// tracking, returning the symlinks it created
+func StowWithCount(ctx context.Context, dotfilesPath string, configName string, current, total int, opts StowOptions) ([]string, error) {
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(current, total, fmt.Sprintf("Stowing %s...", configName))
 	}
@@ -52,31 +149,75 @@ func StowWithCount(dotfilesPath string, configName string, current, total int, o
 		args = append(args, "--adopt") // Adopt existing files
 	}
 
-	args = append(args, "-t", os.Getenv("HOME")) // Target home directory
-	args = append(args, "-d", dotfilesPath)      // Directory containing packages
-	args = append(args, configName)              // Package to stow
+	if opts.DotfilesMode {
+		args = append(args, "--dotfiles")
+	}
+
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory for stow target: %w", err)
+	}
+	if !opts.DryRun {
+		if err := checkTargetWritable("stow", home); err != nil {
+			return nil, err
+		}
+	}
+	args = append(args, "-t", home)         // Target home directory
+	args = append(args, "-d", dotfilesPath) // Directory containing packages
+	args = append(args, configName)         // Package to stow
+
+	// Apply per-invocation excludes via a temporary ignore file
+	if len(opts.Exclude) > 0 {
+		cleanup, err := writeTemporaryIgnore(filepath.Join(dotfilesPath, configName), opts.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+	}
 
-	cmd := exec.Command("stow", args...)
+	cmd := exec.CommandContext(ctx, "stow", args...)
+	verbose.Command(cmd.Args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
-		return fmt.Errorf("stow failed: %w\nOutput: %s", err, string(output))
+		return nil, newStowRunError("stow", err, string(output))
 	}
 
+	links := parseCreatedLinks(string(output))
+
 	if opts.ProgressFunc != nil {
-		opts.ProgressFunc(current, total, fmt.Sprintf("✓ Stowed %s", configName))
+		opts.ProgressFunc(current, total, fmt.Sprintf("✓ Stowed %s (%d symlinks)", configName, len(links)))
 	}
 
-	return nil
+	return links, nil
+}
+
+// parseCreatedLinks extracts the link paths from stow's verbose
+// "LINK: target => source" output lines. Stow prints these to stderr,
+// one per symlink it creates (or would create, in dry-run mode).
+func parseCreatedLinks(output string) []string {
+	var links []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "LINK: ") {
+			continue
+		}
+		link := strings.TrimPrefix(line, "LINK: ")
+		if i := strings.Index(link, " => "); i >= 0 {
+			link = link[:i]
+		}
+		links = append(links, link)
+	}
+	return links
 }
 
 // Unstow removes symlinks for a config
-func Unstow(dotfilesPath string, configName string, opts StowOptions) error {
-	return UnstowWithCount(dotfilesPath, configName, 1, 1, opts)
+func Unstow(ctx context.Context, dotfilesPath string, configName string, opts StowOptions) error {
+	return UnstowWithCount(ctx, dotfilesPath, configName, 1, 1, opts)
 }
 
 // UnstowWithCount removes symlinks for a config with progress tracking
-func UnstowWithCount(dotfilesPath string, configName string, current, total int, opts StowOptions) error {
+func UnstowWithCount(ctx context.Context, dotfilesPath string, configName string, current, total int, opts StowOptions) error {
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(current, total, fmt.Sprintf("Unstowing %s...", configName))
 	}
@@ -87,15 +228,24 @@ func UnstowWithCount(dotfilesPath string, configName string, current, total int,
 		args = append(args, "-n")
 	}
 
-	args = append(args, "-t", os.Getenv("HOME"))
+	if opts.DotfilesMode {
+		args = append(args, "--dotfiles")
+	}
+
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return fmt.Errorf("cannot determine home directory for stow target: %w", err)
+	}
+	args = append(args, "-t", home)
 	args = append(args, "-d", dotfilesPath)
 	args = append(args, configName)
 
-	cmd := exec.Command("stow", args...)
+	cmd := exec.CommandContext(ctx, "stow", args...)
+	verbose.Command(cmd.Args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
-		return fmt.Errorf("unstow failed: %w\nOutput: %s", err, string(output))
+		return newStowRunError("unstow", err, string(output))
 	}
 
 	if opts.ProgressFunc != nil {
@@ -105,13 +255,15 @@ func UnstowWithCount(dotfilesPath string, configName string, current, total int,
 	return nil
 }
 
-// Restow refreshes symlinks for a config (unstow + stow)
-func Restow(dotfilesPath string, configName string, opts StowOptions) error {
-	return RestowWithCount(dotfilesPath, configName, 1, 1, opts)
+// Restow refreshes symlinks for a config (unstow + stow), returning the
+// symlinks it created
+func Restow(ctx context.Context, dotfilesPath string, configName string, opts StowOptions) ([]string, error) {
+	return RestowWithCount(ctx, dotfilesPath, configName, 1, 1, opts)
 }
 
-// RestowWithCount refreshes symlinks for a config with progress tracking
-func RestowWithCount(dotfilesPath string, configName string, current, total int, opts StowOptions) error {
+// RestowWithCount refreshes symlinks for a config with progress tracking,
+// returning the symlinks it created
+func RestowWithCount(ctx context.Context, dotfilesPath string, configName string, current, total int, opts StowOptions) ([]string, error) {
 	if opts.ProgressFunc != nil {
 		opts.ProgressFunc(current, total, fmt.Sprintf("Restowing %s...", configName))
 	}
@@ -122,36 +274,136 @@ func RestowWithCount(dotfilesPath string, configName string, current, total int,
 		args = append(args, "-n")
 	}
 
+	if opts.DotfilesMode {
+		args = append(args, "--dotfiles")
+	}
+
 	if opts.Force {
 		args = append(args, "--adopt")
 	}
 
-	args = append(args, "-t", os.Getenv("HOME"))
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory for stow target: %w", err)
+	}
+	args = append(args, "-t", home)
 	args = append(args, "-d", dotfilesPath)
 	args = append(args, configName)
 
-	cmd := exec.Command("stow", args...)
+	cmd := exec.CommandContext(ctx, "stow", args...)
+	verbose.Command(cmd.Args...)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
-		return fmt.Errorf("restow failed: %w\nOutput: %s", err, string(output))
+		return nil, newStowRunError("restow", err, string(output))
 	}
 
+	links := parseCreatedLinks(string(output))
+
 	if opts.ProgressFunc != nil {
-		opts.ProgressFunc(current, total, fmt.Sprintf("✓ Restowed %s", configName))
+		opts.ProgressFunc(current, total, fmt.Sprintf("✓ Restowed %s (%d symlinks)", configName, len(links)))
 	}
 
-	return nil
+	return links, nil
+}
+
+// writeTemporaryIgnore writes a temporary .stow-local-ignore in the package
+// directory so GNU stow skips the excluded globs. It returns a cleanup
+// function that restores any pre-existing ignore file (or removes the
+// temporary one). Note that an ignore file replaces stow's built-in ignore
+// list, so existing entries are preserved when present.
+func writeTemporaryIgnore(packageDir string, excludes []string) (func(), error) {
+	ignorePath := filepath.Join(packageDir, ".stow-local-ignore")
+
+	var original []byte
+	hadOriginal := false
+	if data, err := os.ReadFile(ignorePath); err == nil {
+		original = data
+		hadOriginal = true
+	}
+
+	var sb strings.Builder
+	if hadOriginal {
+		sb.Write(original)
+		if len(original) > 0 && original[len(original)-1] != '\n' {
+			sb.WriteByte('\n')
+		}
+	}
+	for _, glob := range excludes {
+		sb.WriteString(globToIgnoreRegex(glob))
+		sb.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(ignorePath, []byte(sb.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write temporary ignore file: %w", err)
+	}
+
+	return func() {
+		if hadOriginal {
+			os.WriteFile(ignorePath, original, 0644)
+		} else {
+			os.Remove(ignorePath)
+		}
+	}, nil
+}
+
+// globToIgnoreRegex converts a shell-style glob into the Perl regex format
+// GNU stow expects in ignore files.
+func globToIgnoreRegex(glob string) string {
+	var sb strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(`[^/]*`)
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '.', '+', '(', ')', '[', ']', '{', '}', '^', '$', '|', '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// stowRunOptions adapts the batch options for one config: template-enabled
+// configs get their .tmpl files excluded from stow so they are rendered
+// instead of symlinked
+func stowRunOptions(cfg config.ConfigItem, opts StowOptions) StowOptions {
+	if !cfg.Template {
+		return opts
+	}
+	runOpts := opts
+	runOpts.Exclude = append(append([]string{}, opts.Exclude...), "*"+TemplateSuffix)
+	return runOpts
+}
+
+// stowErrorFor builds a StowError, surfacing the fix suggestion when
+// the underlying failure carries one
+func stowErrorFor(name string, err error) StowError {
+	se := StowError{ConfigName: name, Error: err}
+	var runErr *StowRunError
+	if errors.As(err, &runErr) {
+		se.Hint = runErr.Hint
+	}
+	return se
 }
 
 // StowConfigs stows multiple configs
-func StowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
-	result := &StowResult{}
+func StowConfigs(ctx context.Context, dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
+	result := &StowResult{Links: make(map[string][]string)}
 	total := len(configs)
 
 	for i, cfg := range configs {
 		current := i + 1
 
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, StowError{ConfigName: cfg.Name, Error: err})
+			break
+		}
+
 		// Check if config directory exists
 		configPath := filepath.Join(dotfilesPath, cfg.Path)
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -162,15 +414,36 @@ func StowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOpti
 			continue
 		}
 
-		// Stow it
-		err := StowWithCount(dotfilesPath, cfg.Path, current, total, opts)
+		// Skip configs whose links are all already correct so repeated
+		// installs stay fast and quiet
+		if !opts.Force && IsFullyStowed(dotfilesPath, cfg, opts.DotfilesMode) {
+			result.AlreadyStowed = append(result.AlreadyStowed, cfg.Name)
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("✓ %s already stowed", cfg.Name))
+			}
+			continue
+		}
+
+		// Stow it, rendering any .tmpl files for template-enabled configs
+		links, err := StowWithCount(ctx, dotfilesPath, cfg.Path, current, total, stowRunOptions(cfg, opts))
+		if err == nil && cfg.Template {
+			var rendered []string
+			rendered, err = RenderTemplateFiles(dotfilesPath, cfg.Path, opts)
+			links = append(links, rendered...)
+			if err == nil && len(rendered) > 0 && opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("Rendered %d template files for %s", len(rendered), cfg.Name))
+			}
+		}
 		if err != nil {
-			result.Failed = append(result.Failed, StowError{
-				ConfigName: cfg.Name,
-				Error:      err,
-			})
+			result.Failed = append(result.Failed, stowErrorFor(cfg.Name, err))
+			if opts.FailFast {
+				break
+			}
 		} else {
 			result.Success = append(result.Success, cfg.Name)
+			if len(links) > 0 {
+				result.Links[cfg.Name] = links
+			}
 		}
 	}
 
@@ -178,18 +451,24 @@ func StowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOpti
 }
 
 // UnstowConfigs unstows multiple configs
-func UnstowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
+func UnstowConfigs(ctx context.Context, dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
 	result := &StowResult{}
 	total := len(configs)
 
 	for i, cfg := range configs {
 		current := i + 1
-		err := UnstowWithCount(dotfilesPath, cfg.Path, current, total, opts)
+
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, StowError{ConfigName: cfg.Name, Error: err})
+			break
+		}
+		err := UnstowWithCount(ctx, dotfilesPath, cfg.Path, current, total, stowRunOptions(cfg, opts))
 		if err != nil {
-			result.Failed = append(result.Failed, StowError{
-				ConfigName: cfg.Name,
-				Error:      err,
-			})
+			result.Failed = append(result.Failed, stowErrorFor(cfg.Name, err))
+			if opts.FailFast {
+				break
+			}
 		} else {
 			result.Success = append(result.Success, cfg.Name)
 		}
@@ -199,12 +478,18 @@ func UnstowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOp
 }
 
 // RestowConfigs restows multiple configs
-func RestowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
-	result := &StowResult{}
+func RestowConfigs(ctx context.Context, dotfilesPath string, configs []config.ConfigItem, opts StowOptions) *StowResult {
+	result := &StowResult{Links: make(map[string][]string)}
 	total := len(configs)
 
 	for i, cfg := range configs {
 		current := i + 1
+
+		// Stop cleanly if the operation was cancelled
+		if err := ctx.Err(); err != nil {
+			result.Failed = append(result.Failed, StowError{ConfigName: cfg.Name, Error: err})
+			break
+		}
 		configPath := filepath.Join(dotfilesPath, cfg.Path)
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
 			result.Skipped = append(result.Skipped, cfg.Name)
@@ -214,14 +499,33 @@ func RestowConfigs(dotfilesPath string, configs []config.ConfigItem, opts StowOp
 			continue
 		}
 
-		err := RestowWithCount(dotfilesPath, cfg.Path, current, total, opts)
+		if !opts.Force && IsFullyStowed(dotfilesPath, cfg, opts.DotfilesMode) {
+			result.AlreadyStowed = append(result.AlreadyStowed, cfg.Name)
+			if opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("✓ %s already stowed", cfg.Name))
+			}
+			continue
+		}
+
+		links, err := RestowWithCount(ctx, dotfilesPath, cfg.Path, current, total, stowRunOptions(cfg, opts))
+		if err == nil && cfg.Template {
+			var rendered []string
+			rendered, err = RenderTemplateFiles(dotfilesPath, cfg.Path, opts)
+			links = append(links, rendered...)
+			if err == nil && len(rendered) > 0 && opts.ProgressFunc != nil {
+				opts.ProgressFunc(current, total, fmt.Sprintf("Rendered %d template files for %s", len(rendered), cfg.Name))
+			}
+		}
 		if err != nil {
-			result.Failed = append(result.Failed, StowError{
-				ConfigName: cfg.Name,
-				Error:      err,
-			})
+			result.Failed = append(result.Failed, stowErrorFor(cfg.Name, err))
+			if opts.FailFast {
+				break
+			}
 		} else {
 			result.Success = append(result.Success, cfg.Name)
+			if len(links) > 0 {
+				result.Links[cfg.Name] = links
+			}
 		}
 	}
 
@@ -234,23 +538,66 @@ func IsStowInstalled() bool {
 	return err == nil
 }
 
-// ValidateStow checks if stow is installed and working
-func ValidateStow() error {
+// MinStowVersion is the oldest stow release supporting the features
+// GopherDot relies on (--adopt and --dotfiles, both added in 2.3.0)
+const MinStowVersion = "2.3.0"
+
+// ValidateStow checks if stow is installed and working, returning the
+// detected version (empty when the output can't be parsed)
+func ValidateStow() (string, error) {
 	if !IsStowInstalled() {
-		return fmt.Errorf("GNU stow is not installed")
+		return "", fmt.Errorf("GNU stow is not installed")
 	}
 
 	// Try to get stow version
 	cmd := exec.Command("stow", "--version")
+	verbose.Command(cmd.Args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("stow command failed: %w", err)
+		return "", fmt.Errorf("stow command failed: %w", err)
 	}
 
 	// Check if it's actually GNU stow
 	if !strings.Contains(string(output), "GNU Stow") && !strings.Contains(string(output), "stow") {
-		return fmt.Errorf("unexpected stow version output: %s", string(output))
+		return "", fmt.Errorf("unexpected stow version output: %s", string(output))
 	}
 
-	return nil
+	return parseStowVersion(string(output)), nil
+}
+
+// parseStowVersion pulls the version number out of `stow --version`
+// output, which varies between builds ("stow (GNU Stow) version 2.3.1",
+// "GNU Stow version 2.4.0"). Returns "" when no version is found.
+func parseStowVersion(output string) string {
+	return regexp.MustCompile(`\d+\.\d+(?:\.\d+)?`).FindString(output)
+}
+
+// StowVersionAtLeast reports whether version is at least min, comparing
+// dotted numeric components. Unparseable versions compare as too old.
+func StowVersionAtLeast(version, min string) bool {
+	v := versionComponents(version)
+	m := versionComponents(min)
+	if len(v) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(v) && i < len(m); i++ {
+		if v[i] != m[i] {
+			return v[i] > m[i]
+		}
+	}
+	return len(v) >= len(m)
+}
+
+// versionComponents splits a dotted version into its numeric parts
+func versionComponents(v string) []int {
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
 }
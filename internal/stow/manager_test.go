@@ -1,8 +1,11 @@
 package stow
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/nvandessel/go4dot/internal/config"
@@ -20,10 +23,32 @@ func TestValidateStow(t *testing.T) {
 		t.Skip("Stow is not installed, skipping validation test")
 	}
 
-	err := ValidateStow()
+	version, err := ValidateStow()
 	if err != nil {
 		t.Errorf("ValidateStow() failed: %v", err)
 	}
+	t.Logf("Stow version: %q", version)
+}
+
+func TestStowNoHome(t *testing.T) {
+	// Simulate a cron/systemd context where $HOME is not set; stow must
+	// not run with an empty -t target
+	t.Setenv("HOME", "")
+
+	_, err := Stow(context.Background(), t.TempDir(), "testconfig", StowOptions{})
+	if err == nil {
+		t.Fatal("Stow() should fail when the home directory cannot be determined")
+	}
+	if !strings.Contains(err.Error(), "home directory") {
+		t.Errorf("error should mention the home directory, got: %v", err)
+	}
+
+	if err := Unstow(context.Background(), t.TempDir(), "testconfig", StowOptions{}); err == nil {
+		t.Error("Unstow() should fail when the home directory cannot be determined")
+	}
+	if _, err := Restow(context.Background(), t.TempDir(), "testconfig", StowOptions{}); err == nil {
+		t.Error("Restow() should fail when the home directory cannot be determined")
+	}
 }
 
 func TestStowConfigs(t *testing.T) {
@@ -66,7 +91,7 @@ func TestStowConfigs(t *testing.T) {
 	}
 
 	// Test stowing
-	result := StowConfigs(tmpDir, configs, opts)
+	result := StowConfigs(context.Background(), tmpDir, configs, opts)
 
 	// Should have one skipped (nonexistent) and one attempt
 	if len(result.Skipped) != 1 {
@@ -97,7 +122,7 @@ func TestUnstowConfigs(t *testing.T) {
 		DryRun: true,
 	}
 
-	result := UnstowConfigs(tmpDir, configs, opts)
+	result := UnstowConfigs(context.Background(), tmpDir, configs, opts)
 
 	// Unstowing non-existent config shouldn't cause test failure
 	// It should either succeed or fail gracefully
@@ -134,7 +159,7 @@ func TestRestowConfigs(t *testing.T) {
 		DryRun: true,
 	}
 
-	result := RestowConfigs(tmpDir, configs, opts)
+	result := RestowConfigs(context.Background(), tmpDir, configs, opts)
 
 	// Should have one skipped (missing)
 	if len(result.Skipped) != 1 {
@@ -142,6 +167,114 @@ func TestRestowConfigs(t *testing.T) {
 	}
 }
 
+func TestGlobToIgnoreRegex(t *testing.T) {
+	tests := []struct {
+		name string
+		glob string
+		want string
+	}{
+		{"Plain name", "config", "config"},
+		{"Dot escaped", ".ssh", `\.ssh`},
+		{"Star wildcard", "*.work", `[^/]*\.work`},
+		{"Question mark", "file?.txt", `file[^/]\.txt`},
+		{"Regex chars escaped", "a+b(c)", `a\+b\(c\)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := globToIgnoreRegex(tt.glob); got != tt.want {
+				t.Errorf("globToIgnoreRegex(%q) = %q, want %q", tt.glob, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTemporaryIgnore(t *testing.T) {
+	t.Run("No existing ignore file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		ignorePath := filepath.Join(tmpDir, ".stow-local-ignore")
+
+		cleanup, err := writeTemporaryIgnore(tmpDir, []string{"*.work"})
+		if err != nil {
+			t.Fatalf("writeTemporaryIgnore() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(ignorePath)
+		if err != nil {
+			t.Fatalf("Ignore file should exist: %v", err)
+		}
+		if string(data) != "[^/]*\\.work\n" {
+			t.Errorf("Ignore file content = %q", string(data))
+		}
+
+		cleanup()
+		if _, err := os.Stat(ignorePath); !os.IsNotExist(err) {
+			t.Error("Ignore file should be removed by cleanup")
+		}
+	})
+
+	t.Run("Existing ignore file is preserved and restored", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		ignorePath := filepath.Join(tmpDir, ".stow-local-ignore")
+		original := "^/README\n"
+		if err := os.WriteFile(ignorePath, []byte(original), 0644); err != nil {
+			t.Fatalf("Failed to write ignore file: %v", err)
+		}
+
+		cleanup, err := writeTemporaryIgnore(tmpDir, []string{"secret"})
+		if err != nil {
+			t.Fatalf("writeTemporaryIgnore() failed: %v", err)
+		}
+
+		data, _ := os.ReadFile(ignorePath)
+		if string(data) != original+"secret\n" {
+			t.Errorf("Ignore file content = %q", string(data))
+		}
+
+		cleanup()
+		data, _ = os.ReadFile(ignorePath)
+		if string(data) != original {
+			t.Errorf("Ignore file should be restored, got %q", string(data))
+		}
+	})
+}
+
+func TestOwnedSymlinks(t *testing.T) {
+	dotfiles := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Package with one file that is symlinked into home and one that isn't
+	pkgDir := filepath.Join(dotfiles, "git")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatalf("Failed to create package dir: %v", err)
+	}
+	for _, name := range []string{".gitconfig", ".gitignore"} {
+		if err := os.WriteFile(filepath.Join(pkgDir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(pkgDir, ".gitconfig"), filepath.Join(home, ".gitconfig")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	// A regular file in home should not be reported
+	if err := os.WriteFile(filepath.Join(home, ".gitignore"), []byte("y"), 0644); err != nil {
+		t.Fatalf("Failed to write home file: %v", err)
+	}
+
+	links, err := OwnedSymlinks(dotfiles, "git")
+	if err != nil {
+		t.Fatalf("OwnedSymlinks() failed: %v", err)
+	}
+
+	if len(links) != 1 {
+		t.Fatalf("len(links) = %d, want 1: %v", len(links), links)
+	}
+	if links[0] != filepath.Join(home, ".gitconfig") {
+		t.Errorf("links[0] = %s, want %s", links[0], filepath.Join(home, ".gitconfig"))
+	}
+}
+
 func TestStowResult(t *testing.T) {
 	result := &StowResult{
 		Success: []string{"config1", "config2"},
@@ -185,7 +318,7 @@ func TestStowOptionsProgressCallback(t *testing.T) {
 		},
 	}
 
-	err = Stow(tmpDir, "testconfig", opts)
+	_, err = Stow(context.Background(), tmpDir, "testconfig", opts)
 	// Don't fail on error since we're in dry-run mode with a test directory
 
 	// Should have received at least one progress message
@@ -195,3 +328,147 @@ func TestStowOptionsProgressCallback(t *testing.T) {
 
 	t.Logf("Received %d progress messages", len(progressMessages))
 }
+
+func TestParseCreatedLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "Single link",
+			output: "LINK: .zshrc => ../dotfiles/zsh/.zshrc\n",
+			want:   []string{".zshrc"},
+		},
+		{
+			name: "Multiple links with noise",
+			output: "stowing zsh\n" +
+				"LINK: .zshrc => ../dotfiles/zsh/.zshrc\n" +
+				"LINK: .config/zsh/aliases => ../../dotfiles/zsh/.config/zsh/aliases\n" +
+				"WARNING: in simulation mode so not modifying filesystem.\n",
+			want: []string{".zshrc", ".config/zsh/aliases"},
+		},
+		{
+			name:   "No links",
+			output: "stowing zsh\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCreatedLinks(tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseCreatedLinks() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseCreatedLinks()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseStowVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"standard format", "stow (GNU Stow) version 2.3.1\n", "2.3.1"},
+		{"short banner", "GNU Stow version 2.4.0\n", "2.4.0"},
+		{"two components", "stow 2.3\n", "2.3"},
+		{"no version", "not stow at all\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseStowVersion(tt.output); got != tt.expected {
+				t.Errorf("parseStowVersion(%q) = %q, want %q", tt.output, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStowVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		min      string
+		expected bool
+	}{
+		{"2.3.1", "2.3.0", true},
+		{"2.3.0", "2.3.0", true},
+		{"2.4.0", "2.3.0", true},
+		{"2.2.2", "2.3.0", false},
+		{"1.9.9", "2.3.0", false},
+		{"2.3", "2.3.0", false},
+		{"3.0", "2.3.0", true},
+		{"", "2.3.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+">="+tt.min, func(t *testing.T) {
+			if got := StowVersionAtLeast(tt.version, tt.min); got != tt.expected {
+				t.Errorf("StowVersionAtLeast(%q, %q) = %v, want %v", tt.version, tt.min, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStowHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "immutable file",
+			output: "stow: ERROR: unlink: Operation not permitted\n",
+			want:   "chattr",
+		},
+		{
+			name:   "permission denied",
+			output: "stow: ERROR: mkdir /home/user/.config: Permission denied\n",
+			want:   "not writable",
+		},
+		{
+			name:   "read-only filesystem",
+			output: "stow: ERROR: symlink: Read-only file system\n",
+			want:   "read-only",
+		},
+		{
+			name:   "unrecognized failure",
+			output: "stow: ERROR: something else went wrong\n",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stowHint(tt.output)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("stowHint() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("stowHint() = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStowErrorForHint(t *testing.T) {
+	runErr := newStowRunError("stow", fmt.Errorf("exit status 2"), "stow: ERROR: mkdir: Permission denied")
+	se := stowErrorFor("nvim", fmt.Errorf("wrapped: %w", runErr))
+	if se.Hint == "" {
+		t.Error("expected hint to be carried through wrapping")
+	}
+
+	plain := stowErrorFor("nvim", fmt.Errorf("some other error"))
+	if plain.Hint != "" {
+		t.Errorf("unexpected hint %q for plain error", plain.Hint)
+	}
+}
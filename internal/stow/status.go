@@ -1,17 +1,21 @@
 package stow
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/nvandessel/go4dot/internal/config"
+	"github.com/nvandessel/go4dot/internal/pathutil"
 )
 
 // FileStatus represents the link status of a single file
 type FileStatus struct {
-	RelPath  string // Relative path from config directory
-	IsLinked bool   // True if correctly symlinked
-	Issue    string // Description of issue if not linked
+	RelPath   string // Relative path from config directory
+	TargetRel string // Expected target relative to $HOME (after dot-/.tmpl translation)
+	IsLinked  bool   // True if correctly symlinked (or rendered, for templates)
+	Issue     string // Description of issue if not linked
 }
 
 // ConfigLinkStatus represents the overall link status for a config
@@ -63,11 +67,14 @@ func (s *ConfigLinkStatus) GetMissingFiles() []FileStatus {
 // GetAllConfigLinkStatus returns link status for all configs
 func GetAllConfigLinkStatus(cfg *config.Config, dotfilesPath string) (map[string]*ConfigLinkStatus, error) {
 	result := make(map[string]*ConfigLinkStatus)
-	home := os.Getenv("HOME")
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
 
 	allConfigs := cfg.GetAllConfigs()
 	for _, configItem := range allConfigs {
-		status, err := getConfigLinkStatusInternal(configItem, dotfilesPath, home)
+		status, err := getConfigLinkStatusInternal(configItem, dotfilesPath, home, cfg.Stow.Dotfiles)
 		if err != nil {
 			continue
 		}
@@ -77,8 +84,24 @@ func GetAllConfigLinkStatus(cfg *config.Config, dotfilesPath string) (map[string
 	return result, nil
 }
 
+// IsFullyStowed reports whether every file in a config package is
+// already correctly symlinked (or, for template files, rendered) into
+// the target, so callers can skip a redundant stow run. Any error
+// checking is treated as "not stowed".
+func IsFullyStowed(dotfilesPath string, configItem config.ConfigItem, dotfilesMode bool) bool {
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return false
+	}
+	status, err := getConfigLinkStatusInternal(configItem, dotfilesPath, home, dotfilesMode)
+	if err != nil {
+		return false
+	}
+	return status.IsFullyLinked()
+}
+
 // getConfigLinkStatusInternal checks the link status of a single config
-func getConfigLinkStatusInternal(configItem config.ConfigItem, dotfilesPath, home string) (*ConfigLinkStatus, error) {
+func getConfigLinkStatusInternal(configItem config.ConfigItem, dotfilesPath, home string, dotfilesMode bool) (*ConfigLinkStatus, error) {
 	configPath := filepath.Join(dotfilesPath, configItem.Path)
 
 	status := &ConfigLinkStatus{
@@ -102,17 +125,37 @@ func getConfigLinkStatusInternal(configItem config.ConfigItem, dotfilesPath, hom
 
 		status.TotalCount++
 
-		// Calculate expected target path in home
+		// Calculate the expected target path in home, applying the same
+		// translations stow and the template renderer do: dot- components
+		// in dotfiles mode, and the stripped .tmpl suffix for templates
 		relPath, _ := filepath.Rel(configPath, path)
-		targetPath := filepath.Join(home, relPath)
+		isTemplate := configItem.Template && strings.HasSuffix(relPath, TemplateSuffix)
+
+		targetRel := relPath
+		if isTemplate {
+			targetRel = templateDestination(relPath, dotfilesMode)
+		} else if dotfilesMode {
+			targetRel = dotfilesTargetRel(relPath)
+		}
+		targetPath := filepath.Join(home, targetRel)
 
 		fileStatus := FileStatus{
-			RelPath: relPath,
+			RelPath:   relPath,
+			TargetRel: targetRel,
 		}
 
-		// Check if the symlink exists and is correct
-		if checkLinkStatus(path, targetPath, &fileStatus) {
+		// Template files are rendered rather than linked, so any file at
+		// the destination counts; everything else must be a correct symlink
+		if isTemplate {
+			if _, err := os.Stat(targetPath); err == nil {
+				fileStatus.IsLinked = true
+			} else {
+				fileStatus.Issue = "not rendered"
+			}
+		} else if checkLinkStatus(path, targetPath, &fileStatus) {
 			fileStatus.IsLinked = true
+		}
+		if fileStatus.IsLinked {
 			status.LinkedCount++
 		}
 
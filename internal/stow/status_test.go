@@ -0,0 +1,159 @@
+package stow
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nvandessel/go4dot/internal/config"
+)
+
+func TestIsFullyStowed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and HOME override not reliable on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "zsh")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	source := filepath.Join(packageDir, ".zshrc")
+	if err := os.WriteFile(source, []byte("export EDITOR=nvim\n"), 0644); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+
+	item := config.ConfigItem{Name: "zsh", Path: "zsh"}
+
+	if IsFullyStowed(dotfiles, item, false) {
+		t.Error("expected config with no links to not be fully stowed")
+	}
+
+	if err := os.Symlink(source, filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if !IsFullyStowed(dotfiles, item, false) {
+		t.Error("expected config with all links correct to be fully stowed")
+	}
+
+	if IsFullyStowed(dotfiles, config.ConfigItem{Name: "missing", Path: "missing"}, false) {
+		t.Error("expected missing package directory to not be fully stowed")
+	}
+}
+
+func TestIsFullyStowedDotfilesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and HOME override not reliable on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "zsh")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	source := filepath.Join(packageDir, "dot-zshrc")
+	if err := os.WriteFile(source, []byte("export EDITOR=nvim\n"), 0644); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+
+	// Stow --dotfiles links dot-zshrc to ~/.zshrc
+	if err := os.Symlink(source, filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	item := config.ConfigItem{Name: "zsh", Path: "zsh"}
+
+	if !IsFullyStowed(dotfiles, item, true) {
+		t.Error("expected dot- translated links to count as fully stowed in dotfiles mode")
+	}
+	if IsFullyStowed(dotfiles, item, false) {
+		t.Error("expected dot- package to not be fully stowed without dotfiles mode")
+	}
+}
+
+func TestIsFullyStowedTemplate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME override not reliable on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "git")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	source := filepath.Join(packageDir, ".gitconfig.tmpl")
+	if err := os.WriteFile(source, []byte("[user]\n  name = {{ .platform_os }}\n"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	item := config.ConfigItem{Name: "git", Path: "git", Template: true}
+
+	if IsFullyStowed(dotfiles, item, false) {
+		t.Error("expected unrendered template to not be fully stowed")
+	}
+
+	// A rendered destination (suffix stripped) satisfies the template file
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[user]\n  name = linux\n"), 0644); err != nil {
+		t.Fatalf("failed to write rendered file: %v", err)
+	}
+
+	if !IsFullyStowed(dotfiles, item, false) {
+		t.Error("expected rendered template to count as fully stowed")
+	}
+}
+
+func TestGetAllConfigLinkStatusDotfilesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and HOME override not reliable on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "zsh")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	source := filepath.Join(packageDir, "dot-zshrc")
+	if err := os.WriteFile(source, []byte("export EDITOR=nvim\n"), 0644); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+	if err := os.Symlink(source, filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	cfg := &config.Config{
+		Configs: config.ConfigGroups{
+			Core: []config.ConfigItem{{Name: "zsh", Path: "zsh"}},
+		},
+		Stow: config.StowSettings{Dotfiles: true},
+	}
+
+	statuses, err := GetAllConfigLinkStatus(cfg, dotfiles)
+	if err != nil {
+		t.Fatalf("GetAllConfigLinkStatus() failed: %v", err)
+	}
+
+	status, ok := statuses["zsh"]
+	if !ok {
+		t.Fatal("no status returned for zsh")
+	}
+	if !status.IsFullyLinked() {
+		t.Errorf("status = %d/%d linked, want fully linked in dotfiles mode", status.LinkedCount, status.TotalCount)
+	}
+	if len(status.Files) != 1 || status.Files[0].TargetRel != ".zshrc" {
+		t.Errorf("Files = %+v, want one entry targeting .zshrc", status.Files)
+	}
+}
@@ -1,6 +1,7 @@
 package stow
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/nvandessel/go4dot/internal/config"
@@ -9,7 +10,7 @@ import (
 
 // SyncAll restows all configs and updates state.
 // It handles conflict detection and resolution if interactive.
-func SyncAll(dotfilesPath string, cfg *config.Config, st *state.State, interactive bool, opts StowOptions) (*StowResult, error) {
+func SyncAll(ctx context.Context, dotfilesPath string, cfg *config.Config, st *state.State, interactive bool, opts StowOptions) (*StowResult, error) {
 	if interactive {
 		if opts.ProgressFunc != nil {
 			opts.ProgressFunc(0, 0, "Checking for conflicts...")
@@ -21,9 +22,26 @@ func SyncAll(dotfilesPath string, cfg *config.Config, st *state.State, interacti
 		}
 
 		if len(conflicts) > 0 {
-			if !ResolveConflicts(conflicts) {
+			policy, err := ResolveConflictsTUI(conflicts)
+			if err != nil {
+				return nil, err
+			}
+			if policy == nil {
 				return nil, fmt.Errorf("sync cancelled due to unresolved conflicts")
 			}
+
+			skipped, err := ApplyConflictPolicy(conflicts, policy)
+			if err != nil {
+				return nil, err
+			}
+
+			// Skipped files stay in place; exclude them so stow
+			// doesn't trip over them
+			for _, c := range skipped {
+				if rel := conflictRelPath(cfg, dotfilesPath, c); rel != "" {
+					opts.Exclude = append(opts.Exclude, rel)
+				}
+			}
 		}
 	}
 
@@ -32,7 +50,7 @@ func SyncAll(dotfilesPath string, cfg *config.Config, st *state.State, interacti
 	}
 
 	allConfigs := cfg.GetAllConfigs()
-	result := RestowConfigs(dotfilesPath, allConfigs, opts)
+	result := RestowConfigs(ctx, dotfilesPath, allConfigs, opts)
 
 	// Update symlink counts in state
 	if st != nil {
@@ -43,7 +61,7 @@ func SyncAll(dotfilesPath string, cfg *config.Config, st *state.State, interacti
 }
 
 // SyncSingle restows a single config and updates state.
-func SyncSingle(dotfilesPath string, configName string, cfg *config.Config, st *state.State, opts StowOptions) error {
+func SyncSingle(ctx context.Context, dotfilesPath string, configName string, cfg *config.Config, st *state.State, opts StowOptions) error {
 	// Find the config item
 	var configItem *config.ConfigItem
 	for _, c := range cfg.GetAllConfigs() {
@@ -61,7 +79,7 @@ func SyncSingle(dotfilesPath string, configName string, cfg *config.Config, st *
 		opts.ProgressFunc(0, 0, fmt.Sprintf("Syncing %s...", configName))
 	}
 
-	err := Restow(dotfilesPath, configItem.Path, opts)
+	_, err := Restow(ctx, dotfilesPath, configItem.Path, opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,141 @@
+package stow
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nvandessel/go4dot/internal/pathutil"
+	"github.com/nvandessel/go4dot/internal/platform"
+)
+
+// TemplateSuffix marks files in a template-enabled config package that
+// are rendered into the target instead of symlinked
+const TemplateSuffix = ".tmpl"
+
+// RenderTemplateFiles walks a config package for files ending in .tmpl
+// and renders each one to the corresponding target path with the suffix
+// stripped, instead of letting stow link it. The template context is the
+// platform facts under platform_* keys plus opts.TemplateValues (shared
+// prompt values, when the caller has them); caller values win on
+// collision. Returns the rendered destination paths.
+func RenderTemplateFiles(dotfilesPath, configName string, opts StowOptions) ([]string, error) {
+	packageDir := filepath.Join(dotfilesPath, configName)
+
+	home, err := pathutil.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory for template target: %w", err)
+	}
+
+	values := templateContext(opts.TemplateValues)
+
+	var rendered []string
+	err = filepath.WalkDir(packageDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), TemplateSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(packageDir, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(home, templateDestination(rel, opts.DotfilesMode))
+
+		if !opts.DryRun {
+			if err := renderTemplateFile(path, dest, values); err != nil {
+				return fmt.Errorf("failed to render %s: %w", rel, err)
+			}
+		}
+		rendered = append(rendered, dest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rendered, nil
+}
+
+// templateDestination maps a package-relative template path to its
+// target-relative destination: the .tmpl suffix is stripped and, in
+// dotfiles mode, leading "dot-" components become "." like stow does
+func templateDestination(rel string, dotfilesMode bool) string {
+	rel = strings.TrimSuffix(rel, TemplateSuffix)
+	if !dotfilesMode {
+		return rel
+	}
+	return dotfilesTargetRel(rel)
+}
+
+// dotfilesTargetRel translates a package-relative path the way stow's
+// --dotfiles flag does: every path component starting with "dot-" maps
+// to a "." prefix in the target
+func dotfilesTargetRel(rel string) string {
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i, part := range parts {
+		if rest, ok := strings.CutPrefix(part, "dot-"); ok {
+			parts[i] = "." + rest
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// renderTemplateFile renders a single template file to dest, preserving
+// the source file's permissions (so executable templates stay executable)
+func renderTemplateFile(src, dest string, values map[string]string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(src)).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat template: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	if err := os.WriteFile(dest, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write rendered file: %w", err)
+	}
+
+	return nil
+}
+
+// templateContext merges platform facts under platform_* keys with the
+// caller-provided values, mirroring the machine config template context;
+// caller values win on collision
+func templateContext(extra map[string]string) map[string]string {
+	values := make(map[string]string, len(extra)+4)
+
+	if p, err := platform.Detect(); err == nil {
+		values["platform_os"] = p.OS
+		values["platform_distro"] = p.Distro
+		values["platform_arch"] = p.Architecture
+		values["platform_shell"] = p.Shell
+	}
+
+	for k, v := range extra {
+		values[k] = v
+	}
+
+	return values
+}
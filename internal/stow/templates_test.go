@@ -0,0 +1,126 @@
+package stow
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTemplateDestination(t *testing.T) {
+	tests := []struct {
+		name         string
+		rel          string
+		dotfilesMode bool
+		expected     string
+	}{
+		{
+			name:     "strips tmpl suffix",
+			rel:      "config/theme.conf.tmpl",
+			expected: "config/theme.conf",
+		},
+		{
+			name:     "dot prefix untouched without dotfiles mode",
+			rel:      "dot-zshrc.tmpl",
+			expected: "dot-zshrc",
+		},
+		{
+			name:         "dot prefix translated in dotfiles mode",
+			rel:          "dot-zshrc.tmpl",
+			dotfilesMode: true,
+			expected:     ".zshrc",
+		},
+		{
+			name:         "dot prefix translated per component",
+			rel:          filepath.Join("dot-config", "app", "dot-rc.tmpl"),
+			dotfilesMode: true,
+			expected:     filepath.Join(".config", "app", ".rc"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := templateDestination(tt.rel, tt.dotfilesMode)
+			if result != tt.expected {
+				t.Errorf("templateDestination(%q, %v) = %q, expected %q", tt.rel, tt.dotfilesMode, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME override not supported on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "nvim")
+	if err := os.MkdirAll(filepath.Join(packageDir, ".config", "nvim"), 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+
+	tmplPath := filepath.Join(packageDir, ".config", "nvim", "theme.lua.tmpl")
+	content := "os = {{ .platform_os }}\ntheme = {{ .theme }}\n"
+	if err := os.WriteFile(tmplPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	// A non-template file should be left alone
+	if err := os.WriteFile(filepath.Join(packageDir, ".config", "nvim", "init.lua"), []byte("-- init"), 0644); err != nil {
+		t.Fatalf("failed to write plain file: %v", err)
+	}
+
+	opts := StowOptions{TemplateValues: map[string]string{"theme": "gruvbox"}}
+	rendered, err := RenderTemplateFiles(dotfiles, "nvim", opts)
+	if err != nil {
+		t.Fatalf("RenderTemplateFiles failed: %v", err)
+	}
+
+	expected := filepath.Join(home, ".config", "nvim", "theme.lua")
+	if len(rendered) != 1 || rendered[0] != expected {
+		t.Fatalf("expected rendered = [%s], got %v", expected, rendered)
+	}
+
+	data, err := os.ReadFile(expected)
+	if err != nil {
+		t.Fatalf("failed to read rendered file: %v", err)
+	}
+	if !strings.Contains(string(data), "theme = gruvbox") {
+		t.Errorf("rendered content missing template value: %q", string(data))
+	}
+	if strings.Contains(string(data), "{{") {
+		t.Errorf("rendered content still contains template syntax: %q", string(data))
+	}
+}
+
+func TestRenderTemplateFilesDryRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME override not supported on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dotfiles := t.TempDir()
+	packageDir := filepath.Join(dotfiles, "git")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(packageDir, ".gitconfig.tmpl"), []byte("[user]\n"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	rendered, err := RenderTemplateFiles(dotfiles, "git", StowOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RenderTemplateFiles failed: %v", err)
+	}
+	if len(rendered) != 1 {
+		t.Fatalf("expected 1 rendered path, got %v", rendered)
+	}
+	if _, err := os.Stat(rendered[0]); !os.IsNotExist(err) {
+		t.Errorf("dry run should not write %s", rendered[0])
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 )
 
 // ProgressTracker tracks progress through a multi-step operation
@@ -337,6 +339,60 @@ func RunWithProgress(msg string, task func(updateChan chan<- progressUpdate) err
 	return nil
 }
 
+// ProgressFunc reports step progress. It mirrors the callback signature
+// used throughout setup, deps, and stow.
+type ProgressFunc func(current, total int, msg string)
+
+// plainProgress disables the animated progress bar (set via --no-color)
+var plainProgress bool
+
+// SetPlainProgress forces line output instead of the animated bar
+func SetPlainProgress(plain bool) {
+	plainProgress = plain
+}
+
+// ColorEnabled reports whether styled output should be used: stdout is
+// a terminal and color hasn't been disabled via --no-color or NO_COLOR.
+func ColorEnabled() bool {
+	if plainProgress || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// InteractiveProgress reports whether the animated progress bar can be
+// shown; it follows the same rules as styled output.
+func InteractiveProgress() bool {
+	return ColorEnabled()
+}
+
+// RunStepWithProgress runs a task whose progress is quantifiable
+// (current/total), rendering the animated progress bar when the terminal
+// supports it. On dumb terminals, under --no-color, or when piped, it
+// degrades to plain line output through the fallback callback.
+func RunStepWithProgress(msg string, fallback ProgressFunc, task func(report ProgressFunc) error) error {
+	if !InteractiveProgress() {
+		if fallback == nil {
+			fallback = func(current, total int, msg string) {}
+		}
+		return task(fallback)
+	}
+
+	return RunWithProgress(msg, func(updateChan chan<- progressUpdate) error {
+		return task(func(current, total int, msg string) {
+			var percent float64
+			if total > 0 {
+				percent = float64(current) / float64(total)
+			}
+			// Never block the worker on a slow render
+			select {
+			case updateChan <- progressUpdate{percent: percent, message: msg}:
+			default:
+			}
+		})
+	})
+}
+
 // FormatProgress formats a progress message with an item counter
 func FormatProgress(current, total int, msg string) string {
 	if total > 0 && current > 0 {
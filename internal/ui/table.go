@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Table accumulates rows and renders them as aligned columns with a row
+// style per entry. When color is disabled (--no-color, NO_COLOR, or a
+// non-TTY stdout) output degrades to plain tab-separated lines that are
+// easy to cut/awk.
+type Table struct {
+	headers []string
+	rows    [][]string
+	styles  []lipgloss.Style
+}
+
+// NewTable creates a table with the given column headers
+func NewTable(headers ...string) *Table {
+	return &Table{headers: headers}
+}
+
+// AddRow appends a row rendered with the given style when color is on
+func (t *Table) AddRow(style lipgloss.Style, cells ...string) {
+	t.rows = append(t.rows, cells)
+	t.styles = append(t.styles, style)
+}
+
+// Render returns the formatted table. Empty tables render as nothing.
+func (t *Table) Render() string {
+	if len(t.rows) == 0 {
+		return ""
+	}
+
+	if !ColorEnabled() {
+		var b strings.Builder
+		for _, row := range t.rows {
+			b.WriteString(strings.Join(row, "\t"))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+
+	// Column widths by display width, so unicode glyphs don't skew
+	// the alignment
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i < len(widths) && lipgloss.Width(cell) > widths[i] {
+				widths[i] = lipgloss.Width(cell)
+			}
+		}
+	}
+
+	var b strings.Builder
+	headerCells := make([]string, len(t.headers))
+	for i, h := range t.headers {
+		headerCells[i] = padCell(h, widths[i])
+	}
+	b.WriteString("  " + SubtleStyle.Render(strings.TrimRight(strings.Join(headerCells, "  "), " ")) + "\n")
+
+	for r, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			width := 0
+			if i < len(widths) {
+				width = widths[i]
+			}
+			cells[i] = padCell(cell, width)
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+		b.WriteString("  " + t.styles[r].Render(line) + "\n")
+	}
+
+	return b.String()
+}
+
+// Print renders the table to stdout
+func (t *Table) Print() {
+	fmt.Print(t.Render())
+}
+
+// padCell right-pads a cell to the given display width
+func padCell(s string, width int) string {
+	gap := width - lipgloss.Width(s)
+	if gap <= 0 {
+		return s
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+// StatusStyle maps a status word to the style used for its table row
+func StatusStyle(status string) lipgloss.Style {
+	switch status {
+	case "installed", "configured", "ok":
+		return SuccessStyle
+	case "missing", "failed", "error":
+		return ErrorStyle
+	case "version mismatch", "check failed", "conflict":
+		return WarningStyle
+	case "skipped", "unavailable", "archived":
+		return SubtleStyle
+	default:
+		return TextStyle
+	}
+}
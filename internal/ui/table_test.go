@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableRenderPlain(t *testing.T) {
+	// Non-TTY test runs degrade to tab-separated output; force it so
+	// the test doesn't depend on the environment
+	SetPlainProgress(true)
+	defer SetPlainProgress(false)
+
+	table := NewTable("NAME", "STATUS")
+	table.AddRow(SuccessStyle, "git", "installed")
+	table.AddRow(ErrorStyle, "stow", "missing")
+
+	got := table.Render()
+	want := "git\tinstalled\nstow\tmissing\n"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTableRenderEmpty(t *testing.T) {
+	if got := NewTable("NAME").Render(); got != "" {
+		t.Errorf("Render() of empty table = %q, want empty", got)
+	}
+}
+
+func TestPadCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{"Pads short cell", "git", 6, "git   "},
+		{"Exact width unchanged", "git", 3, "git"},
+		{"Longer cell unchanged", "neovim", 3, "neovim"},
+		{"Unicode glyph counts as one column", "✓", 3, "✓  "},
+		{"Wide rune counts as two columns", "日本", 6, "日本  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := padCell(tt.input, tt.width); got != tt.want {
+				t.Errorf("padCell(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableColumnAlignment(t *testing.T) {
+	SetPlainProgress(true)
+	defer SetPlainProgress(false)
+
+	// Plain output keeps all cells regardless of header count
+	table := NewTable("A")
+	table.AddRow(TextStyle, "one", "two", "three")
+	if got := table.Render(); !strings.Contains(got, "one\ttwo\tthree") {
+		t.Errorf("Render() = %q, want all cells present", got)
+	}
+}
@@ -0,0 +1,40 @@
+// Package verbose provides a process-wide switch for logging the external
+// commands go4dot runs (stow, git, package managers). It lives in its own
+// package so low-level packages like platform can use it without import
+// cycles through the ui package.
+package verbose
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	mu      sync.RWMutex
+	enabled bool
+)
+
+// Set toggles verbose command logging.
+// This should be called from the CLI layer when --verbose is used.
+func Set(value bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = value
+}
+
+// Enabled returns true if verbose command logging is on.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// Command prints the full argv of an external command before it runs.
+func Command(args ...string) {
+	if !Enabled() || len(args) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "+ %s\n", strings.Join(args, " "))
+}
@@ -0,0 +1,79 @@
+package version
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nvandessel/go4dot/internal/state"
+)
+
+// cacheFileName is the update-check cache file under the XDG cache dir
+const cacheFileName = "update-check.json"
+
+// cacheTTL is how long a cached update check stays fresh before the
+// GitHub API is queried again
+const cacheTTL = 24 * time.Hour
+
+// updateCache is the persisted result of the last release lookup
+type updateCache struct {
+	LatestVersion string    `json:"latest_version"`
+	ReleaseURL    string    `json:"release_url"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// cachePath resolves the update-check cache file location
+func cachePath() (string, error) {
+	dir, err := state.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, cacheFileName), nil
+}
+
+// readCache returns the cached release lookup if it is still fresh
+func readCache() *updateCache {
+	path, err := cachePath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var cached updateCache
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil
+	}
+	if cached.LatestVersion == "" || time.Since(cached.CheckedAt) > cacheTTL {
+		return nil
+	}
+
+	return &cached
+}
+
+// writeCache persists a release lookup; failures are ignored since the
+// cache is purely an optimization
+func writeCache(latest, releaseURL string) {
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(updateCache{
+		LatestVersion: latest,
+		ReleaseURL:    releaseURL,
+		CheckedAt:     time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
@@ -16,12 +16,17 @@ type CheckResult struct {
 	ReleaseURL     string
 }
 
-// CheckForUpdates queries GitHub for the latest release
+// CheckForUpdates queries GitHub for the latest release, using the
+// XDG cache to avoid hitting the API more than once a day
 func CheckForUpdates(currentVersion string) (*CheckResult, error) {
 	if currentVersion == "dev" || currentVersion == "unknown" {
 		return nil, nil // Don't check for dev builds
 	}
 
+	if cached := readCache(); cached != nil {
+		return compareVersions(currentVersion, cached.LatestVersion, cached.ReleaseURL), nil
+	}
+
 	client := &http.Client{Timeout: 2 * time.Second}
 	resp, err := client.Get("https://api.github.com/repos/nvandessel/go4dot/releases/latest")
 	if err != nil {
@@ -43,6 +48,14 @@ func CheckForUpdates(currentVersion string) (*CheckResult, error) {
 	}
 
 	latest := strings.TrimPrefix(release.TagName, "v")
+	writeCache(latest, release.HTMLURL)
+
+	return compareVersions(currentVersion, latest, release.HTMLURL), nil
+}
+
+// compareVersions builds a CheckResult when the latest release differs
+// from the running version, nil when already up to date
+func compareVersions(currentVersion, latest, releaseURL string) *CheckResult {
 	current := strings.TrimPrefix(currentVersion, "v")
 
 	if latest != current {
@@ -50,9 +63,9 @@ func CheckForUpdates(currentVersion string) (*CheckResult, error) {
 			LatestVersion:  latest,
 			CurrentVersion: current,
 			IsOutdated:     true,
-			ReleaseURL:     release.HTMLURL,
-		}, nil
+			ReleaseURL:     releaseURL,
+		}
 	}
 
-	return nil, nil
+	return nil
 }